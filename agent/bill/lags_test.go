@@ -81,3 +81,25 @@ func TestBillingLags(t *testing.T) {
 	fixture.checkAcc(t, 1, verifyBillingLags,
 		data.JobAgentPreServiceSuspend)
 }
+
+// Source conditions:
+// Channel 2 of the billing lags fixture is granted enough free units
+// to cover its usage.
+//
+// Expected result:
+// Channel 2 is not selected for suspending.
+func TestBillingLagsFreeUnits(t *testing.T) {
+	fixture := genBillingLags(t)
+	defer fixture.clean()
+
+	fixture.chs[1].FreeUnits = 2 * conf.BillingTest.Session.UnitsUsed
+	if err := db.Update(fixture.chs[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyBillingLags(t)
+
+	if done(fixture.chs[1].ID, data.JobAgentPreServiceSuspend) {
+		t.Fatal("channel covered by free units is suspended")
+	}
+}
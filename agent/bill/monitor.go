@@ -123,7 +123,7 @@ func (m *Monitor) VerifyUnitsBasedChannels() error {
                  AND acc.in_use
                GROUP BY channels.id, offer.setup_price,
                      offer.unit_price, offer.max_unit
-              HAVING offer.setup_price + coalesce(sum(ses.units_used), 0) * offer.unit_price >= channels.total_deposit
+              HAVING offer.setup_price + GREATEST(coalesce(sum(ses.units_used), 0) - channels.free_units, 0) * offer.unit_price >= channels.total_deposit
                   OR COALESCE(SUM(ses.units_used), 0) >= offer.max_unit;`
 
 	logger := m.logger.Add("method", "VerifyUnitsBasedChannels")
@@ -153,7 +153,7 @@ func (m *Monitor) VerifyBillingLags() error {
                GROUP BY channels.id, offer.billing_interval,
                      offer.setup_price, offer.unit_price,
                      offer.max_billing_unit_lag
-              HAVING COALESCE(SUM(ses.units_used), 0) /
+              HAVING GREATEST(COALESCE(SUM(ses.units_used), 0) - channels.free_units, 0) /
 	      offer.billing_interval - (channels.receipt_balance - offer.setup_price ) /
 	      offer.unit_price > offer.max_billing_unit_lag;`
 	logger := m.logger.Add("method", "VerifyBillingLags")
@@ -183,7 +183,7 @@ func (m *Monitor) VerifySuspendedChannelsAndTryToUnsuspend() error {
                GROUP BY channels.id, offer.billing_interval,
                      offer.setup_price, offer.unit_price,
                      offer.max_billing_unit_lag
-              HAVING COALESCE(SUM(ses.units_used), 0) /
+              HAVING GREATEST(COALESCE(SUM(ses.units_used), 0) - channels.free_units, 0) /
 	      offer.billing_interval - (channels.receipt_balance - offer.setup_price) /
 	      offer.unit_price <= offer.max_billing_unit_lag;`
 	logger := m.logger.Add("method", "VerifySuspendedChannelsAndTryToUnsuspend")
@@ -0,0 +1,57 @@
+package somcsrv
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/privatix/dappctrl/data"
+)
+
+// EndpointAuth is a proof of channel ownership in endpoint requests.
+// Signature is made by a channel client over a channel key and a time.
+type EndpointAuth struct {
+	Time      int64             `json:"time"`
+	Signature data.Base64String `json:"signature"`
+}
+
+func endpointAuthHash(key data.Base64String, tm int64) []byte {
+	var tmBytes [8]byte
+	binary.BigEndian.PutUint64(tmBytes[:], uint64(tm))
+	return crypto.Keccak256([]byte(key), tmBytes[:])
+}
+
+// NewEndpointAuth signs an endpoint request for a channel with a given key.
+func NewEndpointAuth(key data.Base64String,
+	prv *ecdsa.PrivateKey, now time.Time) (*EndpointAuth, error) {
+	tm := now.Unix()
+	sig, err := crypto.Sign(endpointAuthHash(key, tm), prv)
+	if err != nil {
+		return nil, err
+	}
+	return &EndpointAuth{Time: tm, Signature: data.FromBytes(sig)}, nil
+}
+
+// signer returns a client, which signed an endpoint request for a given key
+// not earlier than max age ago.
+func (a *EndpointAuth) signer(key data.Base64String,
+	maxAge uint, now time.Time) (data.HexString, bool) {
+	age := now.Unix() - a.Time
+	if age < -int64(maxAge) || age > int64(maxAge) {
+		return "", false
+	}
+
+	sig, err := data.ToBytes(a.Signature)
+	if err != nil {
+		return "", false
+	}
+
+	pub, err := crypto.SigToPub(endpointAuthHash(key, a.Time), sig)
+	if err != nil {
+		return "", false
+	}
+
+	return data.HexFromBytes(crypto.PubkeyToAddress(*pub).Bytes()), true
+}
@@ -1,11 +1,11 @@
 package somcsrv
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/util"
@@ -24,12 +24,26 @@ func NewClient(client *http.Client, hostname string) *Client {
 
 // Offering gets offering message through tor net.
 func (c *Client) Offering(hash data.HexString) (data.Base64String, error) {
-	return c.requestWithPayload("api_offering", string(hash))
+	return c.requestWithPayload("api_offering", hash)
 }
 
-// Endpoint gets endpoint message through tor net.
-func (c *Client) Endpoint(channelKey data.Base64String) (data.Base64String, error) {
-	return c.requestWithPayload("api_endpoint", string(channelKey))
+// invalidParamsCode is a JSON-RPC error code of invalid method parameters.
+const invalidParamsCode = -32602
+
+// Endpoint gets endpoint message through tor net. A request is signed if
+// auth is not nil. Signed request is repeated unsigned if an agent doesn't
+// support signatures.
+func (c *Client) Endpoint(channelKey data.Base64String,
+	auth *EndpointAuth) (data.Base64String, error) {
+	if auth == nil {
+		return c.requestWithPayload("api_endpoint", channelKey)
+	}
+
+	ret, err := c.requestWithPayload("api_endpoint", channelKey, auth)
+	if rerr, ok := err.(*rpcError); ok && rerr.Code == invalidParamsCode {
+		return c.requestWithPayload("api_endpoint", channelKey)
+	}
+	return ret, err
 }
 
 // Ping returns an error if remote enpoint cannot be reached.
@@ -38,27 +52,49 @@ func (c *Client) Ping() error {
 	return err
 }
 
-func (c *Client) requestWithPayload(method string, param string) (data.Base64String, error) {
-	resp, err := c.request(method, param)
+func (c *Client) requestWithPayload(method string,
+	params ...interface{}) (data.Base64String, error) {
+	resp, err := c.request(method, params...)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-
 	return c.extractResult(resp)
 }
 
-func (c *Client) request(method string, param string) (*http.Response, error) {
-	payload := fmt.Sprintf(`{"method": "%s",
-		"params": ["%s"], "id": "%s"}`, method, param, util.NewUUID())
-	return c.client.Post(c.url(), "application/json", strings.NewReader(payload))
+func (c *Client) request(method string,
+	params ...interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+		"id":     util.NewUUID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Post(c.url(), "application/json", bytes.NewReader(payload))
 }
 
 func (c *Client) url() string {
 	return fmt.Sprintf("http://%s/http", c.hostname)
 }
 
+// rpcError is an error returned by agents SOMC API.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
 func (c *Client) extractResult(resp *http.Response) (data.Base64String, error) {
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status: %s",
+			resp.Status)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -66,12 +102,17 @@ func (c *Client) extractResult(resp *http.Response) (data.Base64String, error) {
 
 	var ret struct {
 		Result *data.Base64String `json:"result"`
+		Error  *rpcError          `json:"error"`
 	}
 
 	if err := json.Unmarshal(body, &ret); err != nil {
 		return "", err
 	}
 
+	if ret.Error != nil {
+		return "", ret.Error
+	}
+
 	if ret.Result == nil {
 		return "", fmt.Errorf("unknown reply: %s", body)
 	}
@@ -1,50 +1,57 @@
 package somcsrv
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/privatix/dappctrl/data"
-	"github.com/privatix/dappctrl/util/log"
 )
 
-// Endpoint returns endpoint msg for a channel with given key.
-func (h *Handler) Endpoint(key data.Base64String) (*data.Base64String, error) {
-	logger := h.logger.Add("type", "agent/tor-somc.Handler")
+// Endpoint returns endpoint msg for a channel with given key. Requests are
+// optionally signed by channel clients, signatures are required if it's
+// configured so.
+func (h *Handler) Endpoint(key data.Base64String,
+	auth *EndpointAuth) (*data.Base64String, error) {
+	logger := h.logger.Add("type", "agent/tor-somc.Handler",
+		"method", "Endpoint", "key", key)
 
-	channelsStructs, err := h.db.SelectAllFrom(data.ChannelTable, "")
-	if err != nil {
-		h.logger.Error(err.Error())
-		return nil, ErrInternal
+	if auth == nil && h.conf.RequireSignature {
+		rejectedRequests.Add(rejectedSignature, 1)
+		return nil, ErrSignatureRequired
 	}
 
-	for _, chanStruct := range channelsStructs {
-		channel := chanStruct.(*data.Channel)
-		channelKey, err := h.channelKey(logger, channel)
-		if err != nil {
-			h.logger.Error(err.Error())
-			return nil, ErrInternal
-		}
-		if channelKey == key {
-			endpoint, err := h.endpointByChannelID(logger, channel.ID)
-			if err != nil {
-				return nil, err
-			}
-			return &endpoint.RawMsg, nil
+	// Signatures are verified before a channel lookup and only channels of
+	// a signer are looked up to not let requests probe channel keys.
+	var signer data.HexString
+	if auth != nil {
+		var ok bool
+		signer, ok = auth.signer(key, h.conf.SignatureMaxAge, time.Now())
+		if !ok {
+			rejectedRequests.Add(rejectedSignature, 1)
+			logger.Warn("bad request signature")
+			return nil, ErrBadSignature
 		}
 	}
 
-	return nil, ErrChannelNotFound
-}
-
-func (h *Handler) channelKey(
-	logger log.Logger, channel *data.Channel) (data.Base64String, error) {
-	offering, err := h.offeringByID(logger, channel.Offering)
-	if err != nil {
-		return "", err
+	var msg *data.Base64String
+	err := h.db.QueryRow(`
+		SELECT endpoints.raw_msg
+		  FROM channels
+		  LEFT JOIN endpoints ON endpoints.channel = channels.id
+		 WHERE channels.channel_key = $1
+		       AND ($2 = '' OR channels.client = $2)
+		 LIMIT 1`, key, signer).Scan(&msg)
+	if err == sql.ErrNoRows {
+		return nil, ErrChannelNotFound
 	}
-	keyBytes, err := data.ChannelKey(
-		channel.Client, channel.Agent, channel.Block, offering.Hash)
 	if err != nil {
 		logger.Error(err.Error())
-		return "", ErrInternal
+		return nil, ErrInternal
 	}
-	return data.FromBytes(keyBytes), nil
+
+	if msg == nil {
+		return nil, ErrEndpointNotFound
+	}
+
+	return msg, nil
 }
@@ -2,6 +2,9 @@ package somcsrv_test
 
 import (
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/privatix/dappctrl/agent/somcsrv"
 	"github.com/privatix/dappctrl/data"
@@ -10,7 +13,7 @@ import (
 
 func TestGetEndpointMessage(t *testing.T) {
 	// Test channel does not exist.
-	_, err := handler.Endpoint("my-channel-key")
+	_, err := handler.Endpoint("my-channel-key", nil)
 	util.TestExpectResult(t, "Endpoint", somcsrv.ErrChannelNotFound, err)
 
 	// Test endpoint exists.
@@ -23,8 +26,7 @@ func TestGetEndpointMessage(t *testing.T) {
 	util.TestExpectResult(t, "ChannelKey", nil, err)
 	channelKey := data.FromBytes(keyBytes)
 
-	// TODO: store channel key in table for fast lookup?
-	rawMsg, err := handler.Endpoint(channelKey)
+	rawMsg, err := handler.Endpoint(channelKey, nil)
 	util.TestExpectResult(t, "Endpoint", nil, err)
 	if fxt.Endpoint.RawMsg != *rawMsg {
 		t.Fatalf("wanted: %s, go: %s", fxt.Endpoint.RawMsg, *rawMsg)
@@ -34,6 +36,54 @@ func TestGetEndpointMessage(t *testing.T) {
 	data.DeleteFromTestDB(t, db, fxt.Endpoint)
 	// Restore record for proper fixture close.
 	defer data.SaveToTestDB(t, db, fxt.Endpoint)
-	_, err = handler.Endpoint(channelKey)
+	_, err = handler.Endpoint(channelKey, nil)
 	util.TestExpectResult(t, "Endpoint", somcsrv.ErrEndpointNotFound, err)
 }
+
+func TestGetEndpointMessageSigned(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	conf := somcsrv.NewConfig()
+	conf.RequireSignature = true
+	handler := somcsrv.NewHandler(conf, db, logger)
+
+	key := *fxt.Channel.Key
+	_, err := handler.Endpoint(key, nil)
+	util.TestExpectResult(t, "Endpoint", somcsrv.ErrSignatureRequired, err)
+
+	clientKey, err := data.TestToPrivateKey(
+		fxt.UserAcc.PrivateKey, data.TestPassword)
+	util.TestExpectResult(t, "TestToPrivateKey", nil, err)
+
+	otherKey, err := crypto.GenerateKey()
+	util.TestExpectResult(t, "GenerateKey", nil, err)
+
+	// Test signature of another account.
+	now := time.Now()
+	auth, err := somcsrv.NewEndpointAuth(key, otherKey, now)
+	util.TestExpectResult(t, "NewEndpointAuth", nil, err)
+	_, err = handler.Endpoint(key, auth)
+	util.TestExpectResult(t, "Endpoint", somcsrv.ErrChannelNotFound, err)
+
+	// Test signature is verified before a channel lookup.
+	auth.Signature = data.FromBytes([]byte("bad-signature"))
+	_, err = handler.Endpoint(data.FromBytes([]byte("unknown")), auth)
+	util.TestExpectResult(t, "Endpoint", somcsrv.ErrBadSignature, err)
+
+	// Test outdated signature.
+	old := now.Add(-time.Duration(conf.SignatureMaxAge+1) * time.Second)
+	auth, err = somcsrv.NewEndpointAuth(key, clientKey, old)
+	util.TestExpectResult(t, "NewEndpointAuth", nil, err)
+	_, err = handler.Endpoint(key, auth)
+	util.TestExpectResult(t, "Endpoint", somcsrv.ErrBadSignature, err)
+
+	auth, err = somcsrv.NewEndpointAuth(key, clientKey, now)
+	util.TestExpectResult(t, "NewEndpointAuth", nil, err)
+
+	rawMsg, err := handler.Endpoint(key, auth)
+	util.TestExpectResult(t, "Endpoint", nil, err)
+	if fxt.Endpoint.RawMsg != *rawMsg {
+		t.Fatalf("wanted: %s, go: %s", fxt.Endpoint.RawMsg, *rawMsg)
+	}
+}
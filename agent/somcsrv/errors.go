@@ -9,13 +9,17 @@ const (
 	ErrChannelNotFound
 	ErrEndpointNotFound
 	ErrOfferingNotFound
+	ErrSignatureRequired
+	ErrBadSignature
 )
 
 var errMsgs = errors.Messages{
-	ErrInternal:         "internal error occurred",
-	ErrChannelNotFound:  "channel not found",
-	ErrEndpointNotFound: "endpoint not found",
-	ErrOfferingNotFound: "offering not found",
+	ErrInternal:          "internal error occurred",
+	ErrChannelNotFound:   "channel not found",
+	ErrEndpointNotFound:  "endpoint not found",
+	ErrOfferingNotFound:  "offering not found",
+	ErrSignatureRequired: "request signature required",
+	ErrBadSignature:      "bad request signature",
 }
 
 func init() { errors.InjectMessages(errMsgs) }
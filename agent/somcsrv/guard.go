@@ -0,0 +1,155 @@
+package somcsrv
+
+import (
+	"expvar"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/privatix/dappctrl/util/rpcsrv"
+)
+
+// Reasons of rejected requests.
+const (
+	rejectedRate      = "rate"
+	rejectedSize      = "size"
+	rejectedSignature = "signature"
+)
+
+// rejectedRequests are numbers of rejected requests by reasons.
+var rejectedRequests = expvar.NewMap("somcsrv.rejected")
+
+// RejectedRequests returns numbers of rejected requests by reasons.
+func RejectedRequests() map[string]uint64 {
+	result := map[string]uint64{
+		rejectedRate:      0,
+		rejectedSize:      0,
+		rejectedSignature: 0,
+	}
+
+	rejectedRequests.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			result[kv.Key] = uint64(v.Value())
+		}
+	})
+
+	return result
+}
+
+// maxBuckets is a number of sources after which idle ones are forgotten.
+const maxBuckets = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter is a token bucket rate limiter for many sources.
+type limiter struct {
+	mtx     sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func newLimiter(rate float64, burst uint, now func() time.Time) *limiter {
+	if burst == 0 {
+		burst = 1
+	}
+	return &limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     now,
+	}
+}
+
+func (l *limiter) allow(source string) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+
+	b, ok := l.buckets[source]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.forgetIdle(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[source] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// forgetIdle deletes buckets which are full again.
+func (l *limiter) forgetIdle(now time.Time) {
+	for k, v := range l.buckets {
+		if v.tokens+now.Sub(v.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// Guard returns a middleware limiting request rates and sizes of agents
+// SOMC API according to a configuration. Only HTTP calls are served, as limits
+// can not be applied to calls made over a WebSocket connection.
+func Guard(conf *Config) func(http.Handler) http.Handler {
+	var lim *limiter
+	if conf.RateLimit > 0 {
+		lim = newLimiter(conf.RateLimit, conf.RateBurst, time.Now)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			if r.URL.Path != rpcsrv.HTTPPath {
+				http.NotFound(w, r)
+				return
+			}
+
+			if lim != nil {
+				source, _, err := net.SplitHostPort(r.RemoteAddr)
+				if err != nil {
+					source = r.RemoteAddr
+				}
+
+				if !lim.allow(source) {
+					rejectedRequests.Add(rejectedRate, 1)
+					http.Error(w, http.StatusText(
+						http.StatusTooManyRequests),
+						http.StatusTooManyRequests)
+					return
+				}
+			}
+
+			if conf.MaxRequestSize > 0 {
+				if r.ContentLength > conf.MaxRequestSize {
+					rejectedRequests.Add(rejectedSize, 1)
+					http.Error(w, http.StatusText(
+						http.StatusRequestEntityTooLarge),
+						http.StatusRequestEntityTooLarge)
+					return
+				}
+
+				r.Body = http.MaxBytesReader(
+					w, r.Body, conf.MaxRequestSize)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package somcsrv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/privatix/dappctrl/util/rpcsrv"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	lim := newLimiter(1, 2, func() time.Time { return now })
+
+	for i, want := range []bool{true, true, false} {
+		if lim.allow("a") != want {
+			t.Fatalf("unexpected result of request %d", i)
+		}
+	}
+
+	if !lim.allow("b") {
+		t.Fatal("request from another source rejected")
+	}
+
+	now = now.Add(time.Second)
+	if !lim.allow("a") || lim.allow("a") {
+		t.Fatal("tokens are not refilled at a given rate")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	conf := NewConfig()
+	conf.RateLimit = 1
+	conf.RateBurst = 1
+	conf.MaxRequestSize = 10
+
+	handler := Guard(conf)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(path, body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", path, strings.NewReader(body))
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	before := RejectedRequests()
+
+	if code := serve(rpcsrv.WSPath, "{}"); code != http.StatusNotFound {
+		t.Fatalf("unexpected status for a websocket request: %d", code)
+	}
+
+	if code := serve(rpcsrv.HTTPPath, strings.Repeat("x", 11)); code !=
+		http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status for a large request: %d", code)
+	}
+
+	if code := serve(rpcsrv.HTTPPath, "{}"); code != http.StatusOK {
+		t.Fatalf("unexpected status for a valid request: %d", code)
+	}
+
+	if code := serve(rpcsrv.HTTPPath, "{}"); code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status for a frequent request: %d", code)
+	}
+
+	after := RejectedRequests()
+	if after[rejectedSize] != before[rejectedSize]+1 ||
+		after[rejectedRate] != before[rejectedRate]+1 {
+		t.Fatalf("unexpected rejected requests: %v", after)
+	}
+}
@@ -6,13 +6,45 @@ import (
 	"github.com/privatix/dappctrl/util/log"
 )
 
+// Config is a configuration of agents SOMC API.
+type Config struct {
+	// RateLimit is a number of requests per second allowed from a single
+	// source, zero to disable the limit. Requests coming through Tor all
+	// have the same local source, so for a hidden service this is a limit
+	// for all clients together. Hence the limit is disabled by default.
+	RateLimit float64
+	// RateBurst is a number of requests allowed from a single source at
+	// once.
+	RateBurst uint
+	// MaxRequestSize is a maximum request size in bytes, zero to disable
+	// the limit.
+	MaxRequestSize int64
+	// RequireSignature makes endpoint requests to be signed by clients.
+	RequireSignature bool
+	// SignatureMaxAge is a maximum age of request signatures in seconds.
+	SignatureMaxAge uint
+}
+
+// NewConfig creates a default agents SOMC API configuration.
+func NewConfig() *Config {
+	return &Config{
+		RateLimit:        0,
+		RateBurst:        20,
+		MaxRequestSize:   4096,
+		RequireSignature: false,
+		SignatureMaxAge:  300,
+	}
+}
+
 // Handler is agents RPC handler.
 type Handler struct {
+	conf   *Config
 	db     *reform.DB
 	logger log.Logger
 }
 
 // NewHandler creates a new RPC handler.
-func NewHandler(db *reform.DB, logger log.Logger) *Handler {
-	return &Handler{db: db, logger: logger.Add("type", "tor-somc.Handler")}
+func NewHandler(conf *Config, db *reform.DB, logger log.Logger) *Handler {
+	return &Handler{conf: conf, db: db,
+		logger: logger.Add("type", "tor-somc.Handler")}
 }
@@ -18,6 +18,7 @@ var (
 		Log *log.WriterConfig
 	}
 	db      *reform.DB
+	logger  log.Logger
 	handler *somcsrv.Handler
 )
 
@@ -29,7 +30,8 @@ func TestMain(m *testing.M) {
 	}
 	util.ReadTestArgs(args)
 
-	logger, err := log.NewTestLogger(conf.Log, args.Verbose)
+	var err error
+	logger, err = log.NewTestLogger(conf.Log, args.Verbose)
 	if err != nil {
 		panic(err)
 	}
@@ -37,7 +39,7 @@ func TestMain(m *testing.M) {
 	db = data.NewTestDB(conf.DB)
 	defer data.CloseDB(db)
 
-	handler = somcsrv.NewHandler(db, logger)
+	handler = somcsrv.NewHandler(somcsrv.NewConfig(), db, logger)
 
 	os.Exit(m.Run())
 }
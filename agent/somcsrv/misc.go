@@ -16,20 +16,6 @@ func (h *Handler) offeringByHash(
 	return offering, err
 }
 
-func (h *Handler) offeringByID(
-	logger log.Logger, id string) (*data.Offering, error) {
-	offering := new(data.Offering)
-	err := h.findOneTo(logger, offering, ErrOfferingNotFound, "id", id)
-	return offering, err
-}
-
-func (h *Handler) endpointByChannelID(
-	logger log.Logger, id string) (*data.Endpoint, error) {
-	endpoint := new(data.Endpoint)
-	err := h.findOneTo(logger, endpoint, ErrEndpointNotFound, "channel", id)
-	return endpoint, err
-}
-
 func (h *Handler) findOneTo(logger log.Logger,
 	str reform.Struct, errNotFound error, column string, val interface{}) error {
 	if err := h.db.FindOneTo(str, column, val); err != nil {
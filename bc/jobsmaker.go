@@ -125,11 +125,27 @@ func (m *jobsMaker) agentOnChannelCloseRequested(l *data.JobEthLog) ([]data.Job,
 }
 
 func (m *jobsMaker) agentOnCooperativeChannelClose(l *data.JobEthLog) ([]data.Job, error) {
-	return m.onExistingChannelEvent(l, data.JobAgentAfterCooperativeClose)
+	return m.agentOnChannelClose(l, data.JobAgentAfterCooperativeClose, data.ClosingCoop)
 }
 
 func (m *jobsMaker) agentOnUnCooperativeChannelClose(l *data.JobEthLog) ([]data.Job, error) {
-	return m.onExistingChannelEvent(l, data.JobAgentAfterUncooperativeClose)
+	return m.agentOnChannelClose(l, data.JobAgentAfterUncooperativeClose, data.ClosingUncoop)
+}
+
+// agentOnChannelClose also records a closing, which is used to report
+// settled agent income.
+func (m *jobsMaker) agentOnChannelClose(l *data.JobEthLog, jtype, closingType string) ([]data.Job, error) {
+	jobs, err := m.onExistingChannelEvent(l, jtype)
+	if err != nil {
+		return nil, err
+	}
+
+	rateJob, err := m.rateJob(l, closingType)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(jobs, rateJob), nil
 }
 
 func (m *jobsMaker) agentOnOfferingCreated(l *data.JobEthLog) ([]data.Job, error) {
@@ -409,7 +425,7 @@ func (m *jobsMaker) rateJob(l *data.JobEthLog, closingType string) (data.Job, er
 			Balance: args[1].(uint64),
 			Block:   args[0].(uint32),
 		},
-		UpdateRatings: m.updateRating(),
+		UpdateRatings: !m.isAgent && m.updateRating(),
 	})
 	if err != nil {
 		m.logger.Error(err.Error())
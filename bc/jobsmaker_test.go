@@ -228,6 +228,10 @@ func TestJobsProducers(t *testing.T) {
 					RelatedType: data.JobChannel,
 					Type:        data.JobAgentAfterUncooperativeClose,
 				},
+				{
+					RelatedType: data.JobChannel,
+					Type:        data.JobClientRecordClosing,
+				},
 			},
 			clientProduced: []data.Job{
 				{
@@ -262,6 +266,10 @@ func TestJobsProducers(t *testing.T) {
 					RelatedType: data.JobChannel,
 					Type:        data.JobAgentAfterCooperativeClose,
 				},
+				{
+					RelatedType: data.JobChannel,
+					Type:        data.JobClientRecordClosing,
+				},
 			},
 			clientProduced: []data.Job{
 				{
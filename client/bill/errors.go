@@ -12,6 +12,8 @@ const (
 	ErrGetConsumedUnits
 	ErrGetOffering
 	ErrUpdateReceiptBalance
+	ErrGetTopUpPolicy
+	ErrGetAccount
 )
 
 var errMsgs = errors.Messages{
@@ -20,6 +22,8 @@ var errMsgs = errors.Messages{
 	ErrGetConsumedUnits:     "failed to get consumed units",
 	ErrGetOffering:          "failed to get offering",
 	ErrUpdateReceiptBalance: "failed to update receipt balance",
+	ErrGetTopUpPolicy:       "failed to get top up policy",
+	ErrGetAccount:           "failed to get account",
 }
 
 func init() { errors.InjectMessages(errMsgs) }
@@ -13,6 +13,7 @@ import (
 	"github.com/lib/pq"
 	"gopkg.in/reform.v1"
 
+	"github.com/privatix/dappctrl/client/budget"
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/job"
 	"github.com/privatix/dappctrl/pay"
@@ -46,6 +47,12 @@ type PriceSuggestor interface {
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 }
 
+// FailureReporter is notified about failures to reach an agent.
+type FailureReporter interface {
+	ChannelFailed(channel string)
+	ChannelRecovered(channel string)
+}
+
 // Monitor is a client billing monitor.
 type Monitor struct {
 	conf      *Config
@@ -58,6 +65,7 @@ type Monitor struct {
 	post      postChequeFunc // Is overrided in unit-tests.
 	mtx       sync.Mutex     // To guard the exit channels.
 	suggestor PriceSuggestor
+	failures  []FailureReporter
 	exit      chan struct{}
 	exited    chan struct{}
 	// The channel is only needed for tests.
@@ -71,6 +79,9 @@ type Monitor struct {
 	// Auto increase deposit when used percent of all available traffic.
 	// Read from settings table.
 	autoIncreaseAtRate float64
+	// Stop payments for channels with usage discrepancies.
+	// Read from settings table.
+	blockOnDiscrepancy bool
 }
 
 // NewMonitor creates a new client billing monitor.
@@ -89,6 +100,11 @@ func NewMonitor(conf *Config, logger log.Logger, db *reform.DB, suggestor PriceS
 	}
 }
 
+// AddFailureReporter adds a reporter to notify about failed cheques.
+func (m *Monitor) AddFailureReporter(reporter FailureReporter) {
+	m.failures = append(m.failures, reporter)
+}
+
 // Run processes billing for active client channels. This function does not
 // return until an error occurs or Close() is called.
 func (m *Monitor) Run() error {
@@ -173,6 +189,12 @@ func (m *Monitor) readSettings() (err error) {
 		return err
 	}
 	m.autoIncreaseAtRate = float64(percent) / 100
+	m.blockOnDiscrepancy, err = data.ReadBoolSetting(m.db.Querier,
+		data.SettingClientUsageBlockPayments)
+	if err != nil {
+		m.logger.Warn(err.Error())
+		m.blockOnDiscrepancy = false
+	}
 	return nil
 }
 
@@ -204,6 +226,11 @@ func (m *Monitor) processChannel(ch *data.Channel) error {
 		return nil
 	}
 
+	if m.blockOnDiscrepancy && usageDisputed(logger, m.db, ch.ID) {
+		logger.Warn("payments blocked due to usage discrepancy")
+		return nil
+	}
+
 	var consumed uint64
 	if err := m.db.QueryRow(`
 		SELECT COALESCE(sum(units_used),0)
@@ -220,7 +247,7 @@ func (m *Monitor) processChannel(ch *data.Channel) error {
 		return nil
 	}
 
-	if err := m.checkAndCreateAutoIncreaseJob(logger, ch, amount); err != nil {
+	if err := m.checkAndCreateAutoIncreaseJob(logger, ch, &offer, amount); err != nil {
 		logger.Error(err.Error())
 		return err
 	}
@@ -234,9 +261,16 @@ func (m *Monitor) processChannel(ch *data.Channel) error {
 	return nil
 }
 
-func (m *Monitor) checkAndCreateAutoIncreaseJob(logger log.Logger, ch *data.Channel, amount uint64) error {
+func (m *Monitor) checkAndCreateAutoIncreaseJob(logger log.Logger,
+	ch *data.Channel, offer *data.Offering, amount uint64) error {
+	policy, err := data.FindTopUpPolicy(m.db.Querier, ch.ID)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrGetTopUpPolicy
+	}
+	// A channel policy enables top ups regardless of the global setting.
 	autoincreaseAfter := uint64(float64(ch.TotalDeposit) * m.autoIncreaseAtRate)
-	if !m.autoIncrease || amount < autoincreaseAfter {
+	if (!m.autoIncrease && policy == nil) || amount < autoincreaseAfter {
 		return nil
 	}
 	err, exists := notMinedChannelTopUpExists(logger, m.db, ch.ID)
@@ -248,16 +282,41 @@ func (m *Monitor) checkAndCreateAutoIncreaseJob(logger log.Logger, ch *data.Chan
 		logger.Error(err.Error())
 		return nil
 	}
+	deposit, err := data.LimitTopUp(m.db.Querier, policy, ch,
+		data.TopUpAmount(policy, ch, offer))
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrGetTopUpPolicy
+	}
+	if deposit == 0 {
+		logger.Debug("channel topup caps reached")
+		return nil
+	}
+	if err := m.checkBudgets(logger, ch, deposit); err != nil {
+		if err == budget.ErrBudgetExceeded {
+			return nil
+		}
+		return err
+	}
 	suggestedGasPrice, err := m.fetchSuggestedGasPrice()
 	if err != nil {
 		return fmt.Errorf("could not fetch suggested gas price: %v", err)
 	}
-	logger = logger.Add("suggestedGasPrice", suggestedGasPrice.Uint64())
+	logger = logger.Add("suggestedGasPrice", suggestedGasPrice.Uint64(),
+		"deposit", deposit)
 	jdata := &data.JobTopUpChannelData{
 		GasPrice: suggestedGasPrice.Uint64(),
-		Deposit:  ch.TotalDeposit,
+		Deposit:  deposit,
+	}
+	jtype := data.JobClientPreChannelTopUp
+	if policy != nil && policy.Mode == data.TopUpAsk {
+		if topUpAsked(logger, m.db, ch.ID) {
+			logger.Debug("channel topup already asked")
+			return nil
+		}
+		jtype = data.JobClientAskChannelTopUp
 	}
-	err = job.AddWithData(m.queue, nil, data.JobClientPreChannelTopUp, data.JobChannel, ch.ID, data.JobBillingChecker, jdata)
+	err = job.AddWithData(m.queue, nil, jtype, data.JobChannel, ch.ID, data.JobBillingChecker, jdata)
 	if err == job.ErrAlreadyProcessing || err == job.ErrDuplicatedJob {
 		logger.Warn("active channel topup exists")
 		return nil
@@ -265,6 +324,64 @@ func (m *Monitor) checkAndCreateAutoIncreaseJob(logger log.Logger, ch *data.Chan
 	return err
 }
 
+func (m *Monitor) checkBudgets(logger log.Logger,
+	ch *data.Channel, deposit uint64) error {
+	var acc data.Account
+	if err := data.FindOneTo(m.db.Querier,
+		&acc, "eth_addr", ch.Client); err != nil {
+		logger.Error(err.Error())
+		return ErrGetAccount
+	}
+	return budget.Check(logger, m.db, m.queue,
+		acc.ID, deposit, data.JobBillingChecker)
+}
+
+// topUpAsked checks whether user is already asked to top up a channel
+// since its last top up.
+func topUpAsked(logger log.Logger, db *reform.DB, chID string) bool {
+	row := db.QueryRow(`
+		SELECT count(*)
+		  FROM jobs
+		 WHERE type = $1
+			   AND related_id = $3
+			   AND created_at > (
+				   SELECT COALESCE(MAX(created_at), '0001-01-01 00:00:00')
+					 FROM jobs
+					WHERE type = $2
+					      AND related_id = $3
+			   );`, data.JobClientAskChannelTopUp,
+		data.JobClientPreChannelTopUp, chID)
+	var qty int
+	if err := row.Scan(&qty); err != nil {
+		logger.Error(fmt.Sprintf("could not check for ask topup channel job existance: %v", err))
+		// Not to spam user with questions.
+		return true
+	}
+	return qty != 0
+}
+
+// usageDisputed checks whether an agent charged more units for a channel
+// than measured by client since the last session start. Starting a new
+// session resumes payments.
+func usageDisputed(logger log.Logger, db *reform.DB, chID string) bool {
+	row := db.QueryRow(`
+		SELECT count(*)
+		  FROM jobs
+		 WHERE type = $1
+			   AND related_id = $2
+			   AND created_at > (
+				   SELECT COALESCE(MAX(started), '0001-01-01 00:00:00')
+					 FROM sessions
+					WHERE channel = $2
+			   );`, data.JobClientUsageDiscrepancy, chID)
+	var qty int
+	if err := row.Scan(&qty); err != nil {
+		logger.Error(fmt.Sprintf("could not check for usage discrepancy job existance: %v", err))
+		return false
+	}
+	return qty != 0
+}
+
 func notMinedChannelTopUpExists(logger log.Logger, db *reform.DB, chID string) (error, bool) {
 	// If it's first time
 	if err := db.SelectOneTo(&data.Job{}, "WHERE related_id=$1 AND type=$2",
@@ -385,10 +502,18 @@ func (m *Monitor) postCheque(channelID string, amount uint64) {
 			return
 		}
 		logger.Error(err.Error())
+		// The agent is unreachable.
+		for _, v := range m.failures {
+			v.ChannelFailed(channelID)
+		}
 		go handleErr(err)
 		return
 	}
 
+	for _, v := range m.failures {
+		v.ChannelRecovered(channelID)
+	}
+
 	logger.Info(fmt.Sprintf("sent payment channel: %s, amount: %v", channel, amount))
 	res, err := m.db.Exec(`
 		UPDATE channels
@@ -305,6 +305,77 @@ func TestPayment(t *testing.T) {
 	expectBalance(t, fxt, 8)
 }
 
+func TestPaymentsBlocked(t *testing.T) {
+	fxt := newFixture(t, db)
+	defer fxt.Close()
+
+	blockPayments := &data.Setting{
+		Key:         data.SettingClientUsageBlockPayments,
+		Value:       "true",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientUsageBlockPayments,
+	}
+	data.InsertToTestDB(t, fxt.DB,
+		autoincreaseEnabled, autoincreaseAt, blockPayments)
+	defer data.DeleteFromTestDB(t, fxt.DB,
+		autoincreaseEnabled, autoincreaseAt, blockPayments)
+
+	fxt.Offering.UnitPrice = 1
+	fxt.Offering.BillingInterval = 1
+	fxt.Offering.MaxInactiveTimeSec = 1000
+	fxt.Channel.TotalDeposit = 10
+
+	sess := data.NewTestSession(fxt.Channel.ID)
+	sess.Started = time.Now().Add(-time.Minute)
+	sess.UnitsUsed = 4
+	sess.LastUsageTime = time.Now()
+
+	discrepancy := data.NewTestJob(data.JobClientUsageDiscrepancy,
+		data.JobTask, data.JobChannel)
+	discrepancy.RelatedID = fxt.Channel.ID
+	discrepancy.Status = data.JobDone
+
+	data.SaveToTestDB(t, db, fxt.Offering, fxt.Channel, sess)
+	data.InsertToTestDB(t, db, discrepancy)
+	defer data.DeleteFromTestDB(t, db, discrepancy, sess)
+
+	processErrors := make(chan error)
+
+	mon, ch := newTestMonitor(processErrors, nil)
+	defer closeTestMonitor(t, mon, ch)
+
+	mtx := sync.Mutex{}
+	called := false
+	mon.post = func(db *reform.DB, channel *data.Channel, pscAddr data.HexString,
+		key *ecdsa.PrivateKey, amount uint64, tls bool, timeout uint,
+		pr *proc.Processor) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		called = true
+		return nil
+	}
+
+	util.TestExpectResult(t, "processChannel", nil, <-processErrors)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if called {
+		t.Fatal("payment posted despite usage discrepancy")
+	}
+
+	if !usageDisputed(logger, db, fxt.Channel.ID) {
+		t.Fatal("usage is not disputed")
+	}
+
+	sess2 := data.NewTestSession(fxt.Channel.ID)
+	data.InsertToTestDB(t, db, sess2)
+	defer data.DeleteFromTestDB(t, db, sess2)
+
+	if usageDisputed(logger, db, fxt.Channel.ID) {
+		t.Fatal("usage is disputed after a new session")
+	}
+}
+
 func TestMain(m *testing.M) {
 	conf.ClientBilling = NewConfig()
 	conf.Log = log.NewWriterConfig()
@@ -0,0 +1,109 @@
+package budget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// Check verifies that spending of a given amount by a client account fits
+// into all the budgets applied to the account. A budget warning job is
+// created for the account, if the spending reaches one of the warning
+// thresholds. The warning is delivered to ObjectChange subscribers of
+// the account.
+func Check(logger log.Logger, db *reform.DB, queue job.Queue,
+	account string, amount uint64, creator string) error {
+	logger = logger.Add("account", account, "amount", amount)
+
+	budgets, err := data.FindSpendingBudgets(db.Querier, account)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	thresholds := warnThresholds(logger, db)
+
+	var warnings []data.JobBudgetWarning
+	for _, v := range budgets {
+		spent, err := data.BudgetSpent(db.Querier, v)
+		if err != nil {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
+
+		if spent+amount > v.Amount {
+			logger.Add("budget", v, "spent", spent).Warn(
+				ErrBudgetExceeded.Error())
+			return ErrBudgetExceeded
+		}
+
+		if t, ok := reachedThreshold(
+			thresholds, v.Amount, spent, spent+amount); ok {
+			warnings = append(warnings, data.JobBudgetWarning{
+				Budget:    v,
+				Spent:     spent + amount,
+				Threshold: t,
+			})
+		}
+	}
+
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	err = job.AddWithData(queue, nil, data.JobClientBudgetWarning,
+		data.JobAccount, account, creator,
+		&data.JobBudgetWarningData{Warnings: warnings})
+	if err != nil && err != job.ErrDuplicatedJob &&
+		err != job.ErrAlreadyProcessing {
+		logger.Error(err.Error())
+	}
+
+	return nil
+}
+
+// reachedThreshold returns the highest threshold, which is reached by
+// spending increase from before to after.
+func reachedThreshold(thresholds []uint64,
+	budget, before, after uint64) (uint64, bool) {
+	var ret uint64
+	var ok bool
+	for _, t := range thresholds {
+		limit := budget * t / 100
+		if before < limit && after >= limit && t > ret {
+			ret, ok = t, true
+		}
+	}
+	return ret, ok
+}
+
+func warnThresholds(logger log.Logger, db *reform.DB) []uint64 {
+	val, err := data.ReadSetting(db.Querier,
+		data.SettingClientBudgetWarnThresholds)
+	if err != nil {
+		logger.Debug(err.Error())
+		return nil
+	}
+
+	var ret []uint64
+	for _, v := range strings.Split(val, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		t, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || t == 0 || t > 100 {
+			logger.Warn(fmt.Sprintf(
+				"bad budget warning threshold: %s", v))
+			continue
+		}
+		ret = append(ret, t)
+	}
+	return ret
+}
@@ -0,0 +1,132 @@
+package budget
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+var (
+	conf struct {
+		DB  *data.DBConfig
+		Job *job.Config
+		Log *log.WriterConfig
+	}
+
+	logger log.Logger
+	db     *reform.DB
+	queue  job.Queue
+
+	warnThresholdsSetting = &data.Setting{
+		Key:         data.SettingClientBudgetWarnThresholds,
+		Value:       "50,80",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientBudgetWarnThresholds,
+	}
+)
+
+func newTestTopUpJob(t *testing.T, channel string, deposit uint64) *data.Job {
+	t.Helper()
+
+	j := data.NewTestJob(data.JobClientPreChannelTopUp,
+		data.JobUser, data.JobChannel)
+	j.RelatedID = channel
+	j.Status = data.JobDone
+	j.Data, _ = json.Marshal(&data.JobTopUpChannelData{Deposit: deposit})
+	data.InsertToTestDB(t, db, j)
+	return j
+}
+
+func budgetWarning(t *testing.T, account string) *data.JobBudgetWarningData {
+	t.Helper()
+
+	var j data.Job
+	err := db.SelectOneTo(&j, "WHERE related_id = $1 AND type = $2",
+		account, data.JobClientBudgetWarning)
+	if err == reform.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.DeleteFromTestDB(t, db, &j)
+
+	var jdata data.JobBudgetWarningData
+	if err := json.Unmarshal(j.Data, &jdata); err != nil {
+		t.Fatal(err)
+	}
+	return &jdata
+}
+
+func TestCheck(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	global := &data.SpendingBudget{
+		ID:     util.NewUUID(),
+		Period: data.BudgetDaily,
+		Amount: 1000,
+	}
+	other := &data.SpendingBudget{
+		ID:      util.NewUUID(),
+		Account: &fxt.Account.ID,
+		Period:  data.BudgetLifetime,
+		Amount:  1,
+	}
+	data.InsertToTestDB(t, db, global, other, warnThresholdsSetting)
+	defer data.DeleteFromTestDB(t, db,
+		global, other, warnThresholdsSetting)
+
+	j := newTestTopUpJob(t, fxt.Channel.ID, 600)
+	defer data.DeleteFromTestDB(t, db, j)
+
+	err := Check(logger, db, queue, fxt.UserAcc.ID, 500, data.JobUser)
+	util.TestExpectResult(t, "Check", ErrBudgetExceeded, err)
+
+	err = Check(logger, db, queue, fxt.UserAcc.ID, 100, data.JobUser)
+	util.TestExpectResult(t, "Check", nil, err)
+	if budgetWarning(t, fxt.UserAcc.ID) != nil {
+		t.Fatal("unexpected budget warning")
+	}
+
+	err = Check(logger, db, queue, fxt.UserAcc.ID, 300, data.JobUser)
+	util.TestExpectResult(t, "Check", nil, err)
+	warn := budgetWarning(t, fxt.UserAcc.ID)
+	if warn == nil || len(warn.Warnings) != 1 {
+		t.Fatal("budget warning expected")
+	}
+	if w := warn.Warnings[0]; w.Budget.ID != global.ID ||
+		w.Spent != 900 || w.Threshold != 80 {
+		t.Fatalf("wrong budget warning: %+v", w)
+	}
+}
+
+func TestMain(m *testing.M) {
+	conf.DB = data.NewDBConfig()
+	conf.Job = job.NewConfig()
+	conf.Log = log.NewWriterConfig()
+	args := &util.TestArgs{
+		Conf: &conf,
+	}
+	util.ReadTestArgs(args)
+
+	var err error
+	logger, err = log.NewTestLogger(conf.Log, args.Verbose)
+	if err != nil {
+		panic(err)
+	}
+
+	db = data.NewTestDB(conf.DB)
+	defer data.CloseDB(db)
+
+	queue = job.NewQueue(conf.Job, logger, db, nil)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,19 @@
+package budget
+
+import (
+	"github.com/privatix/dappctrl/util/errors"
+)
+
+// Errors.
+const (
+	// CRC16("github.com/privatix/dappctrl/client/budget") = 0xF8CF
+	ErrBudgetExceeded errors.Error = 0xF8CF<<8 + iota
+	ErrInternal
+)
+
+var errMsgs = errors.Messages{
+	ErrBudgetExceeded: "spending budget exceeded",
+	ErrInternal:       "internal server error",
+}
+
+func init() { errors.InjectMessages(errMsgs) }
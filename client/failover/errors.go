@@ -0,0 +1,19 @@
+package failover
+
+import (
+	"github.com/privatix/dappctrl/util/errors"
+)
+
+// Errors.
+const (
+	// CRC16("github.com/privatix/dappctrl/client/failover") = 0x31AC
+	ErrAlreadyRunning errors.Error = 0x31AC<<8 + iota
+	ErrInternal
+)
+
+var errMsgs = errors.Messages{
+	ErrAlreadyRunning: "already running",
+	ErrInternal:       "internal server error",
+}
+
+func init() { errors.InjectMessages(errMsgs) }
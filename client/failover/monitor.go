@@ -0,0 +1,200 @@
+package failover
+
+import (
+	"database/sql"
+	"sync"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+const subID = "client/failover.Monitor"
+
+// Jobs, which results are watched by the monitor.
+var jobTypes = []string{
+	data.JobClientAfterChannelCreate,
+	data.JobClientEndpointGet,
+	data.JobClientAfterCooperativeClose,
+}
+
+// Monitor counts consecutive endpoint and connection failures of client
+// channels and schedules failover to an alternative offering, when a channel
+// fails too often or is closed by an agent.
+type Monitor struct {
+	logger   log.Logger
+	db       *reform.DB
+	queue    job.Queue
+	mtx      sync.Mutex // To guard the failure counters.
+	failures map[string]uint
+	running  bool
+}
+
+// NewMonitor creates a new client failover monitor.
+func NewMonitor(logger log.Logger, db *reform.DB, queue job.Queue) *Monitor {
+	return &Monitor{
+		logger:   logger.Add("type", "client/failover.Monitor"),
+		db:       db,
+		queue:    queue,
+		failures: make(map[string]uint),
+	}
+}
+
+// Start subscribes the monitor to job results.
+func (m *Monitor) Start() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.running {
+		return ErrAlreadyRunning
+	}
+
+	if err := m.queue.Subscribe(jobTypes, subID, m.handleJob); err != nil {
+		m.logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	m.running = true
+	return nil
+}
+
+// Stop unsubscribes the monitor from job results.
+func (m *Monitor) Stop() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if !m.running {
+		return
+	}
+
+	if err := m.queue.Unsubscribe(jobTypes, subID); err != nil {
+		m.logger.Error(err.Error())
+	}
+
+	m.running = false
+}
+
+// ChannelFailed registers a failure of a given channel. Failover is
+// triggered when a number of consecutive failures reaches a limit.
+func (m *Monitor) ChannelFailed(channel string) {
+	logger := m.logger.Add("method", "ChannelFailed", "channel", channel)
+
+	enabled, max := m.readSettings(logger)
+	if !enabled {
+		return
+	}
+
+	m.mtx.Lock()
+	m.failures[channel]++
+	failures := m.failures[channel]
+	if failures >= max {
+		delete(m.failures, channel)
+	}
+	m.mtx.Unlock()
+
+	logger.Add("failures", failures).Debug("channel failure registered")
+
+	if failures >= max {
+		m.triggerFailover(logger, channel)
+	}
+}
+
+// ChannelRecovered resets failures of a given channel.
+func (m *Monitor) ChannelRecovered(channel string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	delete(m.failures, channel)
+}
+
+func (m *Monitor) readSettings(logger log.Logger) (bool, uint) {
+	enabled, err := data.ReadBoolSetting(
+		m.db.Querier, data.SettingClientFailover)
+	if err != nil {
+		logger.Warn(err.Error())
+		return false, 0
+	}
+
+	max, err := data.ReadUintSetting(
+		m.db.Querier, data.SettingClientFailoverMaxFailures)
+	if err != nil {
+		logger.Warn(err.Error())
+		return false, 0
+	}
+
+	return enabled, max
+}
+
+func (m *Monitor) handleJob(j *data.Job, result error) {
+	logger := m.logger.Add("method", "handleJob", "job", j)
+
+	switch j.Type {
+	case data.JobClientAfterChannelCreate, data.JobClientEndpointGet:
+		if result != nil {
+			m.ChannelFailed(j.RelatedID)
+		}
+	case data.JobClientAfterCooperativeClose:
+		if result != nil {
+			return
+		}
+
+		closedByClient, err := m.closedByClient(j.RelatedID)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+
+		if closedByClient {
+			return
+		}
+
+		if enabled, _ := m.readSettings(logger); enabled {
+			m.ChannelRecovered(j.RelatedID)
+			m.triggerFailover(logger, j.RelatedID)
+		}
+	}
+}
+
+// closedByClient checks whether service termination or channel closing
+// was initiated on the client side.
+func (m *Monitor) closedByClient(channel string) (bool, error) {
+	_, err := m.db.SelectOneFrom(data.JobTable, `
+		WHERE related_id = $1 AND (type IN ($2, $3)
+		      OR type = $4 AND status IN ($5, $6))`, channel,
+		data.JobClientPreServiceTerminate,
+		data.JobClientPreUncooperativeCloseRequest,
+		data.JobClientPreChannelFailover, data.JobActive, data.JobDone)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *Monitor) triggerFailover(logger log.Logger, channel string) {
+	// Failed or canceled failovers are retried.
+	_, err := m.db.SelectOneFrom(data.JobTable,
+		"WHERE related_id = $1 AND type = $2 AND status IN ($3, $4)",
+		channel, data.JobClientPreChannelFailover,
+		data.JobActive, data.JobDone)
+	if err == nil {
+		logger.Debug("channel failover already triggered")
+		return
+	}
+	if err != sql.ErrNoRows {
+		logger.Error(err.Error())
+		return
+	}
+
+	logger.Info("triggering channel failover")
+
+	err = job.AddWithData(m.queue, nil, data.JobClientPreChannelFailover,
+		data.JobChannel, channel, data.JobTask, &data.JobPublishData{})
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,159 @@
+package failover
+
+import (
+	"os"
+	"testing"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+var (
+	conf struct {
+		DB  *data.DBConfig
+		Job *job.Config
+		Log *log.WriterConfig
+	}
+
+	logger log.Logger
+	db     *reform.DB
+	queue  job.Queue
+
+	failoverEnabled = &data.Setting{
+		Key:         data.SettingClientFailover,
+		Value:       "true",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientFailover,
+	}
+	failoverMaxFailures = &data.Setting{
+		Key:         data.SettingClientFailoverMaxFailures,
+		Value:       "2",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientFailoverMaxFailures,
+	}
+)
+
+func failoverJobExists(t *testing.T, channel string) bool {
+	t.Helper()
+
+	var j data.Job
+	err := db.SelectOneTo(&j, "WHERE related_id = $1 AND type = $2",
+		channel, data.JobClientPreChannelFailover)
+	if err == reform.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data.DeleteFromTestDB(t, db, &j)
+	return true
+}
+
+func TestChannelFailed(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	data.InsertToTestDB(t, db, failoverEnabled, failoverMaxFailures)
+	defer data.DeleteFromTestDB(t, db, failoverEnabled, failoverMaxFailures)
+
+	mon := NewMonitor(logger, db, queue)
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	mon.ChannelRecovered(fxt.Channel.ID)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if failoverJobExists(t, fxt.Channel.ID) {
+		t.Fatal("failover triggered before failures limit reached")
+	}
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	if !failoverJobExists(t, fxt.Channel.ID) {
+		t.Fatal("failover not triggered")
+	}
+}
+
+func TestFailedFailoverRetried(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	data.InsertToTestDB(t, db, failoverEnabled, failoverMaxFailures)
+	defer data.DeleteFromTestDB(t, db, failoverEnabled, failoverMaxFailures)
+
+	failed := data.NewTestJob(data.JobClientPreChannelFailover,
+		data.JobTask, data.JobChannel)
+	failed.RelatedID = fxt.Channel.ID
+	failed.Status = data.JobFailed
+	data.InsertToTestDB(t, db, failed)
+	defer data.DeleteFromTestDB(t, db, failed)
+
+	mon := NewMonitor(logger, db, queue)
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	mon.ChannelFailed(fxt.Channel.ID)
+
+	var j data.Job
+	if err := db.SelectOneTo(&j, "WHERE related_id = $1 AND type = $2"+
+		" AND status = $3", fxt.Channel.ID,
+		data.JobClientPreChannelFailover, data.JobActive); err != nil {
+		t.Fatalf("failover not retried: %v", err)
+	}
+	data.DeleteFromTestDB(t, db, &j)
+}
+
+func TestChannelClosedByAgent(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	data.InsertToTestDB(t, db, failoverEnabled, failoverMaxFailures)
+	defer data.DeleteFromTestDB(t, db, failoverEnabled, failoverMaxFailures)
+
+	mon := NewMonitor(logger, db, queue)
+
+	closed := data.NewTestJob(data.JobClientAfterCooperativeClose,
+		data.JobBCMonitor, data.JobChannel)
+	closed.RelatedID = fxt.Channel.ID
+
+	terminate := data.NewTestJob(data.JobClientPreServiceTerminate,
+		data.JobUser, data.JobChannel)
+	terminate.RelatedID = fxt.Channel.ID
+	data.InsertToTestDB(t, db, terminate)
+
+	mon.handleJob(closed, nil)
+	if failoverJobExists(t, fxt.Channel.ID) {
+		t.Fatal("failover triggered for channel closed by client")
+	}
+
+	data.DeleteFromTestDB(t, db, terminate)
+
+	mon.handleJob(closed, nil)
+	if !failoverJobExists(t, fxt.Channel.ID) {
+		t.Fatal("failover not triggered")
+	}
+}
+
+func TestMain(m *testing.M) {
+	conf.DB = data.NewDBConfig()
+	conf.Job = job.NewConfig()
+	conf.Log = log.NewWriterConfig()
+	args := &util.TestArgs{
+		Conf: &conf,
+	}
+	util.ReadTestArgs(args)
+
+	var err error
+	logger, err = log.NewTestLogger(conf.Log, args.Verbose)
+	if err != nil {
+		panic(err)
+	}
+
+	db = data.NewTestDB(conf.DB)
+	defer data.CloseDB(db)
+
+	queue = job.NewQueue(conf.Job, logger, db, nil)
+
+	os.Exit(m.Run())
+}
@@ -8,7 +8,7 @@ import (
 
 // Client is expected somc clients interface.
 type Client interface {
-	Endpoint(data.Base64String) (data.Base64String, error)
+	Endpoint(data.Base64String, *somcsrv.EndpointAuth) (data.Base64String, error)
 	Offering(data.HexString) (data.Base64String, error)
 	Ping() error
 }
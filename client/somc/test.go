@@ -3,6 +3,7 @@
 package somc
 
 import (
+	"github.com/privatix/dappctrl/agent/somcsrv"
 	"github.com/privatix/dappctrl/data"
 )
 
@@ -21,7 +22,8 @@ func NewTestClient() *TestClient {
 }
 
 // Endpoint return stored V, Err values.
-func (c *TestClient) Endpoint(data.Base64String) (data.Base64String, error) {
+func (c *TestClient) Endpoint(data.Base64String,
+	*somcsrv.EndpointAuth) (data.Base64String, error) {
 	return c.V, c.Err
 }
 
@@ -0,0 +1,221 @@
+package vanish
+
+import (
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/client/somc"
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// endpointTimeout is a timeout of connecting to an agent endpoint.
+const endpointTimeout = 10 * time.Second
+
+type failures struct {
+	count uint
+	since time.Time
+}
+
+// Monitor detects client channels, which agents stopped to respond. An agent
+// is considered vanished, when cheques and connections keep failing during
+// a time window, and neither its SOMC nor its payment endpoint respond to
+// a ping. Uncooperative close is requested for channels of vanished agents.
+type Monitor struct {
+	logger   log.Logger
+	db       *reform.DB
+	queue    job.Queue
+	somc     somc.ClientBuilderInterface
+	mtx      sync.Mutex // To guard the failure counters.
+	failures map[string]*failures
+	now      func() time.Time
+	pings    sync.WaitGroup // To wait for running pings in tests.
+}
+
+// NewMonitor creates a new vanished agent monitor.
+func NewMonitor(logger log.Logger, db *reform.DB, queue job.Queue,
+	somcBuilder somc.ClientBuilderInterface) *Monitor {
+	return &Monitor{
+		logger:   logger.Add("type", "client/vanish.Monitor"),
+		db:       db,
+		queue:    queue,
+		somc:     somcBuilder,
+		failures: make(map[string]*failures),
+		now:      time.Now,
+	}
+}
+
+// ChannelFailed registers a failure of a given channel. When failures
+// reach a limit and last longer than a window, the agent SOMC and endpoint
+// are pinged in background to confirm that the agent vanished.
+func (m *Monitor) ChannelFailed(channel string) {
+	logger := m.logger.Add("method", "ChannelFailed", "channel", channel)
+
+	enabled, window, min := m.readSettings(logger)
+	if !enabled {
+		return
+	}
+
+	now := m.now()
+
+	m.mtx.Lock()
+	f, ok := m.failures[channel]
+	if !ok {
+		f = &failures{since: now}
+		m.failures[channel] = f
+	}
+	f.count++
+	current := *f
+	vanished := f.count >= min && now.Sub(f.since) >= window
+	if vanished {
+		delete(m.failures, channel)
+	}
+	m.mtx.Unlock()
+
+	logger = logger.Add("failures", current.count, "since", current.since)
+	logger.Debug("channel failure registered")
+
+	if !vanished {
+		return
+	}
+
+	m.pings.Add(1)
+	go func() {
+		defer m.pings.Done()
+
+		if m.agentResponds(logger, channel) {
+			logger.Info("agent responds to ping")
+			return
+		}
+
+		m.triggerClose(logger, channel, current)
+	}()
+}
+
+// ChannelRecovered resets failures of a given channel.
+func (m *Monitor) ChannelRecovered(channel string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	delete(m.failures, channel)
+}
+
+func (m *Monitor) readSettings(logger log.Logger) (bool, time.Duration, uint) {
+	enabled, err := data.ReadBoolSetting(
+		m.db.Querier, data.SettingClientVanishAutoClose)
+	if err != nil {
+		logger.Warn(err.Error())
+		return false, 0, 0
+	}
+
+	window, err := data.ReadUintSetting(
+		m.db.Querier, data.SettingClientVanishWindow)
+	if err != nil {
+		logger.Warn(err.Error())
+		return false, 0, 0
+	}
+
+	min, err := data.ReadUintSetting(
+		m.db.Querier, data.SettingClientVanishMinFailures)
+	if err != nil {
+		logger.Warn(err.Error())
+		return false, 0, 0
+	}
+
+	return enabled, time.Duration(window) * time.Minute, min
+}
+
+// agentResponds pings SOMC of a channel offering and the channel endpoint.
+// Errors, which are not related to the agent, are treated as a response to
+// avoid false closes.
+func (m *Monitor) agentResponds(logger log.Logger, channel string) bool {
+	var ch data.Channel
+	if err := m.db.FindByPrimaryKeyTo(&ch, channel); err != nil {
+		logger.Error(err.Error())
+		return true
+	}
+
+	if ch.ChannelStatus != data.ChannelActive {
+		logger.Debug("channel is not active")
+		return true
+	}
+
+	var offering data.Offering
+	if err := m.db.FindByPrimaryKeyTo(&offering, ch.Offering); err != nil {
+		logger.Error(err.Error())
+		return true
+	}
+
+	client, err := m.somc.NewClient(offering.SOMCType, offering.SOMCData)
+	if err != nil {
+		logger.Error(err.Error())
+		return true
+	}
+
+	if err := client.Ping(); err != nil {
+		logger.Warn("failed to ping SOMC: " + err.Error())
+		return m.endpointResponds(logger, channel)
+	}
+
+	return true
+}
+
+// endpointResponds connects to the payment receiver of a channel endpoint.
+func (m *Monitor) endpointResponds(logger log.Logger, channel string) bool {
+	var endp data.Endpoint
+	err := m.db.FindOneTo(&endp, "channel", channel)
+	if err == reform.ErrNoRows {
+		logger.Warn("no endpoint to ping")
+		return false
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		return true
+	}
+
+	if endp.PaymentReceiverAddress == nil {
+		logger.Warn("no payment receiver address to ping")
+		return false
+	}
+
+	u, err := url.Parse(*endp.PaymentReceiverAddress)
+	if err != nil || u.Hostname() == "" {
+		logger.Warn("bad payment receiver address")
+		return false
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp",
+		net.JoinHostPort(u.Hostname(), port), endpointTimeout)
+	if err != nil {
+		logger.Warn("failed to ping endpoint: " + err.Error())
+		return false
+	}
+	conn.Close()
+
+	return true
+}
+
+func (m *Monitor) triggerClose(logger log.Logger,
+	channel string, f failures) {
+	logger.Warn("agent vanished, requesting uncooperative close")
+
+	err := job.AddWithData(m.queue, nil, data.JobClientAgentVanished,
+		data.JobChannel, channel, data.JobTask,
+		&data.JobAgentVanishedData{Failures: f.count, Since: f.since})
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+	}
+}
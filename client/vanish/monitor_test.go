@@ -0,0 +1,151 @@
+package vanish
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/client/somc"
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/job"
+	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+var (
+	conf struct {
+		DB  *data.DBConfig
+		Job *job.Config
+		Log *log.WriterConfig
+	}
+
+	logger log.Logger
+	db     *reform.DB
+	queue  job.Queue
+
+	vanishAutoClose = &data.Setting{
+		Key:         data.SettingClientVanishAutoClose,
+		Value:       "true",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientVanishAutoClose,
+	}
+	vanishWindow = &data.Setting{
+		Key:         data.SettingClientVanishWindow,
+		Value:       "10",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientVanishWindow,
+	}
+	vanishMinFailures = &data.Setting{
+		Key:         data.SettingClientVanishMinFailures,
+		Value:       "2",
+		Permissions: data.ReadWrite,
+		Name:        data.SettingClientVanishMinFailures,
+	}
+)
+
+func vanishedJobExists(t *testing.T, mon *Monitor, channel string) bool {
+	t.Helper()
+
+	mon.pings.Wait()
+
+	var j data.Job
+	err := db.SelectOneTo(&j, "WHERE related_id = $1 AND type = $2",
+		channel, data.JobClientAgentVanished)
+	if err == reform.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data.DeleteFromTestDB(t, db, &j)
+	return true
+}
+
+func TestChannelFailed(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	data.InsertToTestDB(t, db,
+		vanishAutoClose, vanishWindow, vanishMinFailures)
+	defer data.DeleteFromTestDB(t, db,
+		vanishAutoClose, vanishWindow, vanishMinFailures)
+
+	client := somc.NewTestClient()
+	mon := NewMonitor(logger, db, queue, somc.NewTestClientBuilder(client))
+
+	now := time.Now()
+	mon.now = func() time.Time { return now }
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if vanishedJobExists(t, mon, fxt.Channel.ID) {
+		t.Fatal("close requested before window passed")
+	}
+
+	now = now.Add(10 * time.Minute)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if vanishedJobExists(t, mon, fxt.Channel.ID) {
+		t.Fatal("close requested while SOMC responds")
+	}
+
+	client.Err = errors.New("somc is unreachable")
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	mon.ChannelRecovered(fxt.Channel.ID)
+	now = now.Add(10 * time.Minute)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if vanishedJobExists(t, mon, fxt.Channel.ID) {
+		t.Fatal("close requested for recovered channel")
+	}
+
+	now = now.Add(10 * time.Minute)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if !vanishedJobExists(t, mon, fxt.Channel.ID) {
+		t.Fatal("close not requested")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	addr := "http://" + ln.Addr().String() + "/v1/pmtChannel/pay"
+	fxt.Endpoint.PaymentReceiverAddress = &addr
+	data.SaveToTestDB(t, db, fxt.Endpoint)
+
+	mon.ChannelFailed(fxt.Channel.ID)
+	now = now.Add(10 * time.Minute)
+	mon.ChannelFailed(fxt.Channel.ID)
+	if vanishedJobExists(t, mon, fxt.Channel.ID) {
+		t.Fatal("close requested while endpoint responds")
+	}
+}
+
+func TestMain(m *testing.M) {
+	conf.DB = data.NewDBConfig()
+	conf.Job = job.NewConfig()
+	conf.Log = log.NewWriterConfig()
+	args := &util.TestArgs{
+		Conf: &conf,
+	}
+	util.ReadTestArgs(args)
+
+	var err error
+	logger, err = log.NewTestLogger(conf.Log, args.Verbose)
+	if err != nil {
+		panic(err)
+	}
+
+	db = data.NewTestDB(conf.DB)
+	defer data.CloseDB(db)
+
+	queue = job.NewQueue(conf.Job, logger, db, nil)
+
+	os.Exit(m.Run())
+}
@@ -12,6 +12,12 @@ import (
 // This means that a country is not defined.
 const UndefinedCountry = "ZZ"
 
+// Country providers.
+const (
+	ProviderHTTP = "http"
+	ProviderMMDB = "mmdb"
+)
+
 // Config is the configuration for obtaining a country code.
 type Config struct {
 	Field   string
@@ -19,12 +25,21 @@ type Config struct {
 	// In a url template there should be an pattern {{ip}}.
 	// Pattern {{ip}} will be replaced by a ip address of the agent.
 	URLTemplate string
+	// MMDBPath is a path to a local MaxMind-format country database.
+	MMDBPath string
+	// Providers are tried in a given order until a country is found.
+	Providers []string
+	CacheTTL  uint64 // in milliseconds, 0 disables caching.
+	// IPTypeMMDBPath is a path to a local MaxMind-format database used
+	// to detect ip types.
+	IPTypeMMDBPath string
 }
 
 // NewConfig creates new configuration for obtaining a country code.
 func NewConfig() *Config {
 	return &Config{
-		Timeout: 30,
+		Timeout:   30,
+		Providers: []string{ProviderHTTP},
 	}
 }
 
@@ -7,12 +7,22 @@ const (
 	ErrInternal errors.Error = 0xFFDA<<8 + iota
 	ErrMissingRequiredField
 	ErrBadCountryValueType
+	ErrBadIP
+	ErrCountryNotFound
+	ErrUnknownProvider
+	ErrNoProviders
+	ErrIPTypeNotFound
 )
 
 var errMsgs = errors.Messages{
 	ErrInternal:             "internal server error",
 	ErrMissingRequiredField: "missing required field",
 	ErrBadCountryValueType:  "country value is not a string",
+	ErrBadIP:                "bad ip address",
+	ErrCountryNotFound:      "country not found",
+	ErrUnknownProvider:      "unknown country provider",
+	ErrNoProviders:          "no country providers",
+	ErrIPTypeNotFound:       "ip type not found",
 }
 
 func init() {
@@ -0,0 +1,93 @@
+package country
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// IP types.
+const (
+	IPTypeResidential = "residential"
+	IPTypeDatacenter  = "datacenter"
+	IPTypeMobile      = "mobile"
+)
+
+// IPTypeResolver resolves a type of ip address.
+type IPTypeResolver interface {
+	IPType(ip string) (string, error)
+}
+
+// NewIPTypeResolver creates an ip type resolver, which uses a configured
+// local database. Nil is returned, if no database is configured.
+func NewIPTypeResolver(conf *Config) (IPTypeResolver, error) {
+	if conf.IPTypeMMDBPath == "" {
+		return nil, nil
+	}
+	return NewMMDBIPTypeResolver(conf.IPTypeMMDBPath)
+}
+
+// MMDBIPTypeResolver resolves an ip type using a local MaxMind-format
+// database. Connection type, anonymous ip and enterprise databases are
+// supported.
+type MMDBIPTypeResolver struct {
+	reader *maxminddb.Reader
+}
+
+type mmdbIPTypeRecord struct {
+	ConnectionType    string `maxminddb:"connection_type"`
+	UserType          string `maxminddb:"user_type"`
+	IsHostingProvider bool   `maxminddb:"is_hosting_provider"`
+	Traits            struct {
+		ConnectionType    string `maxminddb:"connection_type"`
+		UserType          string `maxminddb:"user_type"`
+		IsHostingProvider bool   `maxminddb:"is_hosting_provider"`
+	} `maxminddb:"traits"`
+}
+
+// NewMMDBIPTypeResolver opens a database and creates a new mmdb ip type
+// resolver.
+func NewMMDBIPTypeResolver(path string) (*MMDBIPTypeResolver, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MMDBIPTypeResolver{reader}, nil
+}
+
+// IPType returns a type of ip address.
+func (r *MMDBIPTypeResolver) IPType(ip string) (string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", ErrBadIP
+	}
+
+	var rec mmdbIPTypeRecord
+	if err := r.reader.Lookup(addr, &rec); err != nil {
+		return "", err
+	}
+
+	if rec.IsHostingProvider || rec.Traits.IsHostingProvider {
+		return IPTypeDatacenter, nil
+	}
+
+	for _, v := range []string{rec.UserType, rec.Traits.UserType,
+		rec.ConnectionType, rec.Traits.ConnectionType} {
+		switch strings.ToLower(v) {
+		case "hosting", "content_delivery_network":
+			return IPTypeDatacenter, nil
+		case "cellular":
+			return IPTypeMobile, nil
+		case "residential", "cable/dsl":
+			return IPTypeResidential, nil
+		}
+	}
+
+	return "", ErrIPTypeNotFound
+}
+
+// Close closes the database.
+func (r *MMDBIPTypeResolver) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,60 @@
+package country
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// MMDBResolver resolves a country using a local MaxMind-format database,
+// so that ip addresses are not disclosed to third parties.
+type MMDBResolver struct {
+	reader *maxminddb.Reader
+}
+
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// NewMMDBResolver opens a database and creates a new mmdb resolver.
+func NewMMDBResolver(path string) (*MMDBResolver, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MMDBResolver{reader}, nil
+}
+
+// Country returns a country code by ip.
+func (r *MMDBResolver) Country(ip string) (string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", ErrBadIP
+	}
+
+	var rec mmdbRecord
+	if err := r.reader.Lookup(addr, &rec); err != nil {
+		return "", err
+	}
+
+	country := rec.Country.ISOCode
+	if country == "" {
+		country = rec.RegisteredCountry.ISOCode
+	}
+	if country == "" {
+		return "", ErrCountryNotFound
+	}
+
+	return strings.ToUpper(country), nil
+}
+
+// Close closes the database.
+func (r *MMDBResolver) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,132 @@
+package country
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver resolves a country code by ip.
+type Resolver interface {
+	Country(ip string) (string, error)
+}
+
+// NewResolver creates a resolver, which tries configured providers in
+// order and caches found countries.
+func NewResolver(conf *Config) (Resolver, error) {
+	var chain Chain
+	for _, v := range conf.Providers {
+		switch v {
+		case ProviderHTTP:
+			chain = append(chain, NewHTTPResolver(conf))
+		case ProviderMMDB:
+			r, err := NewMMDBResolver(conf.MMDBPath)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, r)
+		default:
+			return nil, ErrUnknownProvider
+		}
+	}
+
+	if len(chain) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	var r Resolver = chain
+	if conf.CacheTTL != 0 {
+		r = NewCache(r, time.Millisecond*time.Duration(conf.CacheTTL))
+	}
+
+	return r, nil
+}
+
+// HTTPResolver resolves a country using an external http service.
+type HTTPResolver struct {
+	conf *Config
+}
+
+// NewHTTPResolver creates a new http resolver.
+func NewHTTPResolver(conf *Config) *HTTPResolver {
+	return &HTTPResolver{conf}
+}
+
+// Country returns a country code by ip.
+func (r *HTTPResolver) Country(ip string) (string, error) {
+	url := strings.Replace(r.conf.URLTemplate, "{{ip}}", ip, 1)
+	return GetCountry(r.conf.Timeout, url, r.conf.Field)
+}
+
+// Chain is a resolver, which tries resolvers in order until one of them
+// finds a country.
+type Chain []Resolver
+
+// Country returns a country code by ip. An error of the last resolver is
+// returned, if no resolver finds a country.
+func (c Chain) Country(ip string) (string, error) {
+	err := error(ErrNoProviders)
+	for _, r := range c {
+		var country string
+		if country, err = r.Country(ip); err == nil {
+			if len(country) == 2 && country != UndefinedCountry {
+				return country, nil
+			}
+			err = ErrCountryNotFound
+		}
+	}
+	return "", err
+}
+
+type cacheEntry struct {
+	country string
+	expires time.Time
+}
+
+// Cache is a resolver, which caches countries found by another resolver.
+type Cache struct {
+	resolver Resolver
+	ttl      time.Duration
+	mtx      sync.Mutex
+	entries  map[string]cacheEntry
+}
+
+// NewCache creates a new caching resolver.
+func NewCache(resolver Resolver, ttl time.Duration) *Cache {
+	return &Cache{
+		resolver: resolver,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// Country returns a country code by ip.
+func (c *Cache) Country(ip string) (string, error) {
+	now := time.Now()
+
+	c.mtx.Lock()
+	entry, ok := c.entries[ip]
+	c.mtx.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry.country, nil
+	}
+
+	country, err := c.resolver.Country(ip)
+	if err != nil {
+		return "", err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// Drop expired entries not to grow indefinitely.
+	for k, v := range c.entries {
+		if !now.Before(v.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[ip] = cacheEntry{country, now.Add(c.ttl)}
+
+	return country, nil
+}
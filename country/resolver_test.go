@@ -0,0 +1,95 @@
+package country
+
+import (
+	"testing"
+	"time"
+)
+
+type resolverMock struct {
+	country string
+	err     error
+	calls   int
+}
+
+func (r *resolverMock) Country(ip string) (string, error) {
+	r.calls++
+	return r.country, r.err
+}
+
+func TestNewResolver(t *testing.T) {
+	conf := NewConfig()
+
+	conf.Providers = nil
+	if _, err := NewResolver(conf); err != ErrNoProviders {
+		t.Fatalf("expected %v, got %v", ErrNoProviders, err)
+	}
+
+	conf.Providers = []string{"unknown"}
+	if _, err := NewResolver(conf); err != ErrUnknownProvider {
+		t.Fatalf("expected %v, got %v", ErrUnknownProvider, err)
+	}
+
+	conf.Providers = []string{ProviderMMDB}
+	conf.MMDBPath = "not-existing.mmdb"
+	if _, err := NewResolver(conf); err == nil {
+		t.Fatal("error expected for missing database")
+	}
+}
+
+func TestHTTPResolver(t *testing.T) {
+	const field = "testCountry"
+
+	s := NewServerMock(field, "yy")
+	defer s.Close()
+
+	conf := NewConfig()
+	conf.Field = field
+	conf.Timeout = 1000
+	conf.URLTemplate = s.Server.URL + "/{{ip}}"
+
+	r, err := NewResolver(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err := r.Country("1.2.3.4"); err != nil || c != "YY" {
+		t.Fatalf("wrong country: %s, %v", c, err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	failed := &resolverMock{err: ErrInternal}
+	undefined := &resolverMock{country: UndefinedCountry}
+	ok := &resolverMock{country: "YY"}
+
+	c, err := Chain{failed, undefined, ok}.Country("1.2.3.4")
+	if err != nil || c != "YY" {
+		t.Fatalf("wrong country: %s, %v", c, err)
+	}
+
+	if _, err := (Chain{undefined, failed}).Country("1.2.3.4"); err != ErrInternal {
+		t.Fatalf("expected %v, got %v", ErrInternal, err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	r := &resolverMock{err: ErrInternal}
+	c := NewCache(r, time.Hour)
+
+	if _, err := c.Country("1.2.3.4"); err != ErrInternal {
+		t.Fatalf("expected %v, got %v", ErrInternal, err)
+	}
+
+	r.country, r.err = "YY", nil
+	for i := 0; i < 2; i++ {
+		if country, err := c.Country("1.2.3.4"); err != nil ||
+			country != "YY" {
+			t.Fatalf("wrong country: %s, %v", country, err)
+		}
+	}
+
+	if r.calls != 2 {
+		t.Fatalf("failures must not be cached and results must be,"+
+			" calls: %d", r.calls)
+	}
+}
@@ -0,0 +1,45 @@
+package ctl
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// client is an adapter on top of rpc client to talk to UI API.
+type client struct {
+	client *rpc.Client
+	token  string
+}
+
+// dial returns client with established connection and access token.
+func dial(ctx context.Context, endpoint, password string) (*client, error) {
+	c, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+	if err := c.Call(&token, "ui_getToken", password); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &client{c, token}, nil
+}
+
+func (c *client) close() {
+	c.client.Close()
+}
+
+func (c *client) userRole() (string, error) {
+	var role string
+	err := c.client.Call(&role, "ui_getUserRole")
+	return role, err
+}
+
+func (c *client) call(result interface{},
+	method string, args ...interface{}) error {
+	tkn := []interface{}{c.token}
+	return c.client.Call(result, "ui_"+method, append(tkn, args...)...)
+}
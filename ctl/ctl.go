@@ -0,0 +1,432 @@
+// Package ctl implements an operator command line client on top of the UI
+// JSON-RPC API.
+package ctl
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/privatix/dappctrl/data"
+)
+
+// PasswordEnv is an environment variable with a UI password used when the
+// password flag is not given.
+const PasswordEnv = "DAPPCTRL_PASSWORD"
+
+type env struct {
+	client *client
+	out    io.Writer
+	format string
+}
+
+type command struct {
+	usage string
+	run   func(e *env, fs *flag.FlagSet, args []string) error
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func uintArg(s string) (uint64, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, ErrBadArguments
+	}
+	return v, nil
+}
+
+type page struct {
+	offset *uint
+	limit  *uint
+}
+
+func pageFlags(fs *flag.FlagSet) page {
+	return page{
+		offset: fs.Uint("offset", 0, "Number of items to skip"),
+		limit:  fs.Uint("limit", 0, "Maximum number of items"),
+	}
+}
+
+var channelColumns = []column{
+	{"ID", "id"},
+	{"AGENT", "agent"},
+	{"CLIENT", "client"},
+	{"OFFERING", "offering"},
+	{"STATUS", "channelStatus"},
+	{"SERVICE", "serviceStatus"},
+	{"DEPOSIT", "totalDeposit"},
+	{"RECEIPT", "receiptBalance"},
+}
+
+var clientChannelColumns = []column{
+	{"ID", "id"},
+	{"AGENT", "agent"},
+	{"OFFERING", "offering"},
+	{"STATUS", "channelStatus.channelStatus"},
+	{"SERVICE", "channelStatus.serviceStatus"},
+	{"DEPOSIT", "totalDeposit"},
+	{"USAGE", "usage.current"},
+	{"COST", "usage.cost"},
+}
+
+var offeringColumns = []column{
+	{"ID", "id"},
+	{"NAME", "serviceName"},
+	{"STATUS", "status"},
+	{"COUNTRY", "country"},
+	{"SUPPLY", "currentSupply"},
+	{"UNIT PRICE", "unitPrice"},
+	{"HASH", "hash"},
+}
+
+var clientOfferingColumns = []column{
+	{"ID", "offering.id"},
+	{"AGENT", "offering.agent"},
+	{"COUNTRY", "offering.country"},
+	{"SUPPLY", "offering.currentSupply"},
+	{"UNIT PRICE", "offering.unitPrice"},
+	{"RATING", "rating"},
+}
+
+var jobColumns = []column{
+	{"ID", "ID"},
+	{"TYPE", "Type"},
+	{"STATUS", "Status"},
+	{"RELATED TYPE", "RelatedType"},
+	{"RELATED ID", "RelatedID"},
+	{"CREATED", "CreatedAt"},
+	{"TRIES", "TryCount"},
+}
+
+var ethTxColumns = []column{
+	{"ID", "id"},
+	{"HASH", "hash"},
+	{"METHOD", "method"},
+	{"STATUS", "status"},
+	{"ISSUED", "issued"},
+	{"RELATED TYPE", "relatedType"},
+	{"RELATED ID", "relatedID"},
+}
+
+var logColumns = []column{
+	{"TIME", "time"},
+	{"LEVEL", "level"},
+	{"MESSAGE", "message"},
+	{"CONTEXT", "context"},
+}
+
+var accountColumns = []column{
+	{"ID", "id"},
+	{"NAME", "name"},
+	{"ADDRESS", "ethAddr"},
+	{"DEFAULT", "isDefault"},
+	{"PTC", "ptcBalance"},
+	{"PSC", "pscBalance"},
+	{"ETH", "ethBalance"},
+}
+
+func getObject(objectType string) *command {
+	return &command{
+		usage: "<id>",
+		run: func(e *env, fs *flag.FlagSet, args []string) error {
+			if len(args) != 1 {
+				return ErrBadArguments
+			}
+
+			var raw json.RawMessage
+			err := e.client.call(&raw, "getObject", objectType, args[0])
+			if err != nil {
+				return err
+			}
+
+			return writeObject(e.out, e.format, raw)
+		},
+	}
+}
+
+func listChannels(e *env, fs *flag.FlagSet, args []string) error {
+	status := fs.String("status", "", "Comma separated channel statuses")
+	service := fs.String("service", "", "Comma separated service statuses")
+	p := pageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	role, err := e.client.userRole()
+	if err != nil {
+		return err
+	}
+
+	method, cols := "getAgentChannels", channelColumns
+	if role == data.RoleClient {
+		method, cols = "getClientChannels", clientChannelColumns
+	}
+
+	var raw json.RawMessage
+	if err := e.client.call(&raw, method, splitList(*status),
+		splitList(*service), *p.offset, *p.limit); err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, cols)
+}
+
+func changeChannelStatus(e *env, fs *flag.FlagSet, args []string) error {
+	if len(args) != 2 {
+		return ErrBadArguments
+	}
+	return e.client.call(nil, "changeChannelStatus", args[0], args[1])
+}
+
+func listOfferings(e *env, fs *flag.FlagSet, args []string) error {
+	product := fs.String("product", "", "Product id, agent only")
+	status := fs.String("status", "", "Comma separated statuses, agent only")
+	agent := fs.String("agent", "", "Agent address, client only")
+	countries := fs.String("country", "",
+		"Comma separated countries, client only")
+	p := pageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	role, err := e.client.userRole()
+	if err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	cols := offeringColumns
+	if role == data.RoleClient {
+		cols = clientOfferingColumns
+		err = e.client.call(&raw, "getClientOfferings", *agent,
+			0, 0, splitList(*countries), nil, *p.offset, *p.limit)
+	} else {
+		err = e.client.call(&raw, "getAgentOfferings", *product,
+			splitList(*status), *p.offset, *p.limit)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, cols)
+}
+
+func changeOfferingStatus(e *env, fs *flag.FlagSet, args []string) error {
+	gasPrice := fs.Uint64("gasprice", 0, "Gas price in wei")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 {
+		return ErrBadArguments
+	}
+	return e.client.call(nil, "changeOfferingStatus",
+		fs.Arg(0), fs.Arg(1), *gasPrice)
+}
+
+func listJobs(e *env, fs *flag.FlagSet, args []string) error {
+	jobType := fs.String("type", "", "Job type")
+	status := fs.String("status", "", "Comma separated statuses")
+	from := fs.String("from", "", "Created not before")
+	to := fs.String("to", "", "Created not after")
+	p := pageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	var raw json.RawMessage
+	if err := e.client.call(&raw, "getJobs", *jobType, *from, *to,
+		splitList(*status), *p.offset, *p.limit); err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, jobColumns)
+}
+
+func reactivateJob(e *env, fs *flag.FlagSet, args []string) error {
+	if len(args) != 1 {
+		return ErrBadArguments
+	}
+	return e.client.call(nil, "reactivateJob", args[0])
+}
+
+func listTransactions(e *env, fs *flag.FlagSet, args []string) error {
+	relType := fs.String("type", "", "Related object type")
+	relID := fs.String("id", "", "Related object id")
+	p := pageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	var raw json.RawMessage
+	if err := e.client.call(&raw, "getEthTransactions",
+		*relType, *relID, *p.offset, *p.limit); err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, ethTxColumns)
+}
+
+func listLogs(e *env, fs *flag.FlagSet, args []string) error {
+	levels := fs.String("level", "", "Comma separated levels")
+	search := fs.String("search", "", "Text to search for")
+	from := fs.String("from", "", "Logged not before")
+	to := fs.String("to", "", "Logged not after")
+	p := pageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	var raw json.RawMessage
+	if err := e.client.call(&raw, "getLogs", splitList(*levels),
+		*search, *from, *to, *p.offset, *p.limit); err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, logColumns)
+}
+
+func listAccounts(e *env, fs *flag.FlagSet, args []string) error {
+	var raw json.RawMessage
+	if err := e.client.call(&raw, "getAccounts"); err != nil {
+		return err
+	}
+
+	return writeItems(e.out, e.format, raw, accountColumns)
+}
+
+func transferTokens(e *env, fs *flag.FlagSet, args []string) error {
+	gasPrice := fs.Uint64("gasprice", 0, "Gas price in wei")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 3 {
+		return ErrBadArguments
+	}
+
+	amount, err := uintArg(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	return e.client.call(nil, "transferTokens",
+		fs.Arg(0), fs.Arg(1), amount, *gasPrice)
+}
+
+var commands = map[string]map[string]*command{
+	"channels": {
+		"list":   {"[-status s1,s2] [-service s1,s2]", listChannels},
+		"get":    getObject("channel"),
+		"status": {"<id> <action>", changeChannelStatus},
+	},
+	"offerings": {
+		"list": {"[-product id] [-status s1,s2] [-agent addr]" +
+			" [-country c1,c2]", listOfferings},
+		"get":    getObject("offering"),
+		"status": {"[-gasprice wei] <id> <action>", changeOfferingStatus},
+	},
+	"jobs": {
+		"list": {"[-type t] [-status s1,s2] [-from date] [-to date]",
+			listJobs},
+		"get":        getObject("job"),
+		"reactivate": {"<id>", reactivateJob},
+	},
+	"transactions": {
+		"list": {"[-type relType] [-id relID]", listTransactions},
+		"get":  getObject("ethTx"),
+	},
+	"logs": {
+		"list": {"[-level l1,l2] [-search text] [-from date] [-to date]",
+			listLogs},
+	},
+	"accounts": {
+		"list": {"", listAccounts},
+		"transfer": {"[-gasprice wei] <account> <ptc|psc> <amount>",
+			transferTokens},
+	},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: dappctrl ctl [-endpoint url] [-password pwd]"+
+		" [-format table|json] <object> <action> [arguments]")
+
+	objects := make([]string, 0, len(commands))
+	for k := range commands {
+		objects = append(objects, k)
+	}
+	sort.Strings(objects)
+
+	for _, obj := range objects {
+		actions := make([]string, 0, len(commands[obj]))
+		for k := range commands[obj] {
+			actions = append(actions, k)
+		}
+		sort.Strings(actions)
+
+		for _, act := range actions {
+			fmt.Fprintf(w, "  %s %s %s\n",
+				obj, act, commands[obj][act].usage)
+		}
+	}
+}
+
+// Execute runs an operator command, writing its output to a given writer.
+func Execute(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("ctl", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() { usage(os.Stderr) }
+
+	endpoint := fs.String("endpoint", "http://localhost:8888/http",
+		"UI API endpoint")
+	password := fs.String("password", "",
+		"UI password, "+PasswordEnv+" environment variable by default")
+	format := fs.String("format", FormatTable, "Output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "Connection timeout")
+
+	if err := fs.Parse(args); err != nil {
+		return ErrBadArguments
+	}
+
+	if *format != FormatTable && *format != FormatJSON {
+		return ErrBadFormat
+	}
+
+	if fs.NArg() < 2 {
+		usage(os.Stderr)
+		return ErrUnknownCommand
+	}
+
+	cmd, ok := commands[fs.Arg(0)][fs.Arg(1)]
+	if !ok {
+		usage(os.Stderr)
+		return ErrUnknownCommand
+	}
+
+	if *password == "" {
+		*password = os.Getenv(PasswordEnv)
+	}
+	if *password == "" {
+		return ErrNoPassword
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c, err := dial(ctx, *endpoint, *password)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	cmdFlags := flag.NewFlagSet(fs.Arg(0)+" "+fs.Arg(1),
+		flag.ContinueOnError)
+	cmdFlags.SetOutput(os.Stderr)
+
+	e := &env{client: c, out: out, format: *format}
+	return cmd.run(e, cmdFlags, fs.Args()[2:])
+}
@@ -0,0 +1,128 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util"
+)
+
+const (
+	testPassword = "secret"
+	testToken    = "token"
+)
+
+type testHandler struct {
+	channels []data.Channel
+	actions  []string
+}
+
+func (h *testHandler) GetToken(password string) (*string, error) {
+	if password != testPassword {
+		return nil, ErrBadArguments
+	}
+	tkn := testToken
+	return &tkn, nil
+}
+
+func (h *testHandler) GetUserRole() (*string, error) {
+	role := data.RoleAgent
+	return &role, nil
+}
+
+func (h *testHandler) GetAgentChannels(tkn string,
+	channelStatus, serviceStatus []string,
+	offset, limit uint) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"items":      h.channels,
+		"totalItems": len(h.channels),
+	}, nil
+}
+
+func (h *testHandler) GetObject(
+	tkn, objectType, id string) (json.RawMessage, error) {
+	return json.Marshal(h.channels[0])
+}
+
+func (h *testHandler) ChangeChannelStatus(tkn, channel, action string) error {
+	h.actions = append(h.actions, tkn+" "+channel+" "+action)
+	return nil
+}
+
+func newTestServer(t *testing.T, h *testHandler) *httptest.Server {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("ui", h); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(srv)
+}
+
+func TestExecute(t *testing.T) {
+	h := &testHandler{channels: []data.Channel{{
+		ID:            util.NewUUID(),
+		ChannelStatus: data.ChannelActive,
+		ServiceStatus: data.ServiceActive,
+		TotalDeposit:  100,
+	}}}
+
+	srv := newTestServer(t, h)
+	defer srv.Close()
+
+	run := func(args ...string) string {
+		var out bytes.Buffer
+		args = append([]string{"-endpoint", srv.URL,
+			"-password", testPassword}, args...)
+		err := Execute(args, &out)
+		util.TestExpectResult(t, "Execute", nil, err)
+		return out.String()
+	}
+
+	out := run("channels", "list", "-status", data.ChannelActive)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") ||
+		!strings.HasPrefix(lines[1], h.channels[0].ID) ||
+		!strings.Contains(lines[1], data.ServiceActive) {
+		t.Fatalf("unexpected channels table: %s", out)
+	}
+
+	out = run("-format", FormatJSON, "channels", "get", h.channels[0].ID)
+	var ch data.Channel
+	if err := json.Unmarshal([]byte(out), &ch); err != nil ||
+		ch.ID != h.channels[0].ID {
+		t.Fatalf("unexpected channel: %s", out)
+	}
+
+	run("channels", "status", h.channels[0].ID, "pause")
+	if len(h.actions) != 1 ||
+		h.actions[0] != testToken+" "+h.channels[0].ID+" pause" {
+		t.Fatalf("unexpected channel actions: %v", h.actions)
+	}
+
+	err := Execute([]string{"-endpoint", srv.URL, "-password", "bad",
+		"channels", "list"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("command with bad password succeeded")
+	}
+
+	err = Execute([]string{"-endpoint", srv.URL, "-password",
+		testPassword, "channels", "delete"}, &bytes.Buffer{})
+	util.TestExpectResult(t, "Execute", ErrUnknownCommand, err)
+}
+
+func TestWriteObject(t *testing.T) {
+	var out bytes.Buffer
+	raw := json.RawMessage(`{"b": {"c": 1}, "a": 12345678901234567890}`)
+	err := writeObject(&out, FormatTable, raw)
+	util.TestExpectResult(t, "writeObject", nil, err)
+
+	exp := "a  12345678901234567890\nb  {\"c\":1}\n"
+	if out.String() != exp {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
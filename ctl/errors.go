@@ -0,0 +1,21 @@
+package ctl
+
+import "github.com/privatix/dappctrl/util/errors"
+
+// Errors returned by operator commands.
+const (
+	// CRC16("github.com/privatix/dappctrl/ctl") = 0x4006
+	ErrUnknownCommand errors.Error = 0x4006<<8 + iota
+	ErrBadArguments
+	ErrBadFormat
+	ErrNoPassword
+)
+
+var errMsgs = errors.Messages{
+	ErrUnknownCommand: "unknown command",
+	ErrBadArguments:   "bad command arguments",
+	ErrBadFormat:      "unknown output format",
+	ErrNoPassword:     "password is not specified",
+}
+
+func init() { errors.InjectMessages(errMsgs) }
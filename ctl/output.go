@@ -0,0 +1,135 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// column is a table column with a dot separated path of a value within
+// a JSON object.
+type column struct {
+	title string
+	path  string
+}
+
+func decode(raw json.RawMessage) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func lookup(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number, bool:
+		return fmt.Sprint(v)
+	}
+
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+func writeJSON(w io.Writer, raw json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "    "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// writeItems writes a list result. Lists are either arrays or objects with
+// items field.
+func writeItems(w io.Writer, format string,
+	raw json.RawMessage, cols []column) error {
+	if format == FormatJSON {
+		return writeJSON(w, raw)
+	}
+
+	v, err := decode(raw)
+	if err != nil {
+		return err
+	}
+
+	if obj, ok := v.(map[string]interface{}); ok {
+		v = obj["items"]
+	}
+	items, _ := v.([]interface{})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+	fmt.Fprintln(tw, strings.Join(titles, "\t"))
+
+	for _, item := range items {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			cells[i] = cell(lookup(item, c.path))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// writeObject writes a single object, as a table of its fields sorted by
+// name.
+func writeObject(w io.Writer, format string, raw json.RawMessage) error {
+	if format == FormatJSON {
+		return writeJSON(w, raw)
+	}
+
+	v, err := decode(raw)
+	if err != nil {
+		return err
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		_, err := fmt.Fprintln(w, cell(v))
+		return err
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", k, cell(obj[k]))
+	}
+
+	return tw.Flush()
+}
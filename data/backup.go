@@ -0,0 +1,445 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/reform.v1"
+)
+
+// ArchiveFormat is a current version of backup archive format.
+const ArchiveFormat = 1
+
+// Backup archive kinds.
+const (
+	ArchiveBackup = "backup"
+	ArchiveExport = "export"
+)
+
+// Objects which can be exported.
+const (
+	ExportAccounts  = "accounts"
+	ExportTemplates = "templates"
+	ExportProducts  = "products"
+	ExportOfferings = "offerings"
+)
+
+// Archive encryption parameters.
+const (
+	ArchiveCipher = "aes-256-gcm"
+	ArchiveKDF    = "scrypt"
+)
+
+// Archive is a versioned envelope of a database backup or export. Payload is
+// a gzipped JSON with table rows, it is encrypted if Encryption is set.
+type Archive struct {
+	Format        int                `json:"format"`
+	Kind          string             `json:"kind"`
+	SchemaVersion int64              `json:"schemaVersion"`
+	Created       time.Time          `json:"created"`
+	Encryption    *ArchiveEncryption `json:"encryption,omitempty"`
+	Payload       []byte             `json:"payload"`
+}
+
+// ArchiveEncryption is a description of archive payload encryption.
+type ArchiveEncryption struct {
+	Cipher string `json:"cipher"`
+	KDF    string `json:"kdf"`
+	Salt   []byte `json:"salt"`
+	Nonce  []byte `json:"nonce"`
+}
+
+// archiveTable is a table with rows as returned by row_to_json().
+type archiveTable struct {
+	Name string            `json:"name"`
+	Rows []json.RawMessage `json:"rows"`
+}
+
+// backupTables are all the node state tables in order of their dependencies.
+// Log events are not a part of node state.
+var backupTables = []reform.View{
+	SettingTable, ContractTable, TemplateTable, ProductTable,
+	AccountTable, UserTable, OfferingTable, ChannelTable, SessionTable,
+	EndpointTable, JobTable, EthTxTable, ClosingTable, RatingTable,
+	TopUpPolicyTable, SpendingBudgetTable, UsageSampleTable,
+}
+
+// serialTables are tables with sequence generated ids.
+var serialTables = []reform.View{UsageSampleTable}
+
+type exportObject struct {
+	name  string
+	table reform.View
+	tail  string
+}
+
+// exportObjects are exportable objects in order of their dependencies.
+var exportObjects = []exportObject{
+	{ExportTemplates, TemplateTable, ""},
+	{ExportProducts, ProductTable, "WHERE is_server"},
+	{ExportAccounts, AccountTable, ""},
+	{ExportOfferings, OfferingTable,
+		"WHERE product IN (SELECT id FROM products WHERE is_server)"},
+}
+
+var exportDependencies = map[string][]string{
+	ExportProducts:  {ExportTemplates},
+	ExportOfferings: {ExportTemplates, ExportProducts},
+}
+
+func dumpTable(db *reform.Querier,
+	table reform.View, tail string) (*archiveTable, error) {
+	rows, err := db.Query(fmt.Sprintf(
+		"SELECT row_to_json(t) FROM %s t %s", table.Name(), tail))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tbl := &archiveTable{Name: table.Name(), Rows: []json.RawMessage{}}
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return nil, err
+		}
+		tbl.Rows = append(tbl.Rows, row)
+	}
+
+	return tbl, rows.Err()
+}
+
+func archiveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+func newArchiveCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := archiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func newArchive(kind string, schemaVersion int64,
+	tables []*archiveTable, passphrase string) (*Archive, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(tables); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	arch := &Archive{
+		Format:        ArchiveFormat,
+		Kind:          kind,
+		SchemaVersion: schemaVersion,
+		Created:       time.Now(),
+		Payload:       buf.Bytes(),
+	}
+
+	if passphrase == "" {
+		return arch, nil
+	}
+
+	enc := &ArchiveEncryption{
+		Cipher: ArchiveCipher,
+		KDF:    ArchiveKDF,
+		Salt:   make([]byte, 32),
+	}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newArchiveCipher(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return nil, err
+	}
+
+	arch.Encryption = enc
+	arch.Payload = aead.Seal(nil, enc.Nonce, arch.Payload, nil)
+
+	return arch, nil
+}
+
+func (a *Archive) tables(kind string, schemaVersion int64,
+	passphrase string) ([]*archiveTable, error) {
+	if a.Format != ArchiveFormat {
+		return nil, fmt.Errorf(
+			"unsupported archive format: %d", a.Format)
+	}
+
+	if a.Kind != kind {
+		return nil, fmt.Errorf("archive is %s, not %s", a.Kind, kind)
+	}
+
+	if a.SchemaVersion != schemaVersion {
+		return nil, fmt.Errorf("archive database schema version is %d,"+
+			" but the database is at version %d, migrate the database"+
+			" to version %d first", a.SchemaVersion, schemaVersion,
+			a.SchemaVersion)
+	}
+
+	payload := a.Payload
+	if a.Encryption != nil {
+		if a.Encryption.Cipher != ArchiveCipher ||
+			a.Encryption.KDF != ArchiveKDF {
+			return nil, fmt.Errorf("unsupported archive encryption")
+		}
+
+		if passphrase == "" {
+			return nil, fmt.Errorf("archive is encrypted")
+		}
+
+		aead, err := newArchiveCipher(passphrase, a.Encryption.Salt)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err = aead.Open(nil, a.Encryption.Nonce, payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt archive")
+		}
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var tables []*archiveTable
+	if err := json.NewDecoder(zr).Decode(&tables); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// inSnapshot runs a given function in a read-only transaction, which sees
+// a consistent snapshot of all the tables.
+func inSnapshot(db *reform.DB, f func(tx *reform.TX) error) error {
+	return db.InTransaction(func(tx *reform.TX) error {
+		if _, err := tx.Exec(`SET TRANSACTION
+			ISOLATION LEVEL REPEATABLE READ READ ONLY`); err != nil {
+			return err
+		}
+		return f(tx)
+	})
+}
+
+// BackupDB makes an archive with all the node state tables.
+func BackupDB(db *reform.DB, schemaVersion int64,
+	passphrase string) (*Archive, error) {
+	var tables []*archiveTable
+
+	err := inSnapshot(db, func(tx *reform.TX) error {
+		for _, v := range backupTables {
+			tbl, err := dumpTable(tx.Querier, v, "")
+			if err != nil {
+				return err
+			}
+			tables = append(tables, tbl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newArchive(ArchiveBackup, schemaVersion, tables, passphrase)
+}
+
+// ExportDB makes an archive with given objects and objects they depend on.
+func ExportDB(db *reform.DB, schemaVersion int64, objects []string,
+	passphrase string) (*Archive, error) {
+	selected := make(map[string]bool)
+	for _, v := range objects {
+		known := false
+		for _, obj := range exportObjects {
+			known = known || obj.name == v
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown export object: %s", v)
+		}
+
+		selected[v] = true
+		for _, dep := range exportDependencies[v] {
+			selected[dep] = true
+		}
+	}
+
+	var tables []*archiveTable
+	err := inSnapshot(db, func(tx *reform.TX) error {
+		for _, v := range exportObjects {
+			if !selected[v.name] {
+				continue
+			}
+
+			tbl, err := dumpTable(tx.Querier, v.table, v.tail)
+			if err != nil {
+				return err
+			}
+			tables = append(tables, tbl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newArchive(ArchiveExport, schemaVersion, tables, passphrase)
+}
+
+func insertRows(tx *reform.TX, tbl *archiveTable, tail string) (int, error) {
+	query := fmt.Sprintf(`INSERT INTO %[1]s
+		SELECT * FROM json_populate_record(NULL::%[1]s, $1) %[2]s`,
+		tbl.Name, tail)
+
+	var count int
+	for _, row := range tbl.Rows {
+		res, err := tx.Exec(query, string(row))
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to insert into %s: %s", tbl.Name, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += int(n)
+	}
+
+	return count, nil
+}
+
+func knownTable(name string) bool {
+	for _, v := range backupTables {
+		if v.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreDB restores a backup archive into an empty database having the same
+// schema version as the archive.
+func RestoreDB(db *reform.DB, arch *Archive,
+	schemaVersion int64, passphrase string) error {
+	tables, err := arch.tables(ArchiveBackup, schemaVersion, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return db.InTransaction(func(tx *reform.TX) error {
+		for _, v := range backupTables {
+			var count int
+			if err := tx.QueryRow(fmt.Sprintf(
+				"SELECT count(*) FROM %s",
+				v.Name())).Scan(&count); err != nil {
+				return err
+			}
+			if count != 0 {
+				return fmt.Errorf("database is not empty:"+
+					" %s table has %d rows", v.Name(), count)
+			}
+		}
+
+		for _, v := range tables {
+			if !knownTable(v.Name) {
+				return fmt.Errorf("unknown table: %s", v.Name)
+			}
+			if _, err := insertRows(tx, v, ""); err != nil {
+				return err
+			}
+		}
+
+		for _, v := range serialTables {
+			if _, err := tx.Exec(fmt.Sprintf(`SELECT setval(
+				pg_get_serial_sequence('%[1]s', 'id'),
+				COALESCE(max(id), 0) + 1, false) FROM %[1]s`,
+				v.Name())); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ImportDB imports an export archive into a database having the same schema
+// version as the archive. Existing objects are kept as is. It returns
+// a number of imported rows for each table.
+func ImportDB(db *reform.DB, arch *Archive, schemaVersion int64,
+	passphrase string) (map[string]int, error) {
+	tables, err := arch.tables(ArchiveExport, schemaVersion, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := make(map[string]int)
+	err = db.InTransaction(func(tx *reform.TX) error {
+		for _, v := range tables {
+			known := false
+			for _, obj := range exportObjects {
+				known = known || obj.table.Name() == v.Name
+			}
+			if !known {
+				return fmt.Errorf("unknown table: %s", v.Name)
+			}
+
+			n, err := insertRows(tx, v, "ON CONFLICT DO NOTHING")
+			if err != nil {
+				return err
+			}
+			imported[v.Name] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// WriteArchive writes an archive.
+func WriteArchive(w io.Writer, arch *Archive) error {
+	return json.NewEncoder(w).Encode(arch)
+}
+
+// ReadArchive reads an archive.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var arch Archive
+	if err := json.Unmarshal(raw, &arch); err != nil {
+		return nil, fmt.Errorf("failed to parse archive: %s", err)
+	}
+
+	return &arch, nil
+}
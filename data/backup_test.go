@@ -0,0 +1,102 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/privatix/dappctrl/util"
+)
+
+const testSchemaVersion = 11
+
+func TestArchiveEncryption(t *testing.T) {
+	tables := []*archiveTable{{
+		Name: SettingTable.Name(),
+		Rows: []json.RawMessage{[]byte(`{"key":"k","value":"v"}`)},
+	}}
+
+	arch, err := newArchive(ArchiveBackup,
+		testSchemaVersion, tables, "secret")
+	util.TestExpectResult(t, "newArchive", nil, err)
+
+	var buf bytes.Buffer
+	util.TestExpectResult(t, "WriteArchive", nil, WriteArchive(&buf, arch))
+
+	arch, err = ReadArchive(&buf)
+	util.TestExpectResult(t, "ReadArchive", nil, err)
+
+	for _, v := range []struct {
+		kind       string
+		version    int64
+		passphrase string
+	}{
+		{ArchiveExport, testSchemaVersion, "secret"},
+		{ArchiveBackup, testSchemaVersion + 1, "secret"},
+		{ArchiveBackup, testSchemaVersion, ""},
+		{ArchiveBackup, testSchemaVersion, "bad"},
+	} {
+		if _, err := arch.tables(
+			v.kind, v.version, v.passphrase); err == nil {
+			t.Fatalf("archive is read with %+v", v)
+		}
+	}
+
+	tables2, err := arch.tables(ArchiveBackup, testSchemaVersion, "secret")
+	util.TestExpectResult(t, "tables", nil, err)
+
+	if len(tables2) != 1 || tables2[0].Name != tables[0].Name ||
+		string(tables2[0].Rows[0]) != string(tables[0].Rows[0]) {
+		t.Fatal("wrong archive tables")
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	arch, err := BackupDB(db, testSchemaVersion, "")
+	util.TestExpectResult(t, "BackupDB", nil, err)
+
+	err = RestoreDB(db, arch, testSchemaVersion, "")
+	if err == nil {
+		t.Fatal("backup is restored into non-empty database")
+	}
+
+	CleanTestDB(t, db)
+
+	err = RestoreDB(db, arch, testSchemaVersion, "")
+	util.TestExpectResult(t, "RestoreDB", nil, err)
+
+	ReloadFromTestDB(t, db, fxt.Account, fxt.Product, fxt.Offering,
+		fxt.Channel, fxt.Endpoint, fxt.EthTx)
+}
+
+func TestExportImport(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	tpl := NewTestTemplate(TemplateOffer)
+	InsertToTestDB(t, db, tpl)
+
+	arch, err := ExportDB(db, testSchemaVersion,
+		[]string{ExportTemplates}, "")
+	util.TestExpectResult(t, "ExportDB", nil, err)
+
+	DeleteFromTestDB(t, db, tpl)
+
+	imported, err := ImportDB(db, arch, testSchemaVersion, "")
+	util.TestExpectResult(t, "ImportDB", nil, err)
+	defer DeleteFromTestDB(t, db, tpl)
+
+	if len(imported) != 1 || imported[TemplateTable.Name()] != 1 {
+		t.Fatalf("unexpected imported objects: %v", imported)
+	}
+
+	ReloadFromTestDB(t, db, tpl)
+
+	if _, err := ExportDB(db, testSchemaVersion,
+		[]string{"channels"}, ""); err == nil {
+		t.Fatal("unknown object is exported")
+	}
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"fmt"
+
+	"gopkg.in/reform.v1"
+)
+
+// FindSpendingBudgets returns spending budgets applied to a given client
+// account, i.e. budgets of the account and global budgets.
+func FindSpendingBudgets(db *reform.Querier,
+	account string) ([]*SpendingBudget, error) {
+	recs, err := db.SelectAllFrom(SpendingBudgetTable,
+		"WHERE account = $1 OR account IS NULL", account)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]*SpendingBudget, 0, len(recs))
+	for _, v := range recs {
+		budgets = append(budgets, v.(*SpendingBudget))
+	}
+	return budgets, nil
+}
+
+// BudgetSpent returns an amount of PRIX spent within a current period of
+// a given budget. Deposits of created and topped up channels are counted.
+func BudgetSpent(db *reform.Querier, budget *SpendingBudget) (uint64, error) {
+	var since string
+	switch budget.Period {
+	case BudgetDaily:
+		since = "date_trunc('day', now())"
+	case BudgetMonthly:
+		since = "date_trunc('month', now())"
+	case BudgetLifetime:
+		since = "'-infinity'"
+	default:
+		return 0, fmt.Errorf("unknown budget period: %s", budget.Period)
+	}
+
+	var spent uint64
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN jobs.type = $1
+					 THEN (jobs.data->>'deposit')::bigint
+					 ELSE (jobs.data->>'Deposit')::bigint END), 0)
+		  FROM jobs
+		  LEFT JOIN channels ON channels.id = jobs.related_id
+		  LEFT JOIN accounts ON accounts.eth_addr = channels.client
+		 WHERE jobs.status IN ($3, $4)
+		       AND jobs.created_at >= `+since+`
+		       AND ((jobs.type = $1 AND
+			     ($5::uuid IS NULL OR jobs.data->>'account' = $5::text))
+			    OR (jobs.type = $2 AND
+			     ($5::uuid IS NULL OR accounts.id = $5::uuid)))`,
+		JobClientPreChannelCreate, JobClientPreChannelTopUp,
+		JobActive, JobDone, budget.Account).Scan(&spent)
+	return spent, err
+}
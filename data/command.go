@@ -1,12 +1,14 @@
 package data
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/privatix/dappctrl/data/migration"
 	"github.com/privatix/dappctrl/statik"
@@ -23,6 +25,15 @@ type cmdFlag struct {
 // db-migrate - command to execute migration scripts
 // db-load-data - command to initialize database by default values
 // db-version - command to print the version of the database schema.
+// income-report - command to print agent income report.
+// db-backup - command to back up the node state into an archive.
+// db-restore - command to restore the node state from an archive.
+// db-export - command to export accounts, products, templates and offerings.
+// db-import - command to import exported objects.
+// product-create - command to create a product and its adapter config.
+// product-list - command to print agent products.
+// product-rotate-password - command to set a new product password.
+// product-delete - command to delete a product without offerings.
 func ExecuteCommand(args []string) error {
 	if len(args) == 0 {
 		return nil
@@ -57,10 +68,347 @@ func ExecuteCommand(args []string) error {
 		}
 		fmt.Println("database schema version:", version)
 		os.Exit(0)
+	case "db-backup", "db-restore", "db-export", "db-import":
+		f := readArchiveFlags(args)
+		if err := executeArchiveCommand(args[0], f); err != nil {
+			panic("failed to execute " + args[0] + ": " + err.Error())
+		}
+		os.Exit(0)
+	case "income-report":
+		f := readIncomeReportFlags(args)
+		if err := printIncomeReport(f); err != nil {
+			panic("failed to print income report: " + err.Error())
+		}
+		os.Exit(0)
+	case "product-create", "product-list",
+		"product-rotate-password", "product-delete":
+		f := readProductFlags(args)
+		if err := executeProductCommand(args[0], f); err != nil {
+			panic("failed to execute " + args[0] + ": " + err.Error())
+		}
+		os.Exit(0)
 	}
 	return nil
 }
 
+type productFlag struct {
+	connection string
+	id         string
+	pack       string
+	name       string
+	address    string
+	country    string
+	config     string
+	sess       string
+	out        string
+}
+
+func readProductFlags(args []string) *productFlag {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	connStr := fs.String("conn", "", "Database connection string")
+	id := fs.String("id", "", "Product id")
+	pack := fs.String("type", "", "Service type: "+
+		strings.Join(TemplatePacks, ", "))
+	name := fs.String("name", "", "Product name")
+	address := fs.String("address", "", "Service endpoint address")
+	country := fs.String("country", "", "Service country code")
+	config := fs.String("config", "{}",
+		"JSON object with endpoint message additional parameters")
+	sess := fs.String("sess", "ws://localhost:8000/ws",
+		"Session server endpoint for the service adapter")
+	out := fs.String("out", "", "Service adapter config file")
+
+	fs.Parse(args[1:])
+
+	if *connStr == "" {
+		panic(errors.New("connection string is not detected"))
+	}
+
+	return &productFlag{
+		connection: *connStr,
+		id:         *id,
+		pack:       *pack,
+		name:       *name,
+		address:    *address,
+		country:    *country,
+		config:     *config,
+		sess:       *sess,
+		out:        *out,
+	}
+}
+
+func executeProductCommand(cmd string, f *productFlag) error {
+	db, err := NewDBFromConnStr(f.connection)
+	if err != nil {
+		return err
+	}
+	defer CloseDB(db)
+
+	switch cmd {
+	case "product-create":
+		return createProduct(db, f)
+	case "product-list":
+		return listProducts(db)
+	case "product-rotate-password":
+		return rotateProductPassword(db, f)
+	case "product-delete":
+		if f.id == "" {
+			return errors.New("product id is required")
+		}
+		return DeleteProduct(db.Querier, f.id)
+	}
+
+	return fmt.Errorf("unknown product command: %s", cmd)
+}
+
+func createProduct(db *reform.DB, f *productFlag) error {
+	if f.pack == "" || f.address == "" || f.out == "" {
+		return errors.New("type, address and out are required")
+	}
+
+	pack, err := LoadTemplatePack(f.pack)
+	if err != nil {
+		return err
+	}
+
+	var params map[string]string
+	if err := json.Unmarshal([]byte(f.config), &params); err != nil {
+		return fmt.Errorf("failed to parse product config: %s", err)
+	}
+
+	prod, password, err := BootstrapProduct(db, pack, f.name,
+		f.address, f.country, params)
+	if err != nil {
+		return err
+	}
+
+	conf, err := NewAdapterConfig(pack.Name, prod, password, f.sess)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteAdapterConfig(f.out, conf); err != nil {
+		return err
+	}
+
+	fmt.Println("product:", prod.ID)
+	fmt.Println("adapter config:", f.out)
+	return nil
+}
+
+func listProducts(db *reform.DB) error {
+	prods, err := FindServerProducts(db.Querier)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tADDRESS\tCOUNTRY")
+	for _, v := range prods {
+		var addr, country string
+		if v.ServiceEndpointAddress != nil {
+			addr = *v.ServiceEndpointAddress
+		}
+		if v.Country != nil {
+			country = *v.Country
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.ID, v.Name, addr, country)
+	}
+	return w.Flush()
+}
+
+func rotateProductPassword(db *reform.DB, f *productFlag) error {
+	if f.id == "" {
+		return errors.New("product id is required")
+	}
+
+	var conf *AdapterConfig
+	if f.out != "" {
+		var err error
+		if conf, err = ReadAdapterConfig(f.out); err != nil {
+			return err
+		}
+		if conf.Sess.Product != f.id {
+			return fmt.Errorf(
+				"%s is not an adapter config of %s product",
+				f.out, f.id)
+		}
+	}
+
+	password, err := RotateProductPassword(db.Querier, f.id)
+	if err != nil {
+		return err
+	}
+
+	if conf == nil {
+		fmt.Println("password:", password)
+		return nil
+	}
+
+	conf.Sess.Password = password
+	return WriteAdapterConfig(f.out, conf)
+}
+
+// ArchivePassphraseEnv is an environment variable with an archive
+// passphrase used when the passphrase flag is not given.
+const ArchivePassphraseEnv = "DAPPCTRL_ARCHIVE_PASSPHRASE"
+
+type archiveFlag struct {
+	connection string
+	file       string
+	passphrase string
+	objects    []string
+}
+
+func readArchiveFlags(args []string) *archiveFlag {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	connStr := fs.String("conn", "", "Database connection string")
+	file := fs.String("file", "", "Archive file")
+	passphrase := fs.String("passphrase", "", "Archive passphrase, "+
+		ArchivePassphraseEnv+" environment variable by default")
+	objects := fs.String("objects", strings.Join([]string{
+		ExportAccounts, ExportTemplates, ExportProducts,
+		ExportOfferings}, ","), "Comma separated objects to export")
+
+	fs.Parse(args[1:])
+
+	if *connStr == "" {
+		panic(errors.New("connection string is not detected"))
+	}
+
+	if *file == "" {
+		panic(errors.New("archive file is not specified"))
+	}
+
+	if *passphrase == "" {
+		*passphrase = os.Getenv(ArchivePassphraseEnv)
+	}
+
+	return &archiveFlag{
+		connection: *connStr,
+		file:       *file,
+		passphrase: *passphrase,
+		objects:    strings.Split(*objects, ","),
+	}
+}
+
+func executeArchiveCommand(cmd string, f *archiveFlag) error {
+	version, err := migration.Version(f.connection)
+	if err != nil {
+		return err
+	}
+
+	db, err := NewDBFromConnStr(f.connection)
+	if err != nil {
+		return err
+	}
+	defer CloseDB(db)
+
+	var arch *Archive
+	switch cmd {
+	case "db-backup":
+		arch, err = BackupDB(db, version, f.passphrase)
+	case "db-export":
+		arch, err = ExportDB(db, version, f.objects, f.passphrase)
+	default:
+		return importArchive(cmd, db, version, f)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.file,
+		os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteArchive(file, arch)
+}
+
+func importArchive(cmd string, db *reform.DB,
+	version int64, f *archiveFlag) error {
+	file, err := os.Open(f.file)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	arch, err := ReadArchive(file)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "db-restore" {
+		return RestoreDB(db, arch, version, f.passphrase)
+	}
+
+	imported, err := ImportDB(db, arch, version, f.passphrase)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range exportObjects {
+		if n, ok := imported[v.table.Name()]; ok {
+			fmt.Printf("%s: %d imported\n", v.table.Name(), n)
+		}
+	}
+	return nil
+}
+
+type incomeReportFlag struct {
+	connection string
+	bucket     string
+	groupBy    string
+	dateFrom   string
+	dateTo     string
+	format     string
+}
+
+func readIncomeReportFlags(args []string) *incomeReportFlag {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	connStr := fs.String("conn", "", "Database connection string")
+	bucket := fs.String("bucket", IncomeMonth,
+		"Time bucket: day, week or month")
+	groupBy := fs.String("group", IncomeTotal,
+		"Group by offering, product or client")
+	dateFrom := fs.String("from", "", "Report period start")
+	dateTo := fs.String("to", "", "Report period end")
+	format := fs.String("format", IncomeCSV, "Output format: csv or json")
+
+	fs.Parse(args[1:])
+
+	if *connStr == "" {
+		panic(errors.New("connection string is not detected"))
+	}
+
+	return &incomeReportFlag{
+		connection: *connStr,
+		bucket:     *bucket,
+		groupBy:    *groupBy,
+		dateFrom:   *dateFrom,
+		dateTo:     *dateTo,
+		format:     *format,
+	}
+}
+
+func printIncomeReport(f *incomeReportFlag) error {
+	db, err := NewDBFromConnStr(f.connection)
+	if err != nil {
+		return err
+	}
+	defer CloseDB(db)
+
+	items, err := IncomeReport(db.Querier,
+		f.bucket, f.groupBy, f.dateFrom, f.dateTo)
+	if err != nil {
+		return err
+	}
+
+	return WriteIncomeReport(os.Stdout, items, f.format)
+}
+
 func readFlags(args []string) *cmdFlag {
 	connStr := flag.String("conn", "", "Database connection string")
 	version := flag.Int("version", 0, "Migrate to version")
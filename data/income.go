@@ -0,0 +1,158 @@
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gopkg.in/reform.v1"
+)
+
+// Income report time buckets.
+const (
+	IncomeDay   = "day"
+	IncomeWeek  = "week"
+	IncomeMonth = "month"
+)
+
+// Income report groupings.
+const (
+	IncomeTotal      = ""
+	IncomeByOffering = "offering"
+	IncomeByProduct  = "product"
+	IncomeByClient   = "client"
+)
+
+// Income report formats.
+const (
+	IncomeJSON = "json"
+	IncomeCSV  = "csv"
+)
+
+// IncomeReportItem is an agent income within a time bucket. Settled income
+// is a balance of channel closings recorded from blockchain, unsettled income
+// is a balance of last receipts of channels without a closing.
+type IncomeReportItem struct {
+	Period    time.Time `json:"period"`
+	Group     string    `json:"group,omitempty"`
+	Settled   uint64    `json:"settled"`
+	Unsettled uint64    `json:"unsettled"`
+}
+
+var incomeGroupColumns = map[string]string{
+	IncomeTotal:      "''",
+	IncomeByOffering: "income.offering::text",
+	IncomeByProduct:  "income.product::text",
+	IncomeByClient:   "income.client::text",
+}
+
+// IncomeReport returns agent income split into time buckets and grouped by
+// offerings, products or clients. Empty dateFrom or dateTo leaves a report
+// period unbounded.
+func IncomeReport(db *reform.Querier, bucket, groupBy,
+	dateFrom, dateTo string) ([]IncomeReportItem, error) {
+	if bucket != IncomeDay && bucket != IncomeWeek && bucket != IncomeMonth {
+		return nil, fmt.Errorf("unknown income time bucket: %s", bucket)
+	}
+
+	group, ok := incomeGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown income grouping: %s", groupBy)
+	}
+
+	// Settled income is read from recorded closings and dated by channel
+	// close, unsettled income is dated by last usage of a channel.
+	rows, err := db.Query(`
+		WITH income AS (
+			SELECT channels.offering,
+			       offerings.product,
+			       channels.client,
+			       CASE WHEN closings.id IS NULL
+				    THEN channels.receipt_balance
+				    ELSE closings.balance
+			       END AS amount,
+			       closings.id IS NOT NULL AS settled,
+			       CASE WHEN closings.id IS NOT NULL
+				    THEN COALESCE((SELECT MAX(jobs.created_at)
+						     FROM jobs
+						    WHERE jobs.related_id = channels.id
+							  AND jobs.type IN ($1, $2)
+							  AND jobs.status = $3),
+						  channels.service_changed_time,
+						  channels.prepared_at)
+				    ELSE COALESCE((SELECT MAX(sessions.last_usage_time)
+						     FROM sessions
+						    WHERE sessions.channel = channels.id),
+						  channels.prepared_at)
+			       END AS earned_at
+			  FROM channels
+			  JOIN offerings ON offerings.id = channels.offering
+			  LEFT JOIN closings
+			       ON closings.agent = channels.agent
+				  AND closings.client = channels.client
+				  AND closings.block = channels.block
+			 WHERE channels.agent IN (SELECT eth_addr FROM accounts)
+		)
+		SELECT date_trunc($4, income.earned_at) AS period,
+		       `+group+` AS grp,
+		       SUM(CASE WHEN income.settled THEN income.amount ELSE 0 END),
+		       SUM(CASE WHEN income.settled THEN 0 ELSE income.amount END)
+		  FROM income
+		 WHERE income.amount > 0
+		       AND ($5 = '' OR income.earned_at >= $5::timestamptz)
+		       AND ($6 = '' OR income.earned_at < $6::timestamptz)
+		 GROUP BY period, grp
+		 ORDER BY period, grp`,
+		JobAgentAfterCooperativeClose, JobAgentAfterUncooperativeClose,
+		JobDone, bucket, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []IncomeReportItem
+	for rows.Next() {
+		var item IncomeReportItem
+		if err := rows.Scan(&item.Period, &item.Group,
+			&item.Settled, &item.Unsettled); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// WriteIncomeReport writes income report items in a given format.
+func WriteIncomeReport(w io.Writer,
+	items []IncomeReportItem, format string) error {
+	switch format {
+	case IncomeJSON:
+		if items == nil {
+			items = []IncomeReportItem{}
+		}
+		return json.NewEncoder(w).Encode(items)
+	case IncomeCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{
+			"period", "group", "settled", "unsettled"}); err != nil {
+			return err
+		}
+		for _, v := range items {
+			if err := cw.Write([]string{
+				v.Period.Format(time.RFC3339),
+				v.Group,
+				strconv.FormatUint(v.Settled, 10),
+				strconv.FormatUint(v.Unsettled, 10),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown income report format: %s", format)
+}
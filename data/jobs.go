@@ -1,5 +1,9 @@
 package data
 
+import (
+	"time"
+)
+
 // Job creators.
 const (
 	JobUser           = "user"
@@ -32,6 +36,7 @@ const (
 	JobClientPreChannelCreate               = "clientPreChannelCreate"
 	JobClientAfterChannelCreate             = "clientAfterChannelCreate"
 	JobClientPreChannelTopUp                = "clientPreChannelTopUp"
+	JobClientAskChannelTopUp                = "clientAskChannelTopUp"
 	JobClientAfterChannelTopUp              = "clientAfterChannelTopUp"
 	JobClientPreUncooperativeCloseRequest   = "clientPreUncooperativeCloseRequest"
 	JobClientAfterUncooperativeCloseRequest = "clientAfterUncooperativeCloseRequest"
@@ -42,10 +47,17 @@ const (
 	JobClientPreServiceUnsuspend            = "clientPreServiceUnsuspend"
 	JobClientPreServiceTerminate            = "clientPreServiceTerminate"
 	JobClientEndpointGet                    = "clientEndpointGet"
+	JobClientEndpointRefresh                = "clientEndpointRefresh"
+	JobClientVerifyEndpoint                 = "clientVerifyEndpoint"
 	JobClientAfterOfferingMsgBCPublish      = "clientAfterOfferingMsgBCPublish"
 	JobClientAfterOfferingPopUp             = "clientAfterOfferingPopUp"
 	JobClientAfterOfferingDelete            = "clientAfterOfferingDelete"
 	JobClientRecordClosing                  = "clientRecordClosing"
+	JobClientPreChannelFailover             = "clientPreChannelFailover"
+	JobClientBudgetWarning                  = "clientBudgetWarning"
+	JobClientAgentVanished                  = "clientAgentVanished"
+	JobClientVerifyUsage                    = "clientVerifyUsage"
+	JobClientUsageDiscrepancy               = "clientUsageDiscrepancy"
 	JobAgentAfterChannelCreate              = "agentAfterChannelCreate"
 	JobAgentAfterChannelTopUp               = "agentAfterChannelTopUp"
 	JobAgentAfterUncooperativeCloseRequest  = "agentAfterUncooperativeCloseRequest"
@@ -54,7 +66,10 @@ const (
 	JobAgentPreServiceSuspend               = "agentPreServiceSuspend"
 	JobAgentPreServiceUnsuspend             = "agentPreServiceUnsuspend"
 	JobAgentPreServiceTerminate             = "agentPreServiceTerminate"
+	JobAgentPreChannelDispute               = "agentPreChannelDispute"
+	JobAgentPreCooperativeClose             = "agentPreCooperativeClose"
 	JobAgentPreEndpointMsgCreate            = "agentPreEndpointMsgCreate"
+	JobAgentPreEndpointMsgRotate            = "agentPreEndpointMsgRotate"
 	JobAgentPreOfferingMsgBCPublish         = "agentPreOfferingMsgBCPublish"
 	JobAgentAfterOfferingMsgBCPublish       = "agentAfterOfferingMsgBCPublish"
 	JobAgentPreOfferingDelete               = "agentPreOfferingDelete"
@@ -110,6 +125,30 @@ type JobCreateChannelData struct {
 	Deposit  uint
 }
 
+// JobBudgetWarning is a warning about a spending budget close to exhaustion.
+type JobBudgetWarning struct {
+	Budget    *SpendingBudget `json:"budget"`
+	Spent     uint64          `json:"spent"`
+	Threshold uint64          `json:"threshold"`
+}
+
+// JobBudgetWarningData is a data for client budget warning job.
+type JobBudgetWarningData struct {
+	Warnings []JobBudgetWarning `json:"warnings"`
+}
+
+// JobAgentVanishedData is a data for client agent vanished job.
+type JobAgentVanishedData struct {
+	Failures uint      `json:"failures"`
+	Since    time.Time `json:"since"`
+}
+
+// JobUsageDiscrepancyData is a data for client usage discrepancy job.
+type JobUsageDiscrepancyData struct {
+	Measured uint64 `json:"measured"`
+	Charged  uint64 `json:"charged"`
+}
+
 // JobEndpointCreateData is a data for client endpoint create job.
 type JobEndpointCreateData struct {
 	EndpointSealed []byte
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/privatix/dappctrl/data"
+)
+
+// Feed delivers log events written into database to subscribers. Subscriber
+// callbacks are called synchronously by loggers, so they must not block
+// and must not log.
+type Feed struct {
+	mtx  sync.RWMutex
+	subs map[string]func(*data.LogEvent)
+}
+
+// NewFeed creates a new log event feed.
+func NewFeed() *Feed {
+	return &Feed{subs: make(map[string]func(*data.LogEvent))}
+}
+
+// Subscribe adds a subscriber with a given id.
+func (f *Feed) Subscribe(id string, cb func(*data.LogEvent)) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.subs[id] = cb
+}
+
+// Unsubscribe removes a subscriber with a given id.
+func (f *Feed) Unsubscribe(id string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	delete(f.subs, id)
+}
+
+func (f *Feed) publish(e *data.LogEvent) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	for _, cb := range f.subs {
+		cb(e)
+	}
+}
@@ -24,12 +24,14 @@ func NewConfig() *Config {
 
 type dbLogger struct {
 	*log.LoggerBase
-	db *reform.DB
+	db   *reform.DB
+	feed *Feed
 }
 
-// NewLogger creates a new database logger.
-func NewLogger(conf *Config, db *reform.DB) (log.Logger, error) {
-	l := &dbLogger{db: db}
+// NewLogger creates a new database logger. Written log events are published
+// to a given feed, if it's not nil.
+func NewLogger(conf *Config, db *reform.DB, feed *Feed) (log.Logger, error) {
+	l := &dbLogger{db: db, feed: feed}
 
 	base, err := log.NewLoggerBase(conf.BaseConfig, l.log)
 	if err != nil {
@@ -47,11 +49,21 @@ func (l *dbLogger) log(lvl log.Level, msg string,
 		return err
 	}
 
-	return l.db.Insert(&data.LogEvent{
+	e := &data.LogEvent{
 		Time:    time.Now(),
 		Level:   lvl,
 		Message: msg,
 		Context: ctxd,
 		Stack:   stack,
-	})
+	}
+
+	if err := l.db.Insert(e); err != nil {
+		return err
+	}
+
+	if l.feed != nil {
+		l.feed.publish(e)
+	}
+
+	return nil
 }
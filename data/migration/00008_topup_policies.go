@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00008, Down00008)
+}
+
+// Up00008 creates deposit top up policies table.
+func Up00008(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00008_topup_policies_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00008 destroys deposit top up policies table.
+func Down00008(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00008_topup_policies_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00009, Down00009)
+}
+
+// Up00009 creates spending budgets table.
+func Up00009(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00009_spending_budgets_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00009 destroys spending budgets table.
+func Down00009(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00009_spending_budgets_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00010, Down00010)
+}
+
+// Up00010 creates usage samples table.
+func Up00010(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00010_usage_samples_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00010 destroys usage samples table.
+func Down00010(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00010_usage_samples_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
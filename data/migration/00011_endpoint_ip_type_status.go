@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00011, Down00011)
+}
+
+// Up00011 adds ip type status to endpoints.
+func Up00011(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00011_endpoint_ip_type_status_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00011 removes ip type status from endpoints.
+func Down00011(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00011_endpoint_ip_type_status_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
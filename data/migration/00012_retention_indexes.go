@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00012, Down00012)
+}
+
+// Up00012 creates indexes used by data pruning and listings.
+func Up00012(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00012_retention_indexes_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00012 drops indexes used by data pruning and listings.
+func Down00012(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00012_retention_indexes_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
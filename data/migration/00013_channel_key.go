@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00013, Down00013)
+}
+
+// channelKey computes a channel key the same way as data.ChannelKey, which
+// can't be used here, as data package depends on this one.
+func channelKey(client, agent string, block uint32,
+	offeringHash string) (string, error) {
+	clientAddr, err := hex.DecodeString(strings.TrimSpace(client))
+	if err != nil {
+		return "", err
+	}
+
+	agentAddr, err := hex.DecodeString(strings.TrimSpace(agent))
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := base64.URLEncoding.DecodeString(
+		strings.TrimSpace(offeringHash))
+	if err != nil {
+		return "", err
+	}
+
+	var blockBytes [4]byte
+	binary.BigEndian.PutUint32(blockBytes[:], block)
+
+	key := crypto.Keccak256(common.BytesToAddress(clientAddr).Bytes(),
+		common.BytesToAddress(agentAddr).Bytes(), blockBytes[:],
+		common.BytesToHash(hash).Bytes())
+
+	return base64.URLEncoding.EncodeToString(key), nil
+}
+
+// Up00013 adds an indexed channel key column and fills it for existing
+// channels.
+func Up00013(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00013_channel_key_up.sql")
+	if err != nil {
+		return err
+	}
+	if err := exec(string(query), tx); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT c.id, c.client, c.agent, c.block, o.hash
+		  FROM channels c JOIN offerings o ON o.id = c.offering
+		 WHERE c.block > 0`)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var id, client, agent, hash string
+		var block uint32
+		if err := rows.Scan(&id, &client, &agent, &block, &hash); err != nil {
+			rows.Close()
+			return err
+		}
+
+		key, err := channelKey(client, agent, block, hash)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		keys[id] = key
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, key := range keys {
+		if _, err := tx.Exec(`UPDATE channels SET channel_key = $1
+			WHERE id = $2`, key, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down00013 removes channel key column.
+func Down00013(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00013_channel_key_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
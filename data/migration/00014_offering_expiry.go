@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00014, Down00014)
+}
+
+// Up00014 adds expiry time to offerings.
+func Up00014(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00014_offering_expiry_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00014 removes expiry time from offerings.
+func Down00014(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00014_offering_expiry_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+	"github.com/privatix/dappctrl/statik"
+)
+
+func init() {
+	goose.AddMigration(Up00015, Down00015)
+}
+
+// Up00015 adds trial limits to products and free units to channels.
+func Up00015(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00015_trial_units_up.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
+
+// Down00015 removes trial limits and free units.
+func Down00015(tx *sql.Tx) error {
+	query, err := statik.ReadFile("/scripts/migration/00015_trial_units_down.sql")
+	if err != nil {
+		return err
+	}
+	return exec(string(query), tx)
+}
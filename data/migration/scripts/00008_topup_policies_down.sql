@@ -0,0 +1,4 @@
+DELETE FROM jobs WHERE type = 'clientAskChannelTopUp';
+
+DROP TABLE topup_policies;
+DROP TYPE topup_mode;
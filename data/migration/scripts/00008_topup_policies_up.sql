@@ -0,0 +1,22 @@
+-- Deposit top up modes.
+CREATE TYPE topup_mode AS ENUM (
+    'auto', -- top up automatically
+    'ask' -- ask user before top up
+);
+
+-- Deposit top up policies of client channels.
+CREATE TABLE topup_policies (
+    channel uuid PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    mode topup_mode NOT NULL,
+    amount bigint NOT NULL DEFAULT 0 -- fixed amount to top up with, 0 if not used
+        CONSTRAINT positive_amount CHECK (topup_policies.amount >= 0),
+
+    min_units_multiple bigint NOT NULL DEFAULT 0 -- top up with a price of this number of offering min units, 0 if not used
+        CONSTRAINT positive_min_units_multiple CHECK (topup_policies.min_units_multiple >= 0),
+
+    max_deposit bigint NOT NULL DEFAULT 0 -- max total deposit of channel, 0 if unlimited
+        CONSTRAINT positive_max_deposit CHECK (topup_policies.max_deposit >= 0),
+
+    daily_cap bigint NOT NULL DEFAULT 0 -- max amount of top ups for the last 24 hours, 0 if unlimited
+        CONSTRAINT positive_daily_cap CHECK (topup_policies.daily_cap >= 0)
+);
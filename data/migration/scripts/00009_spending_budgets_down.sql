@@ -0,0 +1,4 @@
+DELETE FROM jobs WHERE type = 'clientBudgetWarning';
+
+DROP TABLE spending_budgets;
+DROP TYPE budget_period;
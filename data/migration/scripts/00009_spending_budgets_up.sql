@@ -0,0 +1,18 @@
+-- Spending budget periods.
+CREATE TYPE budget_period AS ENUM (
+    'daily', -- calendar day
+    'monthly', -- calendar month
+    'lifetime' -- whole time
+);
+
+-- Spending budgets of client accounts.
+CREATE TABLE spending_budgets (
+    id uuid PRIMARY KEY,
+    account uuid REFERENCES accounts(id) ON DELETE CASCADE, -- client account, NULL for a global budget
+    period budget_period NOT NULL,
+    amount bigint NOT NULL -- max amount of PRIX to spend for the period
+        CONSTRAINT positive_amount CHECK (spending_budgets.amount > 0)
+);
+
+CREATE UNIQUE INDEX spending_budgets_account_period
+    ON spending_budgets (COALESCE(account, '00000000-0000-0000-0000-000000000000'), period);
@@ -0,0 +1,14 @@
+-- Usage samples of client sessions.
+CREATE TABLE usage_samples (
+    id bigserial PRIMARY KEY,
+    session uuid NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    channel uuid NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    time timestamp with time zone NOT NULL, -- time, when units were used
+    units bigint NOT NULL -- units used since previous sample
+        CONSTRAINT positive_units CHECK (usage_samples.units >= 0),
+
+    downsampled boolean NOT NULL DEFAULT false -- whether sample is merged into an hourly one
+);
+
+CREATE INDEX usage_samples_channel_time ON usage_samples (channel, time);
+CREATE INDEX usage_samples_time ON usage_samples (time);
@@ -0,0 +1,4 @@
+DELETE FROM jobs WHERE type = 'clientVerifyEndpoint';
+
+ALTER TABLE endpoints
+DROP ip_type_status;
@@ -0,0 +1,3 @@
+-- Result of checking an ip type by agent`s ip address.
+ALTER TABLE endpoints
+ADD ip_type_status country_status_type;
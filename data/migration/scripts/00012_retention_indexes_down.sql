@@ -0,0 +1,7 @@
+DROP INDEX closings_block;
+DROP INDEX log_events_time;
+DROP INDEX usage_samples_session;
+DROP INDEX sessions_started;
+DROP INDEX eth_txs_job;
+DROP INDEX eth_txs_issued;
+DROP INDEX jobs_created_at;
@@ -0,0 +1,8 @@
+-- Indexes used by data retention and by listings sorted by time.
+CREATE INDEX jobs_created_at ON jobs (created_at);
+CREATE INDEX eth_txs_issued ON eth_txs (issued);
+CREATE INDEX eth_txs_job ON eth_txs (job);
+CREATE INDEX sessions_started ON sessions (started);
+CREATE INDEX usage_samples_session ON usage_samples (session);
+CREATE INDEX log_events_time ON log_events (time);
+CREATE INDEX closings_block ON closings (block);
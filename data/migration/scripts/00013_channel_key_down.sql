@@ -0,0 +1,2 @@
+DROP INDEX channels_channel_key;
+ALTER TABLE channels DROP COLUMN channel_key;
@@ -0,0 +1,3 @@
+-- Channel key used by agents to find a channel requested by a client.
+ALTER TABLE channels ADD COLUMN channel_key text;
+CREATE INDEX channels_channel_key ON channels (channel_key);
@@ -0,0 +1,2 @@
+ALTER TABLE offerings
+DROP expires_at;
@@ -0,0 +1,3 @@
+-- Time after which an offering message is stale.
+ALTER TABLE offerings
+ADD expires_at timestamp with time zone;
@@ -0,0 +1,8 @@
+ALTER TABLE channels
+DROP free_units;
+
+ALTER TABLE products
+DROP trial_by_ip;
+
+ALTER TABLE products
+DROP trial_units;
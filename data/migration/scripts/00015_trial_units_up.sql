@@ -0,0 +1,13 @@
+-- Max free units a client can get in all channels of a product.
+ALTER TABLE products
+ADD trial_units bigint
+    CONSTRAINT positive_trial_units CHECK (products.trial_units >= 0);
+
+-- Whether free units are also limited per client IP address.
+ALTER TABLE products
+ADD trial_by_ip boolean NOT NULL DEFAULT false;
+
+-- Free units granted to a channel by agent.
+ALTER TABLE channels
+ADD free_units bigint NOT NULL DEFAULT 0
+    CONSTRAINT positive_free_units CHECK (channels.free_units >= 0);
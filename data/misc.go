@@ -216,6 +216,19 @@ func ChannelKey(client, agent HexString, block uint32,
 		common.BytesToHash(hash).Bytes()), nil
 }
 
+// SetChannelKey computes a key of a channel and stores it in the channel.
+func SetChannelKey(ch *Channel, offeringHash HexString) error {
+	key, err := ChannelKey(ch.Client, ch.Agent, ch.Block, offeringHash)
+	if err != nil {
+		return err
+	}
+
+	ch.Key = new(Base64String)
+	*ch.Key = FromBytes(key)
+
+	return nil
+}
+
 // ComputePrice calculates price for units of offering.
 func ComputePrice(offering *Offering, units uint64) uint64 {
 	return units*offering.UnitPrice + offering.SetupPrice
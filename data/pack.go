@@ -0,0 +1,226 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/statik"
+	"github.com/privatix/dappctrl/util"
+)
+
+// Service template packs embedded into the statik filesystem.
+const (
+	PackWireGuard   = "wireguard"
+	PackProxy       = "proxy"
+	PackShadowsocks = "shadowsocks"
+)
+
+// TemplatePacks is a list of all the embedded template packs.
+var TemplatePacks = []string{PackWireGuard, PackProxy, PackShadowsocks}
+
+// TemplatePack is a set of offer and access templates of a service together
+// with defaults for products of that service.
+type TemplatePack struct {
+	Name    string
+	Offer   json.RawMessage
+	Access  json.RawMessage
+	Product PackProduct
+}
+
+// PackProduct is a product bootstrap description. Config holds default
+// values of endpoint message additional parameters.
+type PackProduct struct {
+	Name         string            `json:"name"`
+	UsageRepType string            `json:"usageRepType"`
+	ClientIdent  string            `json:"clientIdent"`
+	Config       map[string]string `json:"config"`
+}
+
+func readPackFile(pack, name string) ([]byte, error) {
+	raw, err := statik.ReadFile("/templates/" + pack + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read %s of %s template pack: %s", name, pack, err)
+	}
+	return raw, nil
+}
+
+// LoadTemplatePack loads a template pack from the statik filesystem.
+func LoadTemplatePack(name string) (*TemplatePack, error) {
+	known := false
+	for _, v := range TemplatePacks {
+		known = known || v == name
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown template pack: %s", name)
+	}
+
+	pack := &TemplatePack{Name: name}
+
+	var err error
+	if pack.Offer, err = readPackFile(name, "offer.json"); err != nil {
+		return nil, err
+	}
+
+	if pack.Access, err = readPackFile(name, "access.json"); err != nil {
+		return nil, err
+	}
+
+	raw, err := readPackFile(name, "product.json")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &pack.Product); err != nil {
+		return nil, fmt.Errorf(
+			"failed to parse %s template pack product: %s", name, err)
+	}
+
+	return pack, nil
+}
+
+// ProductConfig merges default additional parameters of a pack with given
+// ones and checks the result against the pack access template.
+func (p *TemplatePack) ProductConfig(address string,
+	params map[string]string) (map[string]string, error) {
+	conf := make(map[string]string)
+	for k, v := range p.Product.Config {
+		conf[k] = v
+	}
+	for k, v := range params {
+		conf[k] = v
+	}
+
+	sample := map[string]interface{}{
+		"templateHash":           "",
+		"username":               util.NewUUID(),
+		"password":               "",
+		"paymentReceiverAddress": "http://localhost",
+		"serviceEndpointAddress": address,
+		"additionalParams":       conf,
+	}
+
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewBytesLoader(p.Access),
+		gojsonschema.NewGoLoader(sample))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to validate %s product config: %s", p.Name, err)
+	}
+
+	if !result.Valid() {
+		var errs []string
+		for _, v := range result.Errors() {
+			errs = append(errs, v.String())
+		}
+		return nil, fmt.Errorf("invalid %s product config: %s",
+			p.Name, strings.Join(errs, "; "))
+	}
+
+	return conf, nil
+}
+
+// findOrInsertTemplate returns a template with a given content, inserting
+// it if it doesn't exist yet.
+func findOrInsertTemplate(db *reform.Querier,
+	kind string, raw json.RawMessage) (*Template, error) {
+	hash := HexFromBytes(crypto.Keccak256(raw))
+
+	var tpl Template
+	err := db.SelectOneTo(&tpl, "WHERE hash = $1 AND kind = $2", hash, kind)
+	if err == nil {
+		return &tpl, nil
+	}
+	if err != reform.ErrNoRows {
+		return nil, err
+	}
+
+	tpl = Template{
+		ID:   util.NewUUID(),
+		Hash: hash,
+		Raw:  raw,
+		Kind: kind,
+	}
+
+	if err := Insert(db, &tpl); err != nil {
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+// BootstrapProduct creates pack templates, unless they already exist, and a
+// new agent product which uses them. It returns the product together with
+// its randomly generated password.
+func BootstrapProduct(db *reform.DB, pack *TemplatePack,
+	name, address, country string,
+	params map[string]string) (*Product, string, error) {
+	if !util.IsIPv4(address) && !util.IsHostname(address) {
+		return nil, "", fmt.Errorf(
+			"bad service endpoint address: %s", address)
+	}
+
+	conf, err := pack.ProductConfig(address, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	confRaw, err := json.Marshal(conf)
+	if err != nil {
+		return nil, "", err
+	}
+
+	password, salt, hash, err := newProductPassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name == "" {
+		name = pack.Product.Name
+	}
+
+	prod := &Product{
+		ID:                     util.NewUUID(),
+		Name:                   name,
+		UsageRepType:           pack.Product.UsageRepType,
+		IsServer:               true,
+		Salt:                   salt,
+		Password:               hash,
+		ClientIdent:            pack.Product.ClientIdent,
+		Config:                 confRaw,
+		ServiceEndpointAddress: &address,
+	}
+
+	if country != "" {
+		prod.Country = &country
+	}
+
+	err = db.InTransaction(func(tx *reform.TX) error {
+		offer, err := findOrInsertTemplate(
+			tx.Querier, TemplateOffer, pack.Offer)
+		if err != nil {
+			return err
+		}
+
+		access, err := findOrInsertTemplate(
+			tx.Querier, TemplateAccess, pack.Access)
+		if err != nil {
+			return err
+		}
+
+		prod.OfferTplID = &offer.ID
+		prod.OfferAccessID = &access.ID
+
+		return Insert(tx.Querier, prod)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return prod, password, nil
+}
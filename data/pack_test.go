@@ -0,0 +1,55 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestBootstrapProduct(t *testing.T) {
+	params := map[string]map[string]string{
+		PackWireGuard: {
+			"serverPublicKey": "jNVzWKGFbGNjZAkvPSmb8BXZFMXVWHykbs+Ko6A4lnY=",
+		},
+		PackProxy:       {"protocol": "http"},
+		PackShadowsocks: {},
+	}
+
+	for _, name := range TemplatePacks {
+		pack, err := LoadTemplatePack(name)
+		util.TestExpectResult(t, "LoadTemplatePack", nil, err)
+
+		_, _, err = BootstrapProduct(db, pack, "", "localhost", "US",
+			map[string]string{"port": "bad"})
+		if err == nil {
+			t.Fatalf("%s product with bad config is created", name)
+		}
+
+		var prods []*Product
+		var passwords []string
+		for i := 0; i < 2; i++ {
+			prod, pwd, err := BootstrapProduct(db, pack, "",
+				"localhost", "US", params[name])
+			util.TestExpectResult(t, "BootstrapProduct", nil, err)
+			prods = append(prods, prod)
+			passwords = append(passwords, pwd)
+		}
+
+		ReloadFromTestDB(t, db, prods[0])
+		if prods[0].Name != pack.Product.Name ||
+			*prods[0].OfferTplID != *prods[1].OfferTplID ||
+			*prods[0].OfferAccessID != *prods[1].OfferAccessID {
+			t.Fatalf("unexpected %s products: %+v, %+v",
+				name, *prods[0], *prods[1])
+		}
+
+		err = ValidatePassword(prods[0].Password,
+			passwords[0], string(prods[0].Salt))
+		util.TestExpectResult(t, "ValidatePassword", nil, err)
+
+		var offer, access Template
+		FindInTestDB(t, db, &offer, "id", *prods[0].OfferTplID)
+		FindInTestDB(t, db, &access, "id", *prods[0].OfferAccessID)
+		DeleteFromTestDB(t, db, prods[1], prods[0], &access, &offer)
+	}
+}
@@ -177,3 +177,307 @@ VALUES('rating.ranking.steps',
         'Ranking steps number')
 ON CONFLICT (key)
 DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.failover',
+        'false',
+        2,
+        'Enable or disable automatic failover to an alternative offering,' ||
+        ' when a channel fails. Only for client.',
+        'Client failover')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.failover.maxfailures',
+        '3',
+        2,
+        'Number of consecutive endpoint or connection failures of a channel' ||
+        ' after which failover is triggered.',
+        'Failover max failures')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.failover.maxprice',
+        '0',
+        2,
+        'Max unit price of an alternative offering. If 0, unit price' ||
+        ' of the failed offering is used.',
+        'Failover max unit price')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.failover.uncooperative',
+        'false',
+        2,
+        'Close a failed channel uncooperatively instead of waiting for' ||
+        ' the agent to close it cooperatively.',
+        'Failover uncooperative close')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.budget.warnthresholds',
+        '80,95',
+        2,
+        'Comma separated percentages of spending budgets. Reaching' ||
+        ' each of them triggers a budget warning.',
+        'Budget warning thresholds')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('usage.samples.rawperiod',
+        '24',
+        2,
+        'Usage samples older than this number of hours are downsampled' ||
+        ' to hourly ones. If 0, samples are not downsampled.',
+        'Usage samples raw period')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('usage.samples.retention',
+        '90',
+        2,
+        'Usage samples older than this number of days are deleted.' ||
+        ' If 0, samples are kept forever.',
+        'Usage samples retention')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.verify.ratingpenalty',
+        '50',
+        2,
+        'Percentage an agent rating is reduced by for each endpoint' ||
+        ' with a country or an ip type not matching an offering.',
+        'Verification rating penalty')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('client.verify.autoclose',
+        'false',
+        2,
+        'Terminate and close channels with endpoints, which country or' ||
+        ' ip type does not match an offering.',
+        'Verification auto close')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.jobs.maxage',
+        '90',
+        2,
+        'Finished jobs older than this number of days are deleted.' ||
+        ' If 0, they are not deleted by age.',
+        'Jobs retention age')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.jobs.maxcount',
+        '0',
+        2,
+        'Only this number of newest finished jobs are kept.' ||
+        ' If 0, they are not deleted by count.',
+        'Jobs retention count')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.log_events.maxage',
+        '30',
+        2,
+        'Log events older than this number of days are deleted.' ||
+        ' If 0, they are not deleted by age.',
+        'Log events retention age')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.log_events.maxcount',
+        '0',
+        2,
+        'Only this number of newest log events are kept.' ||
+        ' If 0, they are not deleted by count.',
+        'Log events retention count')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.eth_txs.maxage',
+        '0',
+        2,
+        'Mined transactions older than this number of days are deleted.' ||
+        ' If 0, they are not deleted by age.',
+        'Transactions retention age')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.eth_txs.maxcount',
+        '0',
+        2,
+        'Only this number of newest mined transactions are kept.' ||
+        ' If 0, they are not deleted by count.',
+        'Transactions retention count')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.sessions.maxage',
+        '0',
+        2,
+        'Sessions older than this number of days are deleted.' ||
+        ' If 0, they are not deleted by age.',
+        'Sessions retention age')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.sessions.maxcount',
+        '0',
+        2,
+        'Only this number of newest sessions are kept.' ||
+        ' If 0, they are not deleted by count.',
+        'Sessions retention count')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.closings.maxage',
+        '0',
+        2,
+        'Channel closings older than this number of days are deleted.' ||
+        ' Closings of agent channels are kept for income reports.' ||
+        ' If 0, they are not deleted by age.',
+        'Closings retention age')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES('retention.closings.maxcount',
+        '0',
+        2,
+        'Only this number of newest channel closings are kept.' ||
+        ' Closings of agent channels are kept for income reports.' ||
+        ' If 0, they are not deleted by count.',
+        'Closings retention count')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('messages.envelope',
+        'false',
+        2,
+        'Publish offering and endpoint messages in versioned envelopes.' ||
+        ' Enable it when peers support enveloped messages.',
+        'Message envelopes')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('dispute.gas.maxincrease',
+        '200',
+        2,
+        'Gas price of a disputing cooperative close is increased up to' ||
+        ' this percent as the end of a challenge period approaches.',
+        'Dispute gas price increase')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('settlement.onterminate',
+        'true',
+        2,
+        'Submit a cooperative close as soon as a channel service is' ||
+        ' terminated. If false, channels are closed by settlement policies.',
+        'Settle on termination')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('settlement.idle.days',
+        '0',
+        2,
+        'Terminated channels are closed cooperatively after this number' ||
+        ' of days. If 0, they are not closed by idle time.',
+        'Settlement idle days')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('settlement.balance.threshold',
+        '0',
+        2,
+        'Terminated channels are closed cooperatively once their receipt' ||
+        ' balance reaches this amount. If 0, they are not closed by' ||
+        ' balance.',
+        'Settlement balance threshold')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('settlement.gasprice.max',
+        '0',
+        2,
+        'Cooperative closes by settlement policies are batched until' ||
+        ' suggested gas price in WEI is not greater than this value.' ||
+        ' If 0, gas price is not limited.',
+        'Settlement max gas price')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('client.vanish.autoclose',
+        'false',
+        2,
+        'Request uncooperative close of a channel, when its agent is' ||
+        ' unresponsive. Only for client.',
+        'Close on vanished agent')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('client.vanish.window',
+        '60',
+        2,
+        'Number of minutes during which cheques, connections and SOMC' ||
+        ' pings must keep failing to consider an agent vanished.',
+        'Vanished agent window')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('client.vanish.minfailures',
+        '5',
+        2,
+        'Minimal number of failures within the window to consider an' ||
+        ' agent vanished.',
+        'Vanished agent min failures')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('client.usage.tolerance',
+        '10',
+        2,
+        'Percent of units, which an agent may charge over units measured' ||
+        ' by client, before the discrepancy is reported. Only for client.',
+        'Usage discrepancy tolerance')
+ON CONFLICT (key)
+DO NOTHING;
+
+INSERT INTO settings (key, value, permissions, description, name)
+VALUES ('client.usage.blockpayments',
+        'false',
+        2,
+        'Stop paying for a channel, which agent charged more units than' ||
+        ' measured by client, until a new session is started. Only for client.',
+        'Block payments on usage discrepancy')
+ON CONFLICT (key)
+DO NOTHING;
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/sethvargo/go-password/password"
+	"gopkg.in/reform.v1"
+)
+
+// AdapterSessConfig is a service adapter configuration for connecting to
+// the session server.
+type AdapterSessConfig struct {
+	Endpoint string
+	Origin   string
+	Product  string
+	Password string
+}
+
+// AdapterConfig is a configuration file of a service adapter generated
+// for a product.
+type AdapterConfig struct {
+	Pack    string
+	Sess    AdapterSessConfig
+	Service map[string]string
+}
+
+// newProductPassword generates a random product password and hashes it with
+// a random salt the same way as for channels.
+func newProductPassword() (pwd string, salt uint64,
+	hash Base64String, err error) {
+	pwd, err = password.Generate(24, 6, 0, false, true)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(9*1e18))
+	if err != nil {
+		return "", 0, "", err
+	}
+	salt = n.Uint64()
+
+	hash, err = HashPassword(pwd, string(salt))
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return pwd, salt, hash, nil
+}
+
+// FindServerProducts returns all the agent products.
+func FindServerProducts(db *reform.Querier) ([]*Product, error) {
+	rows, err := db.SelectAllFrom(ProductTable,
+		"WHERE is_server ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+
+	prods := make([]*Product, len(rows))
+	for i, v := range rows {
+		prods[i] = v.(*Product)
+	}
+
+	return prods, nil
+}
+
+// RotateProductPassword sets a new random password for a product and
+// returns it.
+func RotateProductPassword(db *reform.Querier, id string) (string, error) {
+	var prod Product
+	if err := FindByPrimaryKeyTo(db, &prod, id); err != nil {
+		return "", err
+	}
+
+	pwd, salt, hash, err := newProductPassword()
+	if err != nil {
+		return "", err
+	}
+
+	prod.Salt = salt
+	prod.Password = hash
+
+	if err := Save(db, &prod); err != nil {
+		return "", err
+	}
+
+	return pwd, nil
+}
+
+// DeleteProduct deletes a product unless it has offerings.
+func DeleteProduct(db *reform.Querier, id string) error {
+	var prod Product
+	if err := FindByPrimaryKeyTo(db, &prod, id); err != nil {
+		return err
+	}
+
+	var offerings int
+	if err := db.QueryRow(`SELECT count(*) FROM offerings
+				WHERE product = $1`, id).Scan(&offerings); err != nil {
+		return err
+	}
+
+	if offerings != 0 {
+		return fmt.Errorf("product %s has %d offerings", id, offerings)
+	}
+
+	return db.Delete(&prod)
+}
+
+// NewAdapterConfig returns a service adapter configuration for a product.
+func NewAdapterConfig(pack string, prod *Product,
+	password, sessEndpoint string) (*AdapterConfig, error) {
+	var service map[string]string
+	if err := json.Unmarshal(prod.Config, &service); err != nil {
+		return nil, fmt.Errorf("failed to parse product config: %s", err)
+	}
+
+	return &AdapterConfig{
+		Pack: pack,
+		Sess: AdapterSessConfig{
+			Endpoint: sessEndpoint,
+			Origin:   "http://localhost",
+			Product:  prod.ID,
+			Password: password,
+		},
+		Service: service,
+	}, nil
+}
+
+// ReadAdapterConfig reads a service adapter configuration file.
+func ReadAdapterConfig(name string) (*AdapterConfig, error) {
+	raw, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf AdapterConfig
+	if err := json.Unmarshal(raw, &conf); err != nil {
+		return nil, err
+	}
+
+	return &conf, nil
+}
+
+// WriteAdapterConfig writes a service adapter configuration file. The file
+// is readable only by its owner as it contains a product password.
+func WriteAdapterConfig(name string, conf *AdapterConfig) error {
+	raw, err := json.MarshalIndent(conf, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(name, append(raw, '\n'), 0600)
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestRotateProductPassword(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	pwd, err := RotateProductPassword(db.Querier, fxt.Product.ID)
+	util.TestExpectResult(t, "RotateProductPassword", nil, err)
+
+	ReloadFromTestDB(t, db, fxt.Product)
+
+	err = ValidatePassword(fxt.Product.Password,
+		pwd, string(fxt.Product.Salt))
+	util.TestExpectResult(t, "ValidatePassword", nil, err)
+}
+
+func TestDeleteProduct(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	if err := DeleteProduct(db.Querier, fxt.Product.ID); err == nil {
+		t.Fatal("product with offerings is deleted")
+	}
+
+	prod := NewTestProduct()
+	InsertToTestDB(t, db, prod)
+
+	err := DeleteProduct(db.Querier, prod.ID)
+	util.TestExpectResult(t, "DeleteProduct", nil, err)
+
+	if err := db.Reload(prod); err == nil {
+		t.Fatal("product is not deleted")
+	}
+}
+
+func TestAdapterConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dappctrl")
+	util.TestExpectResult(t, "TempDir", nil, err)
+	defer os.RemoveAll(dir)
+
+	prod := NewTestProduct()
+	prod.Config = []byte(`{"port": "1080"}`)
+
+	conf, err := NewAdapterConfig(PackProxy, prod, "secret", "ws://sess")
+	util.TestExpectResult(t, "NewAdapterConfig", nil, err)
+
+	name := filepath.Join(dir, "adapter.config.json")
+	err = WriteAdapterConfig(name, conf)
+	util.TestExpectResult(t, "WriteAdapterConfig", nil, err)
+
+	conf2, err := ReadAdapterConfig(name)
+	util.TestExpectResult(t, "ReadAdapterConfig", nil, err)
+
+	if conf2.Sess != conf.Sess || conf2.Service["port"] != "1080" {
+		t.Fatalf("wrong adapter config: %+v", *conf2)
+	}
+}
@@ -0,0 +1,214 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/reform.v1"
+)
+
+// Tables with retention policies.
+const (
+	RetentionJobs      = "jobs"
+	RetentionLogEvents = "log_events"
+	RetentionEthTxs    = "eth_txs"
+	RetentionSessions  = "sessions"
+	RetentionClosings  = "closings"
+)
+
+// RetentionTables is a list of tables with retention policies in order of
+// pruning. Transactions are pruned before jobs they reference.
+var RetentionTables = []string{RetentionEthTxs, RetentionJobs,
+	RetentionSessions, RetentionClosings, RetentionLogEvents}
+
+// RetentionPolicy is a retention policy of a table. Rows older than MaxAge
+// days and rows beyond MaxCount newest ones are pruned. Zero values disable
+// corresponding limits.
+type RetentionPolicy struct {
+	MaxAge   uint64
+	MaxCount uint64
+}
+
+// pruneBatch is a maximum number of rows deleted at once.
+const pruneBatch = 10000
+
+// closingBlockTime is an estimated block time used to find old closings,
+// which have block numbers only.
+const closingBlockTime = 15
+
+// activeChannels selects channels which are not closed yet.
+const activeChannels = `SELECT id FROM channels
+	WHERE channel_status NOT IN ('closed_coop', 'closed_uncoop')`
+
+type retentionTable struct {
+	// maxAge and maxCount are policy setting keys.
+	maxAge   string
+	maxCount string
+	// order is an expression ordering rows by their age.
+	order string
+	// old is an expression which is true for rows created before $1.
+	old string
+	// prunable is a condition of rows which can be deleted.
+	prunable string
+}
+
+var retentionTables = map[string]retentionTable{
+	RetentionJobs: {
+		maxAge:   SettingRetentionJobsMaxAge,
+		maxCount: SettingRetentionJobsMaxCount,
+		order:    "t.created_at",
+		old:      "t.created_at < $1::timestamptz",
+		// Jobs used for spending budgets and income reports are kept.
+		prunable: `t.status <> 'active'
+			AND t.type NOT IN ('` + JobClientPreChannelCreate + `',
+				'` + JobClientPreChannelTopUp + `',
+				'` + JobAgentAfterCooperativeClose + `',
+				'` + JobAgentAfterUncooperativeClose + `')
+			AND NOT EXISTS (SELECT 1 FROM eth_txs
+					 WHERE eth_txs.job = t.id)
+			AND NOT (t.related_type = 'channel' AND
+				 t.related_id IN (` + activeChannels + `))
+			AND NOT (t.related_type = 'endpoint' AND
+				 t.related_id IN (SELECT id FROM endpoints
+				  WHERE channel IN (` + activeChannels + `)))`,
+	},
+	RetentionLogEvents: {
+		maxAge:   SettingRetentionLogEventsMaxAge,
+		maxCount: SettingRetentionLogEventsMaxCount,
+		order:    "t.time",
+		old:      "t.time < $1::timestamptz",
+		prunable: "TRUE",
+	},
+	RetentionEthTxs: {
+		maxAge:   SettingRetentionEthTxsMaxAge,
+		maxCount: SettingRetentionEthTxsMaxCount,
+		order:    "t.issued",
+		old:      "t.issued < $1::timestamptz",
+		prunable: `t.status = '` + TxMined + `'
+			AND NOT (t.related_type = 'channel' AND
+				 t.related_id IN (` + activeChannels + `))`,
+	},
+	RetentionSessions: {
+		maxAge:   SettingRetentionSessionsMaxAge,
+		maxCount: SettingRetentionSessionsMaxCount,
+		order:    "t.started",
+		old:      "t.started < $1::timestamptz",
+		prunable: `t.channel NOT IN (` + activeChannels + `)
+			AND NOT EXISTS (SELECT 1 FROM usage_samples
+					 WHERE usage_samples.session = t.id)`,
+	},
+	RetentionClosings: {
+		maxAge:   SettingRetentionClosingsMaxAge,
+		maxCount: SettingRetentionClosingsMaxCount,
+		order:    "t.block",
+		old: fmt.Sprintf(`t.block < (SELECT max(block) FROM closings) -
+			extract(epoch FROM now() - $1::timestamptz)::bigint / %d`,
+			closingBlockTime),
+		// Closings of agent channels settle their income, so they are
+		// kept for income reports.
+		prunable: `NOT EXISTS (SELECT 1 FROM channels
+				WHERE channels.agent = t.agent
+				      AND channels.client = t.client
+				      AND channels.block = t.block
+				      AND channels.agent IN (
+					  SELECT eth_addr FROM accounts))`,
+	},
+}
+
+// ReadRetentionPolicy reads a retention policy of a table from settings.
+func ReadRetentionPolicy(db *reform.Querier,
+	table string) (*RetentionPolicy, error) {
+	tbl, ok := retentionTables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention table: %s", table)
+	}
+
+	maxAge, err := ReadUint64Setting(db, tbl.maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCount, err := ReadUint64Setting(db, tbl.maxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RetentionPolicy{MaxAge: maxAge, MaxCount: maxCount}, nil
+}
+
+// PruneTable deletes rows of a table according to a retention policy. Rows
+// referenced by active channels are never deleted. Deleted rows are passed
+// to an archive function, if it's not nil, within the deleting transaction,
+// so rows are deleted only if they are successfully archived. It returns
+// a number of deleted rows.
+func PruneTable(db *reform.DB, table string, policy *RetentionPolicy,
+	now time.Time, archive func(rows []json.RawMessage) error) (int, error) {
+	tbl, ok := retentionTables[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention table: %s", table)
+	}
+
+	if policy.MaxAge == 0 && policy.MaxCount == 0 {
+		return 0, nil
+	}
+
+	var before *time.Time
+	if policy.MaxAge != 0 {
+		v := now.AddDate(0, 0, -int(policy.MaxAge))
+		before = &v
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s t
+		 WHERE t.ctid IN (
+			SELECT p.ctid FROM (
+				SELECT t.ctid, (%[2]s) AS old,
+				       row_number() OVER (
+					       ORDER BY %[3]s DESC) AS n
+				  FROM %[1]s t
+				 WHERE %[4]s) p
+			 WHERE p.old OR ($2::bigint > 0 AND p.n > $2::bigint)
+			 LIMIT %[5]d)
+		RETURNING row_to_json(t)`,
+		table, tbl.old, tbl.order, tbl.prunable, pruneBatch)
+
+	var total int
+	for {
+		var n int
+		err := db.InTransaction(func(tx *reform.TX) error {
+			rows, err := tx.Query(query, before, policy.MaxCount)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			var deleted []json.RawMessage
+			for rows.Next() {
+				var row []byte
+				if err := rows.Scan(&row); err != nil {
+					return err
+				}
+				deleted = append(deleted, row)
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			n = len(deleted)
+			if n == 0 || archive == nil {
+				return nil
+			}
+
+			return archive(deleted)
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+		if n < pruneBatch {
+			return total, nil
+		}
+	}
+}
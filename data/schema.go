@@ -76,6 +76,8 @@ type Product struct {
 	Config                 json.RawMessage `json:"config" reform:"config"`
 	ServiceEndpointAddress *string         `json:"serviceEndpointAddress" reform:"service_endpoint_address"`
 	Country                *string         `json:"country" reform:"country"`
+	TrialUnits             *uint64         `json:"trialUnits" reform:"trial_units"` // Max free units per client, nil is no limit.
+	TrialByIP              bool            `json:"trialByIP" reform:"trial_by_ip"`  // Limit free units per client IP too.
 }
 
 // Unit used for billing calculation.
@@ -148,6 +150,7 @@ type Offering struct {
 	SOMCType           uint8           `json:"somcType" reform:"somc_type"`
 	SOMCData           Base64String    `json:"somcData" reform:"somc_data"`
 	SOMCSuccessPing    *time.Time      `json:"somcSuccessPing" reform:"somc_success_ping"`
+	ExpiresAt          *time.Time      `json:"expiresAt" reform:"expires_at"` // Offering message is stale after.
 }
 
 // State channel statuses.
@@ -191,6 +194,8 @@ type Channel struct {
 	Password           Base64String  `json:"-" reform:"password"`
 	ReceiptBalance     uint64        `json:"receiptBalance" reform:"receipt_balance"` // Last payment.
 	ReceiptSignature   *Base64String `json:"-" reform:"receipt_signature"`            // Last payment's signature.
+	Key                *Base64String `json:"-" reform:"channel_key"`                  // Key to find channel by, see ChannelKey.
+	FreeUnits          uint64        `json:"freeUnits" reform:"free_units"`           // Free units granted by agent.
 }
 
 // Session is a client session.
@@ -261,6 +266,7 @@ type Endpoint struct {
 	Password               *string      `json:"password" reform:"password"`
 	AdditionalParams       []byte       `json:"additionalParams" reform:"additional_params"`
 	CountryStatus          *string      `json:"countryStatus" reform:"country_status"`
+	IPTypeStatus           *string      `json:"ipTypeStatus" reform:"ip_type_status"`
 }
 
 // EndpointUI contains only certain fields of endpoints table.
@@ -270,6 +276,7 @@ type EndpointUI struct {
 	PaymentReceiverAddress *string `json:"paymentReceiverAddress" reform:"payment_receiver_address"`
 	ServiceEndpointAddress *string `json:"serviceEndpointAddress" reform:"service_endpoint_address"`
 	CountryStatus          *string `json:"countryStatus" reform:"country_status"`
+	IPTypeStatus           *string `json:"ipTypeStatus" reform:"ip_type_status"`
 }
 
 // Transaction statuses.
@@ -347,3 +354,49 @@ type Rating struct {
 	EthAddr HexString `reform:"eth_addr,pk" json:"eth_addr"`
 	Val     uint64    `reform:"val" json:"val"`
 }
+
+// Deposit top up modes.
+const (
+	TopUpAuto = "auto"
+	TopUpAsk  = "ask"
+)
+
+// TopUpPolicy is a deposit top up policy of a client channel.
+//reform:topup_policies
+type TopUpPolicy struct {
+	Channel          string `reform:"channel,pk" json:"channel"`
+	Mode             string `reform:"mode" json:"mode"`
+	Amount           uint64 `reform:"amount" json:"amount"`
+	MinUnitsMultiple uint64 `reform:"min_units_multiple" json:"minUnitsMultiple"`
+	MaxDeposit       uint64 `reform:"max_deposit" json:"maxDeposit"`
+	DailyCap         uint64 `reform:"daily_cap" json:"dailyCap"`
+}
+
+// Spending budget periods.
+const (
+	BudgetDaily    = "daily"
+	BudgetMonthly  = "monthly"
+	BudgetLifetime = "lifetime"
+)
+
+// SpendingBudget is a limit of PRIX spent by a client account for a period.
+// A budget without an account is a global one.
+//reform:spending_budgets
+type SpendingBudget struct {
+	ID      string  `reform:"id,pk" json:"id"`
+	Account *string `reform:"account" json:"account"`
+	Period  string  `reform:"period" json:"period"`
+	Amount  uint64  `reform:"amount" json:"amount"`
+}
+
+// UsageSample is an amount of units used by a client session since
+// a previous sample. Old samples are downsampled to hourly ones.
+//reform:usage_samples
+type UsageSample struct {
+	ID          int64     `reform:"id,pk" json:"id"`
+	Session     string    `reform:"session" json:"session"`
+	Channel     string    `reform:"channel" json:"channel"`
+	Time        time.Time `reform:"time" json:"time"`
+	Units       uint64    `reform:"units" json:"units"`
+	Downsampled bool      `reform:"downsampled" json:"downsampled"`
+}
@@ -399,7 +399,7 @@ func (v *productTableType) Name() string {
 
 // Columns returns a new slice of column names for that view or table in SQL database.
 func (v *productTableType) Columns() []string {
-	return []string{"id", "name", "offer_tpl_id", "offer_access_id", "usage_rep_type", "is_server", "salt", "password", "client_ident", "config", "service_endpoint_address", "country"}
+	return []string{"id", "name", "offer_tpl_id", "offer_access_id", "usage_rep_type", "is_server", "salt", "password", "client_ident", "config", "service_endpoint_address", "country", "trial_units", "trial_by_ip"}
 }
 
 // NewStruct makes a new struct for that view or table.
@@ -419,13 +419,13 @@ func (v *productTableType) PKColumnIndex() uint {
 
 // ProductTable represents products view or table in SQL database.
 var ProductTable = &productTableType{
-	s: parse.StructInfo{Type: "Product", SQLSchema: "", SQLName: "products", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Name", Type: "string", Column: "name"}, {Name: "OfferTplID", Type: "*string", Column: "offer_tpl_id"}, {Name: "OfferAccessID", Type: "*string", Column: "offer_access_id"}, {Name: "UsageRepType", Type: "string", Column: "usage_rep_type"}, {Name: "IsServer", Type: "bool", Column: "is_server"}, {Name: "Salt", Type: "uint64", Column: "salt"}, {Name: "Password", Type: "Base64String", Column: "password"}, {Name: "ClientIdent", Type: "string", Column: "client_ident"}, {Name: "Config", Type: "json.RawMessage", Column: "config"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "Country", Type: "*string", Column: "country"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Product", SQLSchema: "", SQLName: "products", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Name", Type: "string", Column: "name"}, {Name: "OfferTplID", Type: "*string", Column: "offer_tpl_id"}, {Name: "OfferAccessID", Type: "*string", Column: "offer_access_id"}, {Name: "UsageRepType", Type: "string", Column: "usage_rep_type"}, {Name: "IsServer", Type: "bool", Column: "is_server"}, {Name: "Salt", Type: "uint64", Column: "salt"}, {Name: "Password", Type: "Base64String", Column: "password"}, {Name: "ClientIdent", Type: "string", Column: "client_ident"}, {Name: "Config", Type: "json.RawMessage", Column: "config"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "Country", Type: "*string", Column: "country"}, {Name: "TrialUnits", Type: "*uint64", Column: "trial_units"}, {Name: "TrialByIP", Type: "bool", Column: "trial_by_ip"}}, PKFieldIndex: 0},
 	z: new(Product).Values(),
 }
 
 // String returns a string representation of this struct or record.
 func (s Product) String() string {
-	res := make([]string, 12)
+	res := make([]string, 14)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "Name: " + reform.Inspect(s.Name, true)
 	res[2] = "OfferTplID: " + reform.Inspect(s.OfferTplID, true)
@@ -438,6 +438,8 @@ func (s Product) String() string {
 	res[9] = "Config: " + reform.Inspect(s.Config, true)
 	res[10] = "ServiceEndpointAddress: " + reform.Inspect(s.ServiceEndpointAddress, true)
 	res[11] = "Country: " + reform.Inspect(s.Country, true)
+	res[12] = "TrialUnits: " + reform.Inspect(s.TrialUnits, true)
+	res[13] = "TrialByIP: " + reform.Inspect(s.TrialByIP, true)
 	return strings.Join(res, ", ")
 }
 
@@ -457,6 +459,8 @@ func (s *Product) Values() []interface{} {
 		s.Config,
 		s.ServiceEndpointAddress,
 		s.Country,
+		s.TrialUnits,
+		s.TrialByIP,
 	}
 }
 
@@ -476,6 +480,8 @@ func (s *Product) Pointers() []interface{} {
 		&s.Config,
 		&s.ServiceEndpointAddress,
 		&s.Country,
+		&s.TrialUnits,
+		&s.TrialByIP,
 	}
 }
 
@@ -541,7 +547,7 @@ func (v *offeringTableType) Name() string {
 
 // Columns returns a new slice of column names for that view or table in SQL database.
 func (v *offeringTableType) Columns() []string {
-	return []string{"id", "is_local", "ip_type", "tpl", "product", "hash", "status", "block_number_updated", "agent", "raw_msg", "service_name", "description", "country", "supply", "current_supply", "unit_name", "unit_type", "billing_type", "setup_price", "unit_price", "min_units", "max_unit", "billing_interval", "max_billing_unit_lag", "max_suspended_time", "max_inactive_time_sec", "free_units", "additional_params", "auto_pop_up", "somc_type", "somc_data", "somc_success_ping"}
+	return []string{"id", "is_local", "ip_type", "tpl", "product", "hash", "status", "block_number_updated", "agent", "raw_msg", "service_name", "description", "country", "supply", "current_supply", "unit_name", "unit_type", "billing_type", "setup_price", "unit_price", "min_units", "max_unit", "billing_interval", "max_billing_unit_lag", "max_suspended_time", "max_inactive_time_sec", "free_units", "additional_params", "auto_pop_up", "somc_type", "somc_data", "somc_success_ping", "expires_at"}
 }
 
 // NewStruct makes a new struct for that view or table.
@@ -561,13 +567,13 @@ func (v *offeringTableType) PKColumnIndex() uint {
 
 // OfferingTable represents offerings view or table in SQL database.
 var OfferingTable = &offeringTableType{
-	s: parse.StructInfo{Type: "Offering", SQLSchema: "", SQLName: "offerings", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "IsLocal", Type: "bool", Column: "is_local"}, {Name: "IPType", Type: "string", Column: "ip_type"}, {Name: "Template", Type: "string", Column: "tpl"}, {Name: "Product", Type: "string", Column: "product"}, {Name: "Hash", Type: "HexString", Column: "hash"}, {Name: "Status", Type: "string", Column: "status"}, {Name: "BlockNumberUpdated", Type: "uint64", Column: "block_number_updated"}, {Name: "Agent", Type: "HexString", Column: "agent"}, {Name: "RawMsg", Type: "Base64String", Column: "raw_msg"}, {Name: "ServiceName", Type: "string", Column: "service_name"}, {Name: "Description", Type: "*string", Column: "description"}, {Name: "Country", Type: "string", Column: "country"}, {Name: "Supply", Type: "uint16", Column: "supply"}, {Name: "CurrentSupply", Type: "uint16", Column: "current_supply"}, {Name: "UnitName", Type: "string", Column: "unit_name"}, {Name: "UnitType", Type: "string", Column: "unit_type"}, {Name: "BillingType", Type: "string", Column: "billing_type"}, {Name: "SetupPrice", Type: "uint64", Column: "setup_price"}, {Name: "UnitPrice", Type: "uint64", Column: "unit_price"}, {Name: "MinUnits", Type: "uint64", Column: "min_units"}, {Name: "MaxUnit", Type: "*uint64", Column: "max_unit"}, {Name: "BillingInterval", Type: "uint", Column: "billing_interval"}, {Name: "MaxBillingUnitLag", Type: "uint", Column: "max_billing_unit_lag"}, {Name: "MaxSuspendTime", Type: "uint", Column: "max_suspended_time"}, {Name: "MaxInactiveTimeSec", Type: "uint64", Column: "max_inactive_time_sec"}, {Name: "FreeUnits", Type: "uint8", Column: "free_units"}, {Name: "AdditionalParams", Type: "json.RawMessage", Column: "additional_params"}, {Name: "AutoPopUp", Type: "*bool", Column: "auto_pop_up"}, {Name: "SOMCType", Type: "uint8", Column: "somc_type"}, {Name: "SOMCData", Type: "Base64String", Column: "somc_data"}, {Name: "SOMCSuccessPing", Type: "*time.Time", Column: "somc_success_ping"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Offering", SQLSchema: "", SQLName: "offerings", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "IsLocal", Type: "bool", Column: "is_local"}, {Name: "IPType", Type: "string", Column: "ip_type"}, {Name: "Template", Type: "string", Column: "tpl"}, {Name: "Product", Type: "string", Column: "product"}, {Name: "Hash", Type: "HexString", Column: "hash"}, {Name: "Status", Type: "string", Column: "status"}, {Name: "BlockNumberUpdated", Type: "uint64", Column: "block_number_updated"}, {Name: "Agent", Type: "HexString", Column: "agent"}, {Name: "RawMsg", Type: "Base64String", Column: "raw_msg"}, {Name: "ServiceName", Type: "string", Column: "service_name"}, {Name: "Description", Type: "*string", Column: "description"}, {Name: "Country", Type: "string", Column: "country"}, {Name: "Supply", Type: "uint16", Column: "supply"}, {Name: "CurrentSupply", Type: "uint16", Column: "current_supply"}, {Name: "UnitName", Type: "string", Column: "unit_name"}, {Name: "UnitType", Type: "string", Column: "unit_type"}, {Name: "BillingType", Type: "string", Column: "billing_type"}, {Name: "SetupPrice", Type: "uint64", Column: "setup_price"}, {Name: "UnitPrice", Type: "uint64", Column: "unit_price"}, {Name: "MinUnits", Type: "uint64", Column: "min_units"}, {Name: "MaxUnit", Type: "*uint64", Column: "max_unit"}, {Name: "BillingInterval", Type: "uint", Column: "billing_interval"}, {Name: "MaxBillingUnitLag", Type: "uint", Column: "max_billing_unit_lag"}, {Name: "MaxSuspendTime", Type: "uint", Column: "max_suspended_time"}, {Name: "MaxInactiveTimeSec", Type: "uint64", Column: "max_inactive_time_sec"}, {Name: "FreeUnits", Type: "uint8", Column: "free_units"}, {Name: "AdditionalParams", Type: "json.RawMessage", Column: "additional_params"}, {Name: "AutoPopUp", Type: "*bool", Column: "auto_pop_up"}, {Name: "SOMCType", Type: "uint8", Column: "somc_type"}, {Name: "SOMCData", Type: "Base64String", Column: "somc_data"}, {Name: "SOMCSuccessPing", Type: "*time.Time", Column: "somc_success_ping"}, {Name: "ExpiresAt", Type: "*time.Time", Column: "expires_at"}}, PKFieldIndex: 0},
 	z: new(Offering).Values(),
 }
 
 // String returns a string representation of this struct or record.
 func (s Offering) String() string {
-	res := make([]string, 32)
+	res := make([]string, 33)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "IsLocal: " + reform.Inspect(s.IsLocal, true)
 	res[2] = "IPType: " + reform.Inspect(s.IPType, true)
@@ -600,6 +606,7 @@ func (s Offering) String() string {
 	res[29] = "SOMCType: " + reform.Inspect(s.SOMCType, true)
 	res[30] = "SOMCData: " + reform.Inspect(s.SOMCData, true)
 	res[31] = "SOMCSuccessPing: " + reform.Inspect(s.SOMCSuccessPing, true)
+	res[32] = "ExpiresAt: " + reform.Inspect(s.ExpiresAt, true)
 	return strings.Join(res, ", ")
 }
 
@@ -639,6 +646,7 @@ func (s *Offering) Values() []interface{} {
 		s.SOMCType,
 		s.SOMCData,
 		s.SOMCSuccessPing,
+		s.ExpiresAt,
 	}
 }
 
@@ -678,6 +686,7 @@ func (s *Offering) Pointers() []interface{} {
 		&s.SOMCType,
 		&s.SOMCData,
 		&s.SOMCSuccessPing,
+		&s.ExpiresAt,
 	}
 }
 
@@ -743,7 +752,7 @@ func (v *channelTableType) Name() string {
 
 // Columns returns a new slice of column names for that view or table in SQL database.
 func (v *channelTableType) Columns() []string {
-	return []string{"id", "agent", "client", "offering", "block", "channel_status", "service_status", "service_changed_time", "prepared_at", "total_deposit", "salt", "username", "password", "receipt_balance", "receipt_signature"}
+	return []string{"id", "agent", "client", "offering", "block", "channel_status", "service_status", "service_changed_time", "prepared_at", "total_deposit", "salt", "username", "password", "receipt_balance", "receipt_signature", "channel_key", "free_units"}
 }
 
 // NewStruct makes a new struct for that view or table.
@@ -763,13 +772,13 @@ func (v *channelTableType) PKColumnIndex() uint {
 
 // ChannelTable represents channels view or table in SQL database.
 var ChannelTable = &channelTableType{
-	s: parse.StructInfo{Type: "Channel", SQLSchema: "", SQLName: "channels", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Agent", Type: "HexString", Column: "agent"}, {Name: "Client", Type: "HexString", Column: "client"}, {Name: "Offering", Type: "string", Column: "offering"}, {Name: "Block", Type: "uint32", Column: "block"}, {Name: "ChannelStatus", Type: "string", Column: "channel_status"}, {Name: "ServiceStatus", Type: "string", Column: "service_status"}, {Name: "ServiceChangedTime", Type: "*time.Time", Column: "service_changed_time"}, {Name: "PreparedAt", Type: "time.Time", Column: "prepared_at"}, {Name: "TotalDeposit", Type: "uint64", Column: "total_deposit"}, {Name: "Salt", Type: "uint64", Column: "salt"}, {Name: "Username", Type: "*string", Column: "username"}, {Name: "Password", Type: "Base64String", Column: "password"}, {Name: "ReceiptBalance", Type: "uint64", Column: "receipt_balance"}, {Name: "ReceiptSignature", Type: "*Base64String", Column: "receipt_signature"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Channel", SQLSchema: "", SQLName: "channels", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Agent", Type: "HexString", Column: "agent"}, {Name: "Client", Type: "HexString", Column: "client"}, {Name: "Offering", Type: "string", Column: "offering"}, {Name: "Block", Type: "uint32", Column: "block"}, {Name: "ChannelStatus", Type: "string", Column: "channel_status"}, {Name: "ServiceStatus", Type: "string", Column: "service_status"}, {Name: "ServiceChangedTime", Type: "*time.Time", Column: "service_changed_time"}, {Name: "PreparedAt", Type: "time.Time", Column: "prepared_at"}, {Name: "TotalDeposit", Type: "uint64", Column: "total_deposit"}, {Name: "Salt", Type: "uint64", Column: "salt"}, {Name: "Username", Type: "*string", Column: "username"}, {Name: "Password", Type: "Base64String", Column: "password"}, {Name: "ReceiptBalance", Type: "uint64", Column: "receipt_balance"}, {Name: "ReceiptSignature", Type: "*Base64String", Column: "receipt_signature"}, {Name: "Key", Type: "*Base64String", Column: "channel_key"}, {Name: "FreeUnits", Type: "uint64", Column: "free_units"}}, PKFieldIndex: 0},
 	z: new(Channel).Values(),
 }
 
 // String returns a string representation of this struct or record.
 func (s Channel) String() string {
-	res := make([]string, 15)
+	res := make([]string, 17)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "Agent: " + reform.Inspect(s.Agent, true)
 	res[2] = "Client: " + reform.Inspect(s.Client, true)
@@ -785,6 +794,8 @@ func (s Channel) String() string {
 	res[12] = "Password: " + reform.Inspect(s.Password, true)
 	res[13] = "ReceiptBalance: " + reform.Inspect(s.ReceiptBalance, true)
 	res[14] = "ReceiptSignature: " + reform.Inspect(s.ReceiptSignature, true)
+	res[15] = "Key: " + reform.Inspect(s.Key, true)
+	res[16] = "FreeUnits: " + reform.Inspect(s.FreeUnits, true)
 	return strings.Join(res, ", ")
 }
 
@@ -807,6 +818,8 @@ func (s *Channel) Values() []interface{} {
 		s.Password,
 		s.ReceiptBalance,
 		s.ReceiptSignature,
+		s.Key,
+		s.FreeUnits,
 	}
 }
 
@@ -829,6 +842,8 @@ func (s *Channel) Pointers() []interface{} {
 		&s.Password,
 		&s.ReceiptBalance,
 		&s.ReceiptSignature,
+		&s.Key,
+		&s.FreeUnits,
 	}
 }
 
@@ -1269,7 +1284,7 @@ func (v *endpointTableType) Name() string {
 
 // Columns returns a new slice of column names for that view or table in SQL database.
 func (v *endpointTableType) Columns() []string {
-	return []string{"id", "template", "channel", "hash", "raw_msg", "payment_receiver_address", "service_endpoint_address", "username", "password", "additional_params", "country_status"}
+	return []string{"id", "template", "channel", "hash", "raw_msg", "payment_receiver_address", "service_endpoint_address", "username", "password", "additional_params", "country_status", "ip_type_status"}
 }
 
 // NewStruct makes a new struct for that view or table.
@@ -1289,13 +1304,13 @@ func (v *endpointTableType) PKColumnIndex() uint {
 
 // EndpointTable represents endpoints view or table in SQL database.
 var EndpointTable = &endpointTableType{
-	s: parse.StructInfo{Type: "Endpoint", SQLSchema: "", SQLName: "endpoints", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Template", Type: "string", Column: "template"}, {Name: "Channel", Type: "string", Column: "channel"}, {Name: "Hash", Type: "HexString", Column: "hash"}, {Name: "RawMsg", Type: "Base64String", Column: "raw_msg"}, {Name: "PaymentReceiverAddress", Type: "*string", Column: "payment_receiver_address"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "Username", Type: "*string", Column: "username"}, {Name: "Password", Type: "*string", Column: "password"}, {Name: "AdditionalParams", Type: "[]uint8", Column: "additional_params"}, {Name: "CountryStatus", Type: "*string", Column: "country_status"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Endpoint", SQLSchema: "", SQLName: "endpoints", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Template", Type: "string", Column: "template"}, {Name: "Channel", Type: "string", Column: "channel"}, {Name: "Hash", Type: "HexString", Column: "hash"}, {Name: "RawMsg", Type: "Base64String", Column: "raw_msg"}, {Name: "PaymentReceiverAddress", Type: "*string", Column: "payment_receiver_address"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "Username", Type: "*string", Column: "username"}, {Name: "Password", Type: "*string", Column: "password"}, {Name: "AdditionalParams", Type: "[]uint8", Column: "additional_params"}, {Name: "CountryStatus", Type: "*string", Column: "country_status"}, {Name: "IPTypeStatus", Type: "*string", Column: "ip_type_status"}}, PKFieldIndex: 0},
 	z: new(Endpoint).Values(),
 }
 
 // String returns a string representation of this struct or record.
 func (s Endpoint) String() string {
-	res := make([]string, 11)
+	res := make([]string, 12)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "Template: " + reform.Inspect(s.Template, true)
 	res[2] = "Channel: " + reform.Inspect(s.Channel, true)
@@ -1307,6 +1322,7 @@ func (s Endpoint) String() string {
 	res[8] = "Password: " + reform.Inspect(s.Password, true)
 	res[9] = "AdditionalParams: " + reform.Inspect(s.AdditionalParams, true)
 	res[10] = "CountryStatus: " + reform.Inspect(s.CountryStatus, true)
+	res[11] = "IPTypeStatus: " + reform.Inspect(s.IPTypeStatus, true)
 	return strings.Join(res, ", ")
 }
 
@@ -1325,6 +1341,7 @@ func (s *Endpoint) Values() []interface{} {
 		s.Password,
 		s.AdditionalParams,
 		s.CountryStatus,
+		s.IPTypeStatus,
 	}
 }
 
@@ -1343,6 +1360,7 @@ func (s *Endpoint) Pointers() []interface{} {
 		&s.Password,
 		&s.AdditionalParams,
 		&s.CountryStatus,
+		&s.IPTypeStatus,
 	}
 }
 
@@ -1408,7 +1426,7 @@ func (v *endpointUITableType) Name() string {
 
 // Columns returns a new slice of column names for that view or table in SQL database.
 func (v *endpointUITableType) Columns() []string {
-	return []string{"id", "payment_receiver_address", "service_endpoint_address", "country_status"}
+	return []string{"id", "payment_receiver_address", "service_endpoint_address", "country_status", "ip_type_status"}
 }
 
 // NewStruct makes a new struct for that view or table.
@@ -1428,17 +1446,18 @@ func (v *endpointUITableType) PKColumnIndex() uint {
 
 // EndpointUITable represents endpoints view or table in SQL database.
 var EndpointUITable = &endpointUITableType{
-	s: parse.StructInfo{Type: "EndpointUI", SQLSchema: "", SQLName: "endpoints", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "PaymentReceiverAddress", Type: "*string", Column: "payment_receiver_address"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "CountryStatus", Type: "*string", Column: "country_status"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "EndpointUI", SQLSchema: "", SQLName: "endpoints", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "PaymentReceiverAddress", Type: "*string", Column: "payment_receiver_address"}, {Name: "ServiceEndpointAddress", Type: "*string", Column: "service_endpoint_address"}, {Name: "CountryStatus", Type: "*string", Column: "country_status"}, {Name: "IPTypeStatus", Type: "*string", Column: "ip_type_status"}}, PKFieldIndex: 0},
 	z: new(EndpointUI).Values(),
 }
 
 // String returns a string representation of this struct or record.
 func (s EndpointUI) String() string {
-	res := make([]string, 4)
+	res := make([]string, 5)
 	res[0] = "ID: " + reform.Inspect(s.ID, true)
 	res[1] = "PaymentReceiverAddress: " + reform.Inspect(s.PaymentReceiverAddress, true)
 	res[2] = "ServiceEndpointAddress: " + reform.Inspect(s.ServiceEndpointAddress, true)
 	res[3] = "CountryStatus: " + reform.Inspect(s.CountryStatus, true)
+	res[4] = "IPTypeStatus: " + reform.Inspect(s.IPTypeStatus, true)
 	return strings.Join(res, ", ")
 }
 
@@ -1450,6 +1469,7 @@ func (s *EndpointUI) Values() []interface{} {
 		s.PaymentReceiverAddress,
 		s.ServiceEndpointAddress,
 		s.CountryStatus,
+		s.IPTypeStatus,
 	}
 }
 
@@ -1461,6 +1481,7 @@ func (s *EndpointUI) Pointers() []interface{} {
 		&s.PaymentReceiverAddress,
 		&s.ServiceEndpointAddress,
 		&s.CountryStatus,
+		&s.IPTypeStatus,
 	}
 }
 
@@ -2107,6 +2128,372 @@ var (
 	_ fmt.Stringer  = (*Rating)(nil)
 )
 
+type topUpPolicyTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *topUpPolicyTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("topup_policies").
+func (v *topUpPolicyTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *topUpPolicyTableType) Columns() []string {
+	return []string{"channel", "mode", "amount", "min_units_multiple", "max_deposit", "daily_cap"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *topUpPolicyTableType) NewStruct() reform.Struct {
+	return new(TopUpPolicy)
+}
+
+// NewRecord makes a new record for that table.
+func (v *topUpPolicyTableType) NewRecord() reform.Record {
+	return new(TopUpPolicy)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *topUpPolicyTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// TopUpPolicyTable represents topup_policies view or table in SQL database.
+var TopUpPolicyTable = &topUpPolicyTableType{
+	s: parse.StructInfo{Type: "TopUpPolicy", SQLSchema: "", SQLName: "topup_policies", Fields: []parse.FieldInfo{{Name: "Channel", Type: "string", Column: "channel"}, {Name: "Mode", Type: "string", Column: "mode"}, {Name: "Amount", Type: "uint64", Column: "amount"}, {Name: "MinUnitsMultiple", Type: "uint64", Column: "min_units_multiple"}, {Name: "MaxDeposit", Type: "uint64", Column: "max_deposit"}, {Name: "DailyCap", Type: "uint64", Column: "daily_cap"}}, PKFieldIndex: 0},
+	z: new(TopUpPolicy).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s TopUpPolicy) String() string {
+	res := make([]string, 6)
+	res[0] = "Channel: " + reform.Inspect(s.Channel, true)
+	res[1] = "Mode: " + reform.Inspect(s.Mode, true)
+	res[2] = "Amount: " + reform.Inspect(s.Amount, true)
+	res[3] = "MinUnitsMultiple: " + reform.Inspect(s.MinUnitsMultiple, true)
+	res[4] = "MaxDeposit: " + reform.Inspect(s.MaxDeposit, true)
+	res[5] = "DailyCap: " + reform.Inspect(s.DailyCap, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *TopUpPolicy) Values() []interface{} {
+	return []interface{}{
+		s.Channel,
+		s.Mode,
+		s.Amount,
+		s.MinUnitsMultiple,
+		s.MaxDeposit,
+		s.DailyCap,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *TopUpPolicy) Pointers() []interface{} {
+	return []interface{}{
+		&s.Channel,
+		&s.Mode,
+		&s.Amount,
+		&s.MinUnitsMultiple,
+		&s.MaxDeposit,
+		&s.DailyCap,
+	}
+}
+
+// View returns View object for that struct.
+func (s *TopUpPolicy) View() reform.View {
+	return TopUpPolicyTable
+}
+
+// Table returns Table object for that record.
+func (s *TopUpPolicy) Table() reform.Table {
+	return TopUpPolicyTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *TopUpPolicy) PKValue() interface{} {
+	return s.Channel
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *TopUpPolicy) PKPointer() interface{} {
+	return &s.Channel
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *TopUpPolicy) HasPK() bool {
+	return s.Channel != TopUpPolicyTable.z[TopUpPolicyTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *TopUpPolicy) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.Channel = string(i64)
+	} else {
+		s.Channel = pk.(string)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View   = TopUpPolicyTable
+	_ reform.Struct = (*TopUpPolicy)(nil)
+	_ reform.Table  = TopUpPolicyTable
+	_ reform.Record = (*TopUpPolicy)(nil)
+	_ fmt.Stringer  = (*TopUpPolicy)(nil)
+)
+
+type spendingBudgetTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *spendingBudgetTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("spending_budgets").
+func (v *spendingBudgetTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *spendingBudgetTableType) Columns() []string {
+	return []string{"id", "account", "period", "amount"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *spendingBudgetTableType) NewStruct() reform.Struct {
+	return new(SpendingBudget)
+}
+
+// NewRecord makes a new record for that table.
+func (v *spendingBudgetTableType) NewRecord() reform.Record {
+	return new(SpendingBudget)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *spendingBudgetTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// SpendingBudgetTable represents spending_budgets view or table in SQL database.
+var SpendingBudgetTable = &spendingBudgetTableType{
+	s: parse.StructInfo{Type: "SpendingBudget", SQLSchema: "", SQLName: "spending_budgets", Fields: []parse.FieldInfo{{Name: "ID", Type: "string", Column: "id"}, {Name: "Account", Type: "*string", Column: "account"}, {Name: "Period", Type: "string", Column: "period"}, {Name: "Amount", Type: "uint64", Column: "amount"}}, PKFieldIndex: 0},
+	z: new(SpendingBudget).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s SpendingBudget) String() string {
+	res := make([]string, 4)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "Account: " + reform.Inspect(s.Account, true)
+	res[2] = "Period: " + reform.Inspect(s.Period, true)
+	res[3] = "Amount: " + reform.Inspect(s.Amount, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *SpendingBudget) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.Account,
+		s.Period,
+		s.Amount,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *SpendingBudget) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.Account,
+		&s.Period,
+		&s.Amount,
+	}
+}
+
+// View returns View object for that struct.
+func (s *SpendingBudget) View() reform.View {
+	return SpendingBudgetTable
+}
+
+// Table returns Table object for that record.
+func (s *SpendingBudget) Table() reform.Table {
+	return SpendingBudgetTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *SpendingBudget) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *SpendingBudget) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *SpendingBudget) HasPK() bool {
+	return s.ID != SpendingBudgetTable.z[SpendingBudgetTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *SpendingBudget) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.ID = string(i64)
+	} else {
+		s.ID = pk.(string)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View   = SpendingBudgetTable
+	_ reform.Struct = (*SpendingBudget)(nil)
+	_ reform.Table  = SpendingBudgetTable
+	_ reform.Record = (*SpendingBudget)(nil)
+	_ fmt.Stringer  = (*SpendingBudget)(nil)
+)
+
+type usageSampleTableType struct {
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *usageSampleTableType) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("usage_samples").
+func (v *usageSampleTableType) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *usageSampleTableType) Columns() []string {
+	return []string{"id", "session", "channel", "time", "units", "downsampled"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *usageSampleTableType) NewStruct() reform.Struct {
+	return new(UsageSample)
+}
+
+// NewRecord makes a new record for that table.
+func (v *usageSampleTableType) NewRecord() reform.Record {
+	return new(UsageSample)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *usageSampleTableType) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// UsageSampleTable represents usage_samples view or table in SQL database.
+var UsageSampleTable = &usageSampleTableType{
+	s: parse.StructInfo{Type: "UsageSample", SQLSchema: "", SQLName: "usage_samples", Fields: []parse.FieldInfo{{Name: "ID", Type: "int64", Column: "id"}, {Name: "Session", Type: "string", Column: "session"}, {Name: "Channel", Type: "string", Column: "channel"}, {Name: "Time", Type: "time.Time", Column: "time"}, {Name: "Units", Type: "uint64", Column: "units"}, {Name: "Downsampled", Type: "bool", Column: "downsampled"}}, PKFieldIndex: 0},
+	z: new(UsageSample).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s UsageSample) String() string {
+	res := make([]string, 6)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "Session: " + reform.Inspect(s.Session, true)
+	res[2] = "Channel: " + reform.Inspect(s.Channel, true)
+	res[3] = "Time: " + reform.Inspect(s.Time, true)
+	res[4] = "Units: " + reform.Inspect(s.Units, true)
+	res[5] = "Downsampled: " + reform.Inspect(s.Downsampled, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *UsageSample) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.Session,
+		s.Channel,
+		s.Time,
+		s.Units,
+		s.Downsampled,
+	}
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *UsageSample) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.Session,
+		&s.Channel,
+		&s.Time,
+		&s.Units,
+		&s.Downsampled,
+	}
+}
+
+// View returns View object for that struct.
+func (s *UsageSample) View() reform.View {
+	return UsageSampleTable
+}
+
+// Table returns Table object for that record.
+func (s *UsageSample) Table() reform.Table {
+	return UsageSampleTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *UsageSample) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *UsageSample) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *UsageSample) HasPK() bool {
+	return s.ID != UsageSampleTable.z[UsageSampleTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *UsageSample) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.ID = int64(i64)
+	} else {
+		s.ID = pk.(int64)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View   = UsageSampleTable
+	_ reform.Struct = (*UsageSample)(nil)
+	_ reform.Table  = UsageSampleTable
+	_ reform.Record = (*UsageSample)(nil)
+	_ fmt.Stringer  = (*UsageSample)(nil)
+)
+
 func init() {
 	parse.AssertUpToDate(&AccountTable.s, new(Account))
 	parse.AssertUpToDate(&UserTable.s, new(User))
@@ -2124,4 +2511,7 @@ func init() {
 	parse.AssertUpToDate(&LogEventView.s, new(LogEvent))
 	parse.AssertUpToDate(&ClosingTable.s, new(Closing))
 	parse.AssertUpToDate(&RatingTable.s, new(Rating))
+	parse.AssertUpToDate(&TopUpPolicyTable.s, new(TopUpPolicy))
+	parse.AssertUpToDate(&SpendingBudgetTable.s, new(SpendingBudget))
+	parse.AssertUpToDate(&UsageSampleTable.s, new(UsageSample))
 }
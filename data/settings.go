@@ -26,6 +26,37 @@ const (
 	SettingClientAutoincreaseDepositPercent = "client.autoincrease.percent"
 	SettingClientAutoincreaseDeposit        = "client.autoincrease.deposit"
 	SettingRatingRankingSteps               = "rating.ranking.steps"
+	SettingClientFailover                   = "client.failover"
+	SettingClientFailoverMaxFailures        = "client.failover.maxfailures"
+	SettingClientFailoverMaxPrice           = "client.failover.maxprice"
+	SettingClientFailoverUncooperative      = "client.failover.uncooperative"
+	SettingClientBudgetWarnThresholds       = "client.budget.warnthresholds"
+	SettingUsageSamplesRawPeriod            = "usage.samples.rawperiod"
+	SettingUsageSamplesRetention            = "usage.samples.retention"
+	SettingClientVerifyRatingPenalty        = "client.verify.ratingpenalty"
+	SettingClientVerifyAutoClose            = "client.verify.autoclose"
+	SettingClientVanishAutoClose            = "client.vanish.autoclose"
+	SettingClientVanishWindow               = "client.vanish.window"
+	SettingClientVanishMinFailures          = "client.vanish.minfailures"
+	SettingClientUsageTolerance             = "client.usage.tolerance"
+	SettingClientUsageBlockPayments         = "client.usage.blockpayments"
+	SettingRetentionJobsMaxAge              = "retention.jobs.maxage"
+	SettingRetentionJobsMaxCount            = "retention.jobs.maxcount"
+	SettingRetentionLogEventsMaxAge         = "retention.log_events.maxage"
+	SettingRetentionLogEventsMaxCount       = "retention.log_events.maxcount"
+	SettingRetentionEthTxsMaxAge            = "retention.eth_txs.maxage"
+	SettingRetentionEthTxsMaxCount          = "retention.eth_txs.maxcount"
+	SettingRetentionSessionsMaxAge          = "retention.sessions.maxage"
+	SettingRetentionSessionsMaxCount        = "retention.sessions.maxcount"
+	SettingRetentionClosingsMaxAge          = "retention.closings.maxage"
+	SettingRetentionClosingsMaxCount        = "retention.closings.maxcount"
+	SettingErrorSendRemote                  = "error.sendremote"
+	SettingMessageEnvelope                  = "messages.envelope"
+	SettingDisputeMaxGasIncrease            = "dispute.gas.maxincrease"
+	SettingSettlementOnTerminate            = "settlement.onterminate"
+	SettingSettlementIdleDays               = "settlement.idle.days"
+	SettingSettlementBalanceThreshold       = "settlement.balance.threshold"
+	SettingSettlementMaxGasPrice            = "settlement.gasprice.max"
 )
 
 // ReadSetting reads value of a given setting.
@@ -421,6 +421,9 @@ func NewTestFixture(t *testing.T, db *reform.DB) *TestFixture {
 	off := NewTestOffering(acc.EthAddr, prod.ID, tmpl.ID)
 	ch := NewTestChannel(
 		acc.EthAddr, user.EthAddr, off.ID, 0, 0, ChannelActive)
+	if err := SetChannelKey(ch, off.Hash); err != nil {
+		t.Fatal(err)
+	}
 	endpTmpl := NewTestTemplate(TemplateAccess)
 	prod.OfferAccessID = &endpTmpl.ID
 	prod.OfferTplID = &tmpl.ID
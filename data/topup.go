@@ -0,0 +1,77 @@
+package data
+
+import (
+	"database/sql"
+
+	"gopkg.in/reform.v1"
+)
+
+// FindTopUpPolicy returns a top up policy of a given channel or nil,
+// if the channel has no policy.
+func FindTopUpPolicy(db *reform.Querier, channel string) (*TopUpPolicy, error) {
+	var policy TopUpPolicy
+	if err := db.FindByPrimaryKeyTo(&policy, channel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// TopUpAmount returns an amount to top up a channel with according to
+// a given policy. Current total deposit is used when the policy doesn't
+// define an amount.
+func TopUpAmount(policy *TopUpPolicy, ch *Channel, offering *Offering) uint64 {
+	switch {
+	case policy == nil:
+	case policy.Amount != 0:
+		return policy.Amount
+	case policy.MinUnitsMultiple != 0:
+		// Setup price is paid once on channel creation.
+		return policy.MinUnitsMultiple * offering.MinUnits *
+			offering.UnitPrice
+	}
+	return ch.TotalDeposit
+}
+
+// LimitTopUp limits an amount to top up a channel with by caps of a given
+// policy. Zero is returned when the caps are already reached.
+func LimitTopUp(db *reform.Querier, policy *TopUpPolicy, ch *Channel,
+	amount uint64) (uint64, error) {
+	if policy == nil {
+		return amount, nil
+	}
+
+	if policy.MaxDeposit != 0 {
+		if ch.TotalDeposit >= policy.MaxDeposit {
+			return 0, nil
+		}
+		if left := policy.MaxDeposit - ch.TotalDeposit; amount > left {
+			amount = left
+		}
+	}
+
+	if policy.DailyCap != 0 {
+		var spent uint64
+		if err := db.QueryRow(`
+			SELECT COALESCE(SUM((data->>'Deposit')::bigint), 0)
+			  FROM jobs
+			 WHERE related_id = $1
+			   AND type = $2
+			   AND status IN ($3, $4)
+			   AND created_at > now() - INTERVAL '1 day'`,
+			ch.ID, JobClientPreChannelTopUp,
+			JobActive, JobDone).Scan(&spent); err != nil {
+			return 0, err
+		}
+		if spent >= policy.DailyCap {
+			return 0, nil
+		}
+		if left := policy.DailyCap - spent; amount > left {
+			amount = left
+		}
+	}
+
+	return amount, nil
+}
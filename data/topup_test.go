@@ -0,0 +1,25 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestTopUpAmount(t *testing.T) {
+	offering := &Offering{MinUnits: 10, UnitPrice: 2, SetupPrice: 100}
+	ch := &Channel{TotalDeposit: 500}
+
+	for _, v := range []struct {
+		policy   *TopUpPolicy
+		expected uint64
+	}{
+		{nil, 500},
+		{&TopUpPolicy{}, 500},
+		{&TopUpPolicy{Amount: 300}, 300},
+		{&TopUpPolicy{MinUnitsMultiple: 3}, 60},
+	} {
+		if amount := TopUpAmount(v.policy, ch, offering); amount != v.expected {
+			t.Fatalf("expected top up amount %d, got %d",
+				v.expected, amount)
+		}
+	}
+}
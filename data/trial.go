@@ -0,0 +1,83 @@
+package data
+
+import (
+	"gopkg.in/reform.v1"
+)
+
+// TrialUnits returns a number of free units of an offering, which can be
+// granted to a new channel of a given client without exceeding a trial
+// limit of the offering product.
+func TrialUnits(db *reform.Querier,
+	offering *Offering, client HexString) (uint64, error) {
+	free := uint64(offering.FreeUnits)
+	if free == 0 {
+		return 0, nil
+	}
+
+	var prod Product
+	if err := db.FindByPrimaryKeyTo(&prod, offering.Product); err != nil {
+		return 0, err
+	}
+
+	if prod.TrialUnits == nil {
+		return free, nil
+	}
+
+	var granted uint64
+	if err := db.QueryRow(`
+		SELECT COALESCE(sum(channels.free_units), 0)
+		  FROM channels
+		  JOIN offerings ON offerings.id = channels.offering
+		 WHERE offerings.product = $1 AND channels.client = $2`,
+		prod.ID, client).Scan(&granted); err != nil {
+		return 0, err
+	}
+
+	return limitTrialUnits(free, *prod.TrialUnits, granted), nil
+}
+
+// TrialUnitsByIP returns a number of free units of a channel, which can be
+// kept without exceeding a trial limit of its product for a given client IP
+// address. Free units of other channels with sessions from the same address
+// are counted.
+func TrialUnitsByIP(db *reform.Querier,
+	ch *Channel, ip string) (uint64, error) {
+	if ch.FreeUnits == 0 {
+		return 0, nil
+	}
+
+	var prod Product
+	if err := db.SelectOneTo(&prod, `
+		WHERE id = (SELECT product FROM offerings WHERE id = $1)`,
+		ch.Offering); err != nil {
+		return 0, err
+	}
+
+	if prod.TrialUnits == nil || !prod.TrialByIP {
+		return ch.FreeUnits, nil
+	}
+
+	var granted uint64
+	if err := db.QueryRow(`
+		SELECT COALESCE(sum(channels.free_units), 0)
+		  FROM channels
+		  JOIN offerings ON offerings.id = channels.offering
+		 WHERE offerings.product = $1 AND channels.id <> $2
+		   AND channels.id IN (SELECT channel FROM sessions
+					WHERE client_ip = $3)`,
+		prod.ID, ch.ID, ip).Scan(&granted); err != nil {
+		return 0, err
+	}
+
+	return limitTrialUnits(ch.FreeUnits, *prod.TrialUnits, granted), nil
+}
+
+func limitTrialUnits(free, limit, granted uint64) uint64 {
+	if granted >= limit {
+		return 0
+	}
+	if free > limit-granted {
+		return limit - granted
+	}
+	return free
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestTrialUnits(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	fxt.Offering.FreeUnits = 10
+	fxt.Channel.FreeUnits = 25
+	SaveToTestDB(t, db, fxt.Offering, fxt.Channel)
+
+	check := func(expected uint64) {
+		t.Helper()
+		free, err := TrialUnits(db.Querier, fxt.Offering, fxt.Channel.Client)
+		util.TestExpectResult(t, "TrialUnits", nil, err)
+		if free != expected {
+			t.Fatalf("expected %d free units, got %d", expected, free)
+		}
+	}
+
+	// No trial limit.
+	check(10)
+
+	fxt.Product.TrialUnits = pointer.ToUint64(30)
+	SaveToTestDB(t, db, fxt.Product)
+	check(5)
+
+	fxt.Product.TrialUnits = pointer.ToUint64(20)
+	SaveToTestDB(t, db, fxt.Product)
+	check(0)
+}
+
+func TestTrialUnitsByIP(t *testing.T) {
+	fxt := NewTestFixture(t, db)
+	defer fxt.Close()
+
+	fxt.Product.TrialUnits = pointer.ToUint64(30)
+	fxt.Channel.FreeUnits = 25
+	SaveToTestDB(t, db, fxt.Product, fxt.Channel)
+
+	ch := NewTestChannel(fxt.Channel.Agent, fxt.Channel.Client,
+		fxt.Offering.ID, 0, 0, ChannelActive)
+	ch.FreeUnits = 10
+
+	sess := NewTestSession(fxt.Channel.ID)
+	sess.ClientIP = pointer.ToString("1.2.3.4")
+
+	InsertToTestDB(t, db, ch, sess)
+	defer DeleteFromTestDB(t, db, sess, ch)
+
+	check := func(ip string, expected uint64) {
+		t.Helper()
+		free, err := TrialUnitsByIP(db.Querier, ch, ip)
+		util.TestExpectResult(t, "TrialUnitsByIP", nil, err)
+		if free != expected {
+			t.Fatalf("expected %d free units, got %d", expected, free)
+		}
+	}
+
+	// Limit per IP is disabled.
+	check("1.2.3.4", 10)
+
+	fxt.Product.TrialByIP = true
+	SaveToTestDB(t, db, fxt.Product)
+	check("1.2.3.4", 5)
+	check("5.6.7.8", 10)
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/reform.v1"
+)
+
+// Usage series time buckets.
+const (
+	UsageMinute = "minute"
+	UsageHour   = "hour"
+	UsageDay    = "day"
+)
+
+// Usage series groupings.
+const (
+	UsageByChannel  = "channel"
+	UsageByOffering = "offering"
+	UsageByClient   = "client"
+)
+
+// UsageSeriesItem is an amount of units used within a time bucket.
+type UsageSeriesItem struct {
+	Period time.Time `json:"period"`
+	Group  string    `json:"group"`
+	Units  uint64    `json:"units"`
+}
+
+var usageGroupColumns = map[string]string{
+	UsageByChannel:  "channels.id::text",
+	UsageByOffering: "channels.offering::text",
+	UsageByClient:   "channels.client::text",
+}
+
+// UsageSeries returns units used within time buckets grouped by channels,
+// offerings or clients. A non empty id limits series to a given channel,
+// offering or client. Empty dateFrom or dateTo leaves a period unbounded.
+func UsageSeries(db *reform.Querier, bucket, groupBy, id,
+	dateFrom, dateTo string) ([]UsageSeriesItem, error) {
+	if bucket != UsageMinute && bucket != UsageHour && bucket != UsageDay {
+		return nil, fmt.Errorf("unknown usage time bucket: %s", bucket)
+	}
+
+	group, ok := usageGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown usage grouping: %s", groupBy)
+	}
+
+	rows, err := db.Query(`
+		SELECT date_trunc($1, usage_samples.time) AS period,
+		       `+group+` AS grp,
+		       SUM(usage_samples.units)
+		  FROM usage_samples
+		  JOIN channels ON channels.id = usage_samples.channel
+		 WHERE ($2 = '' OR `+group+` = $2)
+		       AND ($3 = '' OR usage_samples.time >= $3::timestamptz)
+		       AND ($4 = '' OR usage_samples.time < $4::timestamptz)
+		 GROUP BY period, grp
+		 ORDER BY period, grp`, bucket, id, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []UsageSeriesItem
+	for rows.Next() {
+		var item UsageSeriesItem
+		if err := rows.Scan(
+			&item.Period, &item.Group, &item.Units); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// DownsampleUsageSamples merges usage samples older than a given time into
+// hourly samples.
+func DownsampleUsageSamples(db *reform.Querier, before time.Time) error {
+	_, err := db.Exec(`
+		WITH merged AS (
+			DELETE FROM usage_samples
+			 WHERE time < $1 AND NOT downsampled
+			RETURNING session, channel, time, units
+		)
+		INSERT INTO usage_samples (session, channel, time, units, downsampled)
+		SELECT session, channel, date_trunc('hour', time), SUM(units), true
+		  FROM merged
+		 GROUP BY session, channel, date_trunc('hour', time)`, before)
+	return err
+}
+
+// DeleteUsageSamples deletes usage samples older than a given time.
+func DeleteUsageSamples(db *reform.Querier, before time.Time) error {
+	_, err := db.DeleteFrom(UsageSampleTable, "WHERE time < $1", before)
+	return err
+}
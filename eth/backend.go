@@ -36,6 +36,8 @@ type Backend interface {
 
 	GetTransactionByHash(context.Context, common.Hash) (*types.Transaction, bool, error)
 
+	TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error)
+
 	RegisterServiceOffering(*bind.TransactOpts, [common.HashLength]byte,
 		uint64, uint16, uint8, data.Base64String) (*types.Transaction, error)
 
@@ -303,6 +305,19 @@ func (b *backendInstance) GetTransactionByHash(ctx context.Context,
 	return tx, pending, err
 }
 
+// TransactionReceipt returns the receipt of a mined transaction.
+func (b *backendInstance) TransactionReceipt(ctx context.Context,
+	hash common.Hash) (*types.Receipt, error) {
+	ctx2, cancel := b.addTimeout(ctx)
+	defer cancel()
+
+	receipt, err := b.conn.ethClient().TransactionReceipt(ctx2, hash)
+	if err != nil {
+		err = fmt.Errorf("failed to get transaction receipt: %s", err)
+	}
+	return receipt, err
+}
+
 // RegisterServiceOffering calls registerServiceOffering method of Privatix
 // service contract.
 func (b *backendInstance) RegisterServiceOffering(opts *bind.TransactOpts,
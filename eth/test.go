@@ -38,6 +38,7 @@ type TestEthBackend struct {
 	PscAddr                common.Address
 	Tx                     *types.Transaction
 	TxIsPending            bool
+	TxFailed               bool
 	OfferingAgent          common.Address
 	OfferMinDeposit        uint64
 	OfferCurrentSupply     uint16
@@ -46,6 +47,7 @@ type TestEthBackend struct {
 	OfferingIsActive       bool
 	GasPrice               *big.Int
 	EstimatedGas           uint64
+	SettleBlock            uint32 // If not 0, returned as channel settle block.
 }
 
 var _ Backend = new(TestEthBackend)
@@ -214,6 +216,10 @@ func (b *TestEthBackend) PSCGetChannelInfo(opts *bind.CallOpts,
 	client common.Address, agent common.Address,
 	blockNumber uint32,
 	hash [common.HashLength]byte) (uint64, uint32, uint64, error) {
+	if b.SettleBlock != 0 {
+		return 0, b.SettleBlock, 0, nil
+	}
+
 	settleBlock, _ := b.LatestBlockNumber(context.Background())
 
 	return 0, uint32(settleBlock.Uint64()), 0, nil
@@ -238,6 +244,16 @@ func (b *TestEthBackend) GetTransactionByHash(context.Context,
 	return b.Tx, b.TxIsPending, nil
 }
 
+// TransactionReceipt is mock to TransactionReceipt.
+func (b *TestEthBackend) TransactionReceipt(context.Context,
+	common.Hash) (*types.Receipt, error) {
+	status := types.ReceiptStatusSuccessful
+	if b.TxFailed {
+		status = types.ReceiptStatusFailed
+	}
+	return &types.Receipt{Status: status}, nil
+}
+
 // TestCalled tests the existence of a Ethereum call.
 func (b *TestEthBackend) TestCalled(t *testing.T, method string,
 	caller common.Address, gasLimit uint64, args ...interface{}) {
@@ -39,7 +39,7 @@ require (
 	github.com/mattn/go-sqlite3 v1.10.0 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/olekukonko/tablewriter v0.0.1 // indirect
-	github.com/oschwald/maxminddb-golang v1.3.1 // indirect
+	github.com/oschwald/maxminddb-golang v1.3.1
 	github.com/pborman/uuid v0.0.0-20180906182336-adf5a7427709
 	github.com/pkg/errors v0.8.1 // indirect
 	github.com/pkg/profile v1.3.0
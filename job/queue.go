@@ -26,7 +26,7 @@ type TypeConfig struct {
 	TryLimit        uint8 // Default number of tries to complete job.
 	TryPeriod       uint  // Default retry period, in milliseconds.
 	Duplicated      bool  // Whether do or do not check for duplicates.
-	FirstStartDelay uint  // Default first run delay after job added, in milliseconds.
+	FirstStartDelay uint  // Default first run delay after job added, in milliseconds (unless job is explicitly delayed).
 }
 
 // Config is a job queue configuration.
@@ -138,7 +138,7 @@ func (q *queue) Add(tx *reform.TX, j *data.Job) error {
 			return err
 		}
 	}
-	if tconf.FirstStartDelay > 0 {
+	if tconf.FirstStartDelay > 0 && !j.NotBefore.After(time.Now()) {
 		j.NotBefore = time.Now().Add(
 			time.Duration(tconf.FirstStartDelay) * time.Millisecond)
 	}
@@ -20,8 +20,11 @@ import (
 	"github.com/privatix/dappctrl/agent/somcsrv"
 	"github.com/privatix/dappctrl/bc"
 	cbill "github.com/privatix/dappctrl/client/bill"
+	"github.com/privatix/dappctrl/client/failover"
 	"github.com/privatix/dappctrl/client/somc"
+	"github.com/privatix/dappctrl/client/vanish"
 	"github.com/privatix/dappctrl/country"
+	"github.com/privatix/dappctrl/ctl"
 	"github.com/privatix/dappctrl/data"
 	dblog "github.com/privatix/dappctrl/data/log"
 	"github.com/privatix/dappctrl/eth"
@@ -34,6 +37,8 @@ import (
 	"github.com/privatix/dappctrl/proc/handlers"
 	"github.com/privatix/dappctrl/proc/looper"
 	"github.com/privatix/dappctrl/proc/worker"
+	"github.com/privatix/dappctrl/report"
+	"github.com/privatix/dappctrl/report/backend"
 	"github.com/privatix/dappctrl/report/bugsnag"
 	rlog "github.com/privatix/dappctrl/report/log"
 	"github.com/privatix/dappctrl/sess"
@@ -70,9 +75,11 @@ type config struct {
 	Proc             *proc.Config
 	Profiling        bool
 	Report           *bugsnag.Config
+	Reporter         *backend.Config
 	Role             string
 	Sess             *rpcsrv.Config
 	SOMCServer       *rpcsrv.Config
+	SOMCServerAPI    *somcsrv.Config
 	StaticPassword   string
 	TorHostname      string
 	TorSocksListener uint
@@ -98,8 +105,10 @@ func newConfig() *config {
 		Proc:          proc.NewConfig(),
 		Profiling:     false,
 		Report:        bugsnag.NewConfig(),
+		Reporter:      backend.NewConfig(),
 		Sess:          rpcsrv.NewConfig(),
 		SOMCServer:    rpcsrv.NewConfig(),
+		SOMCServerAPI: somcsrv.NewConfig(),
 		UI:            rpcsrv.NewConfig(),
 	}
 }
@@ -125,7 +134,8 @@ func getPWDStorage(conf *config) data.PWDGetSetter {
 	return data.NewStatisPWDStorage(conf.StaticPassword, data.ToPrivateKey)
 }
 
-func createLogger(conf *config, db *reform.DB) (log.Logger, io.Closer, error) {
+func createLogger(conf *config, db *reform.DB,
+	logFeed *dblog.Feed) (log.Logger, io.Closer, error) {
 	elog, err := log.NewStderrLogger(conf.FileLog.WriterConfig)
 	if err != nil {
 		return nil, nil, err
@@ -136,7 +146,7 @@ func createLogger(conf *config, db *reform.DB) (log.Logger, io.Closer, error) {
 		return nil, nil, err
 	}
 
-	dlog, err := dblog.NewLogger(conf.DBLog, db)
+	dlog, err := dblog.NewLogger(conf.DBLog, db, logFeed)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -149,8 +159,8 @@ func createLogger(conf *config, db *reform.DB) (log.Logger, io.Closer, error) {
 	logger := log.NewMultiLogger(flog, elog, dlog, blog)
 
 	blog2 := blog.(bugsnag.Log)
-	reporter, err := bugsnag.NewClient(conf.Report, db, blog2,
-		version.Message(Commit, Version))
+	reporter, err := backend.NewReporter(conf.Reporter, conf.Report, db,
+		blog2, version.Message(Commit, Version))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -163,7 +173,8 @@ func createLogger(conf *config, db *reform.DB) (log.Logger, io.Closer, error) {
 func createUIServer(conf *rpcsrv.Config, logger log.Logger, db *reform.DB,
 	queue job.Queue, pwdStorage data.PWDGetSetter, userRole string,
 	ethBack eth.Backend, processor *proc.Processor,
-	somcClientBuilder somc.ClientBuilderInterface) (*rpcsrv.Server, error) {
+	somcClientBuilder somc.ClientBuilderInterface,
+	logFeed *dblog.Feed) (*rpcsrv.Server, error) {
 	server, err := rpcsrv.NewServer(conf)
 	if err != nil {
 		return nil, err
@@ -171,7 +182,7 @@ func createUIServer(conf *rpcsrv.Config, logger log.Logger, db *reform.DB,
 
 	handler := ui.NewHandler(logger, db, queue, pwdStorage,
 		data.EncryptedKey, userRole, processor,
-		somcClientBuilder, ui.NewSimpleToken(), ethBack)
+		somcClientBuilder, ui.NewSimpleToken(), ethBack, logFeed)
 	if err := server.AddHandler("ui", handler); err != nil {
 		return nil, err
 	}
@@ -180,13 +191,17 @@ func createUIServer(conf *rpcsrv.Config, logger log.Logger, db *reform.DB,
 }
 
 func createSessServer(conf *rpcsrv.Config, logger log.Logger, db *reform.DB,
-	countryConf *country.Config, queue job.Queue) (*rpcsrv.Server, error) {
+	countryResolver country.Resolver, queue job.Queue,
+	reporters ...sess.FailureReporter) (*rpcsrv.Server, error) {
 	server, err := rpcsrv.NewServer(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	handler := sess.NewHandler(logger, db, countryConf, queue)
+	handler := sess.NewHandler(logger, db, countryResolver, queue)
+	for _, v := range reporters {
+		handler.AddFailureReporter(v)
+	}
 	if err := server.AddHandler("sess", handler); err != nil {
 		return nil, err
 	}
@@ -227,24 +242,84 @@ func startAutoPopUpLoop(ctx context.Context, cfg *looper.Config,
 	return err
 }
 
+func startPruneLoop(ctx context.Context, cfg *looper.Config,
+	logger log.Logger, db *reform.DB, queue job.Queue) {
+	if cfg.PruneTimeout == 0 {
+		return
+	}
+
+	pruneTablesFunc := func() []*data.Job {
+		return looper.PruneTables(logger, db, cfg.PruneArchiveDir, time.Now)
+	}
+
+	looper.Loop(ctx, logger, db, queue, time.Millisecond*
+		time.Duration(cfg.PruneTimeout), pruneTablesFunc)
+}
+
+func startUsageSamplesLoop(ctx context.Context, cfg *looper.Config,
+	logger log.Logger, db *reform.DB, queue job.Queue) {
+	if cfg.UsageSamplesCompactTimeout == 0 {
+		return
+	}
+
+	compactUsageSamplesFunc := func() []*data.Job {
+		return looper.CompactUsageSamples(logger, db, time.Now)
+	}
+
+	looper.Loop(ctx, logger, db, queue, time.Millisecond*
+		time.Duration(cfg.UsageSamplesCompactTimeout),
+		compactUsageSamplesFunc)
+}
+
+func startDisputeLoop(ctx context.Context, cfg *looper.Config,
+	logger log.Logger, db *reform.DB, queue job.Queue) {
+	if cfg.DisputeTimeout == 0 {
+		return
+	}
+
+	disputesFunc := func() []*data.Job {
+		return looper.AgentChannelDisputes(logger, db)
+	}
+
+	looper.Loop(ctx, logger, db, queue, time.Millisecond*
+		time.Duration(cfg.DisputeTimeout), disputesFunc)
+}
+
+func startSettlementLoop(ctx context.Context, cfg *looper.Config,
+	logger log.Logger, db *reform.DB, queue job.Queue,
+	ethBack eth.Backend) {
+	if cfg.SettlementTimeout == 0 {
+		return
+	}
+
+	settlementsFunc := func() []*data.Job {
+		return looper.AgentSettlements(logger, db, ethBack, time.Now)
+	}
+
+	looper.Loop(ctx, logger, db, queue, time.Millisecond*
+		time.Duration(cfg.SettlementTimeout), settlementsFunc)
+}
+
 func panicHunter(logger log.Logger) {
 	if err := recover(); err != nil {
 		logger.Fatal(fmt.Sprintf("panic raised: %+v", err))
 	}
 }
 
-func newAgentSOMCServer(conf *rpcsrv.Config, db *reform.DB,
-	logger log.Logger) (*rpcsrv.Server, error) {
+func newAgentSOMCServer(conf *rpcsrv.Config, somcConf *somcsrv.Config,
+	db *reform.DB, logger log.Logger) (*rpcsrv.Server, error) {
 	server, err := rpcsrv.NewServer(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	handler := somcsrv.NewHandler(db, logger)
+	handler := somcsrv.NewHandler(somcConf, db, logger)
 	if err := server.AddHandler("api", handler); err != nil {
 		return nil, err
 	}
 
+	server.Wrap(somcsrv.Guard(somcConf))
+
 	return server, nil
 }
 
@@ -265,12 +340,21 @@ func externalPorts(conf *config) ([]uint16, error) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		if err := ctl.Execute(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if err := data.ExecuteCommand(os.Args[1:]); err != nil {
 		panic(fmt.Sprintf("failed to execute command: %s", err))
 	}
 
 	fatal := make(chan error)
 	defer bugsnag.PanicHunter()
+	defer report.PanicHunter()
 
 	conf := newConfig()
 	readFlags(conf)
@@ -288,7 +372,8 @@ func main() {
 	}
 	defer data.CloseDB(db)
 
-	logger, closer, err := createLogger(conf, db)
+	logFeed := dblog.NewFeed()
+	logger, closer, err := createLogger(conf, db, logFeed)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create logger: %s", err))
 	}
@@ -314,13 +399,24 @@ func main() {
 
 	ethBack := eth.NewBackend(conf.Eth, logger)
 
+	countryResolver, err := country.NewResolver(conf.Country)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
 	worker, err := worker.NewWorker(logger, db, ethBack, conf.Gas,
-		ethBack.PSCAddress(), conf.PayAddress, pwdStorage, conf.Country,
+		ethBack.PSCAddress(), conf.PayAddress, pwdStorage, countryResolver,
 		conf.EptMsg, conf.TorHostname, somc.NewClientBuilder(conf.TorSocksListener))
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
 
+	ipTypeResolver, err := country.NewIPTypeResolver(conf.Country)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	worker.SetIPTypeResolver(ipTypeResolver)
+
 	queue := job.NewQueue(conf.Job, logger, db, handlers.HandlersMap(worker))
 	defer queue.Close()
 	worker.SetQueue(queue)
@@ -340,8 +436,14 @@ func main() {
 		fatal <- queue.Process()
 	}()
 
+	pruneCtx, pruneCancel := context.WithCancel(context.Background())
+	defer pruneCancel()
+	startPruneLoop(pruneCtx, conf.Looper, logger, db, queue)
+	startUsageSamplesLoop(pruneCtx, conf.Looper, logger, db, queue)
+
 	uiSrv, err := createUIServer(conf.UI, logger, db, queue, pwdStorage,
-		conf.Role, ethBack, pr, somc.NewClientBuilder(conf.TorSocksListener))
+		conf.Role, ethBack, pr, somc.NewClientBuilder(conf.TorSocksListener),
+		logFeed)
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
@@ -349,8 +451,23 @@ func main() {
 		fatal <- uiSrv.ListenAndServe()
 	}()
 
+	var fmon *failover.Monitor
+	var vmon *vanish.Monitor
+	var reporters []sess.FailureReporter
+	if conf.Role == data.RoleClient {
+		fmon = failover.NewMonitor(logger, db, queue)
+		if err := fmon.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+		defer fmon.Stop()
+
+		vmon = vanish.NewMonitor(logger, db, queue,
+			somc.NewClientBuilder(conf.TorSocksListener))
+		reporters = append(reporters, fmon, vmon)
+	}
+
 	sessSrv, err := createSessServer(
-		conf.Sess, logger, db, conf.Country, queue)
+		conf.Sess, logger, db, countryResolver, queue, reporters...)
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
@@ -361,6 +478,8 @@ func main() {
 	if conf.Role == data.RoleClient {
 		cmon := cbill.NewMonitor(conf.ClientMonitor, logger, db, ethBack,
 			pr, queue, conf.Eth.Contract.PSCAddrHex, pwdStorage)
+		cmon.AddFailureReporter(fmon)
+		cmon.AddFailureReporter(vmon)
 		go func() {
 			fatal <- cmon.Run()
 		}()
@@ -368,7 +487,8 @@ func main() {
 	}
 
 	if conf.Role == data.RoleAgent {
-		somcServer, err := newAgentSOMCServer(conf.SOMCServer, db, logger)
+		somcServer, err := newAgentSOMCServer(conf.SOMCServer,
+			conf.SOMCServerAPI, db, logger)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
@@ -385,6 +505,9 @@ func main() {
 			logger.Fatal(err.Error())
 		}
 
+		startDisputeLoop(ctx, conf.Looper, logger, db, queue)
+		startSettlementLoop(ctx, conf.Looper, logger, db, queue, ethBack)
+
 		paySrv := pay.NewServer(conf.PayServer, logger, db)
 		go func() {
 			fatal <- paySrv.ListenAndServe()
@@ -0,0 +1,168 @@
+package messages
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// Message types.
+const (
+	TypeOffering = "offering"
+	TypeEndpoint = "endpoint"
+)
+
+// VersionLegacy is a version of messages packed without an envelope.
+const VersionLegacy = 1
+
+// Envelope is a versioned container of a signed message. Packed envelopes
+// are signed as a whole, so the signature covers the envelope metadata too.
+type Envelope struct {
+	Type    string          `json:"type"`
+	Version uint            `json:"version"`
+	Signer  string          `json:"signer"`
+	Time    int64           `json:"time"`
+	Payload json.RawMessage `json:"payload"`
+
+	signed []byte
+	sig    []byte
+}
+
+// PackEnvelope wraps a JSON message into an envelope of a given type and
+// version, and packs it with a signature.
+func PackEnvelope(msgType string, version uint, payload []byte,
+	key *ecdsa.PrivateKey, now time.Time) ([]byte, error) {
+	env := &Envelope{
+		Type:    msgType,
+		Version: version,
+		Signer:  ethcrypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Time:    now.Unix(),
+		Payload: payload,
+	}
+
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return PackWithSignature(envBytes, key)
+}
+
+// UnpackEnvelope unpacks a signed message of a given type. Messages packed
+// without an envelope are returned in an envelope of VersionLegacy with the
+// message as a payload.
+func UnpackEnvelope(c []byte, msgType string) (*Envelope, error) {
+	if len(c) < sigLen {
+		return nil, ErrBadEnvelope
+	}
+
+	signed, sig := UnpackSignature(c)
+
+	// Legacy messages are either encrypted, so they are not JSON, or JSON
+	// with neither type nor version fields.
+	var env Envelope
+	if err := json.Unmarshal(signed, &env); err != nil ||
+		(env.Type == "" && env.Version == 0) {
+		env = Envelope{
+			Type:    msgType,
+			Version: VersionLegacy,
+			Payload: signed,
+		}
+	}
+
+	if env.Type != msgType {
+		return nil, ErrBadMessageType
+	}
+
+	if env.Version <= VersionLegacy && env.Signer != "" {
+		return nil, ErrBadEnvelope
+	}
+
+	env.signed = signed
+	env.sig = sig
+
+	return &env, nil
+}
+
+// Legacy returns true if a message is packed without an envelope.
+func (e *Envelope) Legacy() bool {
+	return e.Version == VersionLegacy
+}
+
+// Verify checks that an envelope is signed by an owner of a given public
+// key, and that the key belongs to the envelope signer.
+func (e *Envelope) Verify(pub []byte) bool {
+	if !VerifySignature(pub, ethcrypto.Keccak256(e.signed), e.sig) {
+		return false
+	}
+
+	if e.Legacy() {
+		return true
+	}
+
+	pubKey, err := ethcrypto.UnmarshalPubkey(pub)
+	if err != nil || !common.IsHexAddress(e.Signer) {
+		return false
+	}
+
+	return ethcrypto.PubkeyToAddress(*pubKey) == common.HexToAddress(e.Signer)
+}
+
+// AgentSealEnvelope encrypts a message using client's public key and packs it
+// into an envelope of a given type and version signed by agent.
+func AgentSealEnvelope(msgType string, version uint, msg, clientPub []byte,
+	agentKey *ecdsa.PrivateKey, now time.Time) ([]byte, error) {
+	pubKey, err := ethcrypto.UnmarshalPubkey(clientPub)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := ecies.ImportECDSAPublic(pubKey)
+	msgEncrypted, err := ecies.Encrypt(rand.Reader, pub, msg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(msgEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	return PackEnvelope(msgType, version, payload, agentKey, now)
+}
+
+// ClientOpenEnvelope verifies a sealed message of a given type using agent's
+// key and decrypts it using client's key. Both legacy and enveloped messages
+// are accepted. It returns the envelope and the decrypted message.
+func ClientOpenEnvelope(c []byte, msgType string, agentPub []byte,
+	clientPrv *ecdsa.PrivateKey) (*Envelope, []byte, error) {
+	env, err := UnpackEnvelope(c, msgType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !env.Verify(agentPub) {
+		return nil, nil, ErrWrongSignature
+	}
+
+	sealed := []byte(env.Payload)
+	if !env.Legacy() {
+		if err := json.Unmarshal(env.Payload, &sealed); err != nil {
+			return nil, nil, ErrBadEnvelope
+		}
+	}
+
+	prv := ecies.ImportECDSA(clientPrv)
+
+	opened, err := prv.Decrypt(sealed, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return env, opened, nil
+}
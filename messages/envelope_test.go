@@ -0,0 +1,100 @@
+package messages_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/privatix/dappctrl/messages"
+)
+
+func TestEnvelope(t *testing.T) {
+	msg := []byte(`{"foo":"bar"}`)
+
+	key, _ := ecdsa.GenerateKey(ethcrypto.S256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(ethcrypto.S256(), rand.Reader)
+	pub := ethcrypto.FromECDSAPub(&key.PublicKey)
+
+	packed, err := messages.PackEnvelope(
+		messages.TypeOffering, 2, msg, key, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := messages.UnpackEnvelope(packed,
+		messages.TypeEndpoint); err != messages.ErrBadMessageType {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	env, err := messages.UnpackEnvelope(packed, messages.TypeOffering)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Legacy() || env.Version != 2 ||
+		!bytes.Equal(env.Payload, msg) || !env.Verify(pub) ||
+		env.Verify(ethcrypto.FromECDSAPub(&otherKey.PublicKey)) {
+		t.Fatalf("unexpected envelope: %+v", env)
+	}
+
+	// Envelope metadata is covered by the signature.
+	tampered := bytes.Replace(packed, []byte(`"version":2`),
+		[]byte(`"version":3`), 1)
+	env, err = messages.UnpackEnvelope(tampered, messages.TypeOffering)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Verify(pub) {
+		t.Fatal("tampered envelope verified")
+	}
+
+	// Legacy messages are unpacked too.
+	packed, err = messages.PackWithSignature(msg, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err = messages.UnpackEnvelope(packed, messages.TypeOffering)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !env.Legacy() || !bytes.Equal(env.Payload, msg) || !env.Verify(pub) {
+		t.Fatalf("unexpected legacy envelope: %+v", env)
+	}
+}
+
+func TestSealOpenEnvelope(t *testing.T) {
+	msg := []byte(`{"foo": "bar"}`)
+
+	clientKey, _ := ecdsa.GenerateKey(ethcrypto.S256(), rand.Reader)
+	agentKey, _ := ecdsa.GenerateKey(ethcrypto.S256(), rand.Reader)
+	clientPub := ethcrypto.FromECDSAPub(&clientKey.PublicKey)
+	agentPub := ethcrypto.FromECDSAPub(&agentKey.PublicKey)
+
+	sealed, err := messages.AgentSealEnvelope(messages.TypeEndpoint, 2,
+		msg, clientPub, agentKey, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, opened, err := messages.ClientOpenEnvelope(
+		sealed, messages.TypeEndpoint, agentPub, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Version != 2 || !bytes.Equal(opened, msg) {
+		t.Fatalf("got: %x, want: %x", opened, msg)
+	}
+
+	_, _, err = messages.ClientOpenEnvelope(sealed, messages.TypeEndpoint,
+		clientPub, clientKey)
+	if err != messages.ErrWrongSignature {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package ept
+
+import (
+	"encoding/json"
+
+	"github.com/privatix/dappctrl/messages"
+)
+
+// Version is a current version of endpoint messages.
+const Version = 2
+
+// decoders decode endpoint messages of different versions. Messages of
+// versions newer than Version are decoded by the latest decoder, which
+// ignores unknown fields.
+var decoders = map[uint]func([]byte) (*Message, error){
+	messages.VersionLegacy: decodeMessage,
+	Version:                decodeMessage,
+}
+
+func decodeMessage(data []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, ErrInvalidFormat
+	}
+	return &msg, nil
+}
+
+// Decode decodes an opened endpoint message of a given version.
+func Decode(version uint, data []byte) (*Message, error) {
+	if version > Version {
+		version = Version
+	}
+
+	decode, ok := decoders[version]
+	if !ok {
+		return nil, messages.ErrUnsupportedVersion
+	}
+
+	return decode(data)
+}
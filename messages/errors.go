@@ -6,10 +6,16 @@ import "github.com/privatix/dappctrl/util/errors"
 const (
 	// CRC16("github.com/privatix/dappctrl/client/svcrun") = 0x7FDE
 	ErrWrongSignature errors.Error = 0x7FDE<<8 + iota
+	ErrBadEnvelope
+	ErrBadMessageType
+	ErrUnsupportedVersion
 )
 
 func init() {
 	errors.InjectMessages(map[errors.Error]string{
-		ErrWrongSignature: "wrong signature",
+		ErrWrongSignature:     "wrong signature",
+		ErrBadEnvelope:        "bad message envelope",
+		ErrBadMessageType:     "unexpected message type",
+		ErrUnsupportedVersion: "unsupported message version",
 	})
 }
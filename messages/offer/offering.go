@@ -2,6 +2,7 @@ package offer
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/xeipuuv/gojsonschema"
 
@@ -32,9 +33,17 @@ func OfferingMessage(agent *data.Account, template *data.Template,
 		Nonce:                     offering.ID,
 		ServiceSpecificParameters: offering.AdditionalParams,
 	}
+	if offering.ExpiresAt != nil {
+		msg.Expiry = offering.ExpiresAt.Unix()
+	}
 	return msg
 }
 
+// Expired returns true if an offering message is stale at a given time.
+func (m *Message) Expired(now time.Time) bool {
+	return m.Expiry != 0 && now.Unix() >= m.Expiry
+}
+
 // ValidMsg if is true then offering message corresponds
 // to an offer template scheme.
 func ValidMsg(schema json.RawMessage, msg Message) bool {
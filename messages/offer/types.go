@@ -23,4 +23,5 @@ type Message struct {
 	FreeUnits                 uint8             `json:"freeUnits"`
 	Nonce                     string            `json:"nonce"`
 	ServiceSpecificParameters []byte            `json:"serviceSpecificParameters"`
+	Expiry                    int64             `json:"expiry,omitempty"` // Unix time, 0 if not expiring.
 }
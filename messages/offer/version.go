@@ -0,0 +1,72 @@
+package offer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"time"
+
+	"github.com/privatix/dappctrl/messages"
+)
+
+// Version is a current version of offering messages.
+const Version = 2
+
+// decoders decode offering messages of different versions. Messages of
+// versions newer than Version are decoded by the latest decoder, which
+// ignores unknown fields.
+var decoders = map[uint]func([]byte) (*Message, error){
+	messages.VersionLegacy: decodeMessage,
+	Version:                decodeMessage,
+}
+
+func decodeMessage(data []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Pack packs an offering message signed by a given key. Legacy messages are
+// packed without an envelope for peers which do not support envelopes.
+func Pack(msg *Message, key *ecdsa.PrivateKey,
+	legacy bool, now time.Time) ([]byte, error) {
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if legacy {
+		return messages.PackWithSignature(msgBytes, key)
+	}
+
+	return messages.PackEnvelope(
+		messages.TypeOffering, Version, msgBytes, key, now)
+}
+
+// Unpack unpacks and decodes a packed offering message of any supported
+// version. A signature must be verified by a caller using the returned
+// envelope and an agent public key from the message.
+func Unpack(raw []byte) (*Message, *messages.Envelope, error) {
+	env, err := messages.UnpackEnvelope(raw, messages.TypeOffering)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version := env.Version
+	if version > Version {
+		version = Version
+	}
+
+	decode, ok := decoders[version]
+	if !ok {
+		return nil, nil, messages.ErrUnsupportedVersion
+	}
+
+	msg, err := decode(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return msg, env, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"gopkg.in/reform.v1"
@@ -12,6 +13,7 @@ import (
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/eth"
 	"github.com/privatix/dappctrl/proc"
+	"github.com/privatix/dappctrl/util"
 	"github.com/privatix/dappctrl/util/srv"
 )
 
@@ -56,32 +58,39 @@ func newPayload(db *reform.DB, channel *data.Channel, pscAddr data.HexString,
 
 	pld.BalanceMsgSig = data.FromBytes(sig)
 
+	if err := signRequest(pld, key, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return pld, nil
 }
 
-func postPayload(db *reform.DB, channel *data.Channel, pld *paymentPayload,
-	tls bool, timeout uint, pr *proc.Processor,
-	sendFunc func(req *http.Request) (*srv.Response, error)) error {
-	pldArgs, err := json.Marshal(pld)
+// signRequest adds a time, a nonce and a request signature to a payload
+// so that a pay server can reject stale and replayed requests.
+func signRequest(pld *paymentPayload, key *ecdsa.PrivateKey,
+	now time.Time) error {
+	pld.Time = now.Unix()
+	pld.Nonce = util.NewUUID()
+
+	hash, err := requestHash(pld)
 	if err != nil {
 		return err
 	}
 
-	var endp data.Endpoint
-	if err := db.FindOneTo(&endp, "channel", channel.ID); err != nil {
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
 		return err
 	}
 
-	if endp.PaymentReceiverAddress == nil {
-		return fmt.Errorf("no payment addr found for chan %s", channel)
-	}
-	//TODO: add URL validation and TLS support
-	url := *endp.PaymentReceiverAddress
+	pld.RequestSig = data.FromBytes(sig)
 
-	req, err := srv.NewHTTPRequestWithURL(
-		http.MethodPost, url, &srv.Request{Args: pldArgs})
+	return nil
+}
 
-	resp, err := sendFunc(req)
+func postPayload(db *reform.DB, channel *data.Channel, pld *paymentPayload,
+	tls bool, timeout uint, pr *proc.Processor,
+	sendFunc func(req *http.Request) (*srv.Response, error)) error {
+	resp, err := sendPayload(db, channel, pld, sendFunc)
 	if err != nil {
 		return err
 	}
@@ -100,6 +109,33 @@ func postPayload(db *reform.DB, channel *data.Channel, pld *paymentPayload,
 	return nil
 }
 
+func sendPayload(db *reform.DB, channel *data.Channel, pld *paymentPayload,
+	sendFunc func(req *http.Request) (*srv.Response, error)) (*srv.Response, error) {
+	pldArgs, err := json.Marshal(pld)
+	if err != nil {
+		return nil, err
+	}
+
+	var endp data.Endpoint
+	if err := db.FindOneTo(&endp, "channel", channel.ID); err != nil {
+		return nil, err
+	}
+
+	if endp.PaymentReceiverAddress == nil {
+		return nil, fmt.Errorf("no payment addr found for chan %s", channel)
+	}
+	//TODO: add URL validation and TLS support
+	url := *endp.PaymentReceiverAddress
+
+	req, err := srv.NewHTTPRequestWithURL(
+		http.MethodPost, url, &srv.Request{Args: pldArgs})
+	if err != nil {
+		return nil, err
+	}
+
+	return sendFunc(req)
+}
+
 // PostCheque sends a payment cheque to a payment server.
 func PostCheque(db *reform.DB, channel *data.Channel,
 	pscAddr data.HexString, key *ecdsa.PrivateKey, amount uint64,
@@ -110,3 +146,41 @@ func PostCheque(db *reform.DB, channel *data.Channel,
 	}
 	return postPayload(db, channel, pld, tls, timeout, pr, srv.Send)
 }
+
+// RequestSuspension sends a cheque with unchanged balance to a payment server
+// to find out whether an agent suspended a service. Units the agent charges
+// are returned for a suspended service.
+func RequestSuspension(db *reform.DB, channel *data.Channel,
+	pscAddr data.HexString, key *ecdsa.PrivateKey) (*PaymentResult, error) {
+	return requestSuspension(db, channel, pscAddr, key, srv.Send)
+}
+
+func requestSuspension(db *reform.DB, channel *data.Channel,
+	pscAddr data.HexString, key *ecdsa.PrivateKey,
+	sendFunc func(req *http.Request) (*srv.Response, error)) (*PaymentResult, error) {
+	pld, err := newPayload(db, channel, pscAddr, key, channel.ReceiptBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendPayload(db, channel, pld, sendFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	var res PaymentResult
+	if resp.Error != nil {
+		if resp.Error.Code == ErrCodeEqualBalance {
+			return &res, nil
+		}
+		return nil, resp.Error
+	}
+
+	if len(resp.Result) != 0 {
+		if err := json.Unmarshal(resp.Result, &res); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,180 @@
+package pay
+
+import (
+	"container/list"
+	"encoding/binary"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+	"github.com/privatix/dappctrl/util/srv"
+)
+
+// Audit records of suspicious payment requests.
+const (
+	auditReplay     = "replay"
+	auditOutOfOrder = "out_of_order"
+)
+
+// requestHash returns a hash of a payment request signed by a client in
+// addition to a balance proof.
+func requestHash(pld *paymentPayload) ([]byte, error) {
+	sig, err := data.ToBytes(pld.BalanceMsgSig)
+	if err != nil {
+		return nil, err
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(pld.Time))
+
+	return crypto.Keccak256(sig, ts[:], []byte(pld.Nonce)), nil
+}
+
+// maxNonces is a maximum number of remembered request nonces. The oldest
+// nonces are forgotten first.
+const maxNonces = 100000
+
+type nonceEntry struct {
+	nonce  string
+	expiry time.Time
+}
+
+// nonceCache remembers request nonces until requests with them get stale.
+// Nonces are lost on restart, so requests issued before the cache is
+// created are considered stale.
+type nonceCache struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	max     int
+	started time.Time
+	nonces  map[string]time.Time
+	order   *list.List // Of nonceEntry in order of expiry.
+}
+
+func newNonceCache(ttl time.Duration, max int, now time.Time) *nonceCache {
+	return &nonceCache{
+		ttl:     ttl,
+		max:     max,
+		started: now.Truncate(time.Second),
+		nonces:  make(map[string]time.Time),
+		order:   list.New(),
+	}
+}
+
+// issuedBefore returns true if a request is issued before the cache is
+// created, so its nonce could be forgotten.
+func (c *nonceCache) issuedBefore(issued time.Time) bool {
+	return issued.Before(c.started)
+}
+
+// add returns false if a nonce is already seen.
+func (c *nonceCache) add(nonce string, now time.Time) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		if now.Before(e.Value.(nonceEntry).expiry) {
+			break
+		}
+		c.remove(e)
+	}
+
+	if expiry, ok := c.nonces[nonce]; ok && now.Before(expiry) {
+		return false
+	}
+
+	if c.order.Len() >= c.max {
+		c.remove(c.order.Front())
+	}
+
+	entry := nonceEntry{nonce: nonce, expiry: now.Add(c.ttl)}
+	c.nonces[nonce] = entry.expiry
+	c.order.PushBack(entry)
+	return true
+}
+
+func (c *nonceCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(nonceEntry)
+	if c.nonces[entry.nonce] == entry.expiry {
+		delete(c.nonces, entry.nonce)
+	}
+}
+
+// requireFreshness responds with an error to a payment request without
+// time and nonce.
+func (s *Server) requireFreshness(logger log.Logger,
+	w http.ResponseWriter, pld *paymentPayload) bool {
+	if pld.Time != 0 || pld.Nonce != "" {
+		return true
+	}
+
+	s.RespondError(logger, w, &srv.Error{
+		Status:  http.StatusBadRequest,
+		Code:    errCodeStaleRequest,
+		Message: "Request time and nonce are required",
+	})
+	logger.Warn("payment request without time and nonce")
+	return false
+}
+
+// checkFreshness verifies a request signature, a request age and a nonce
+// of a payment request. Requests without them are accepted unless
+// freshness is required.
+func (s *Server) checkFreshness(logger log.Logger,
+	w http.ResponseWriter, ch *data.Channel, pub []byte,
+	pld *paymentPayload) bool {
+	if pld.Time == 0 && pld.Nonce == "" {
+		return !s.conf.RequireFreshness ||
+			s.requireFreshness(logger, w, pld)
+	}
+
+	hash, err := requestHash(pld)
+	if err != nil {
+		logger.Error("could not hash request: " + err.Error())
+		s.RespondError(logger, w, errUnexpected)
+		return false
+	}
+
+	sig, err := data.ToBytes(pld.RequestSig)
+	if err != nil || len(sig) == 0 ||
+		!crypto.VerifySignature(pub, hash, sig[:len(sig)-1]) {
+		s.RespondError(logger, w, &srv.Error{
+			Status:  http.StatusBadRequest,
+			Code:    errCodeInvalidSignature,
+			Message: "Request signature does not match",
+		})
+		logger.Warn("request signature does not match")
+		return false
+	}
+
+	now := time.Now()
+	issued := time.Unix(pld.Time, 0)
+	maxAge := time.Duration(s.conf.MaxRequestAge) * time.Second
+	age := now.Sub(issued)
+	if maxAge != 0 && (age > maxAge || age < -maxAge ||
+		s.nonces.issuedBefore(issued)) {
+		s.RespondError(logger, w, &srv.Error{
+			Status:  http.StatusBadRequest,
+			Code:    errCodeStaleRequest,
+			Message: "Request is stale",
+		})
+		logger.Warn("payment request is stale")
+		return false
+	}
+
+	if !s.nonces.add(ch.ID+"/"+pld.Nonce, now) {
+		s.RespondError(logger, w, &srv.Error{
+			Status:  http.StatusBadRequest,
+			Code:    errCodeReplayedRequest,
+			Message: "Request is replayed",
+		})
+		logger.Add("audit", auditReplay).Warn("replayed payment request")
+		return false
+	}
+
+	return true
+}
@@ -24,6 +24,8 @@ const (
 	errCodeInvalidBalance
 	errCodeInvalidSignature
 	ErrCodeEqualBalance
+	errCodeStaleRequest
+	errCodeReplayedRequest
 )
 
 var errUnexpected = &srv.Error{
@@ -140,7 +142,8 @@ func (s *Server) verifySignature(logger log.Logger,
 		logger.Warn("client signature does not match")
 		return false
 	}
-	return true
+
+	return s.checkFreshness(logger, w, ch, pub, pld)
 }
 
 func (s *Server) validateChannelForPayment(logger log.Logger,
@@ -153,6 +156,8 @@ func (s *Server) updateChannelWithPayment(logger log.Logger,
 	w http.ResponseWriter, ch *data.Channel, pld *paymentPayload) bool {
 	// Check receipt balance.
 	if ch.ReceiptBalance == pld.Balance {
+		logger.Add("audit", auditOutOfOrder).Warn(
+			"payment with equal balance")
 		s.RespondError(logger, w, &srv.Error{
 			Status:  http.StatusBadRequest,
 			Code:    ErrCodeEqualBalance,
@@ -161,6 +166,7 @@ func (s *Server) updateChannelWithPayment(logger log.Logger,
 		return false
 	}
 
+	prevBalance := ch.ReceiptBalance
 	ch.ReceiptBalance = pld.Balance
 	ch.ReceiptSignature = &pld.BalanceMsgSig
 	ret, err := s.db.Exec(`
@@ -179,6 +185,10 @@ func (s *Server) updateChannelWithPayment(logger log.Logger,
 		return false
 	}
 	if affected == 0 {
+		if pld.Balance < prevBalance {
+			logger.Add("audit", auditOutOfOrder).Warn(
+				"payment with lower balance")
+		}
 		s.RespondError(logger, w, &srv.Error{
 			Status:  http.StatusBadRequest,
 			Code:    errCodeInvalidBalance,
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
 	"github.com/privatix/dappctrl/util/srv"
 )
 
@@ -16,6 +17,17 @@ type paymentPayload struct {
 	Balance         uint64            `json:"balance"`
 	BalanceMsgSig   data.Base64String `json:"balanceMsgSig"`
 	ContractAddress data.HexString    `json:"contractAddress"`
+	Time            int64             `json:"time,omitempty"`
+	Nonce           string            `json:"nonce,omitempty"`
+	RequestSig      data.Base64String `json:"requestSig,omitempty"`
+}
+
+// PaymentResult is a result of a payment. Units used and free units are
+// reported for a suspended service to let a client verify them.
+type PaymentResult struct {
+	ServiceSuspended bool   `json:"serviceSuspended,omitempty"`
+	UnitsUsed        uint64 `json:"unitsUsed,omitempty"`
+	FreeUnits        uint64 `json:"freeUnits,omitempty"`
 }
 
 // handlePay handles clients balance proof informations.
@@ -39,8 +51,22 @@ func (s *Server) handlePay(
 		return
 	}
 
-	if !ok || !s.validateChannelForPayment(logger, w, ch, payload) ||
-		!s.updateChannelWithPayment(logger, w, ch, payload) {
+	if !ok || !s.validateChannelForPayment(logger, w, ch, payload) {
+		return
+	}
+
+	// Unchanged balance for a suspended service is a request of units the
+	// service is suspended for. Such requests must be fresh, otherwise an
+	// old cheque could be replayed to get usage of the channel.
+	if ch.ReceiptBalance == payload.Balance &&
+		ch.ServiceStatus == data.ServiceSuspended {
+		if s.requireFreshness(logger, w, payload) {
+			s.respondSuspended(logger, w, ch)
+		}
+		return
+	}
+
+	if !s.updateChannelWithPayment(logger, w, ch, payload) {
 		return
 	}
 
@@ -48,3 +74,22 @@ func (s *Server) handlePay(
 
 	logger.Info(fmt.Sprintf("received payment: %d, from: %s", payload.Balance, ch.Client))
 }
+
+func (s *Server) respondSuspended(logger log.Logger,
+	w http.ResponseWriter, ch *data.Channel) {
+	var used uint64
+	if err := s.db.QueryRow(`
+		SELECT COALESCE(sum(units_used), 0)
+		  FROM sessions
+		 WHERE channel = $1`, ch.ID).Scan(&used); err != nil {
+		logger.Error("could not get units used: " + err.Error())
+		s.RespondError(logger, w, errUnexpected)
+		return
+	}
+
+	s.RespondResult(logger, w, &PaymentResult{
+		ServiceSuspended: true,
+		UnitsUsed:        used,
+		FreeUnits:        ch.FreeUnits,
+	})
+}
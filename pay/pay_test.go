@@ -180,6 +180,64 @@ func TestInvalidPayments(t *testing.T) {
 	}
 }
 
+func TestRequestFreshness(t *testing.T) {
+	defer data.CleanTestDB(t, testDB)
+	fxt := newFixture(t)
+
+	key, err := data.TestToPrivateKey(fxt.UserAcc.PrivateKey, data.TestPassword)
+	util.TestExpectResult(t, "to private key", nil, err)
+
+	fresh := newTestPayload(t, 100, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	util.TestExpectResult(t, "sign request", nil,
+		signRequest(fresh, key, time.Now()))
+	if res := sendTestRequest(t, fresh); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	replayed := newTestPayload(t, 200, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	util.TestExpectResult(t, "sign request", nil,
+		signRequest(replayed, key, time.Now()))
+	replayed.Nonce = fresh.Nonce
+	hash, err := requestHash(replayed)
+	util.TestExpectResult(t, "request hash", nil, err)
+	sig, err := crypto.Sign(hash, key)
+	util.TestExpectResult(t, "sign", nil, err)
+	replayed.RequestSig = data.FromBytes(sig)
+
+	stale := newTestPayload(t, 300, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	util.TestExpectResult(t, "sign request", nil, signRequest(stale, key,
+		time.Now().Add(-2*time.Duration(conf.PayServer.MaxRequestAge)*
+			time.Second)))
+
+	badSig := newTestPayload(t, 400, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	util.TestExpectResult(t, "sign request", nil,
+		signRequest(badSig, key, time.Now()))
+	badSig.Time++
+
+	for _, v := range []struct {
+		pld  *paymentPayload
+		code int
+	}{
+		{replayed, errCodeReplayedRequest},
+		{stale, errCodeStaleRequest},
+		{badSig, errCodeInvalidSignature},
+	} {
+		res := sendTestRequest(t, v.pld)
+		if res.Error == nil || res.Error.Code != v.code {
+			t.Fatalf("unexpected response error: %v", res.Error)
+		}
+	}
+
+	testServer.conf.RequireFreshness = true
+	defer func() { testServer.conf.RequireFreshness = false }()
+
+	legacy := newTestPayload(t, 500, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	res := sendTestRequest(t, legacy)
+	if res.Error == nil || res.Error.Code != errCodeStaleRequest {
+		t.Fatalf("unexpected response error: %v", res.Error)
+	}
+}
+
 func TestServiceTerminate(t *testing.T) {
 	defer data.CleanTestDB(t, testDB)
 	fxt := newFixture(t)
@@ -212,6 +270,80 @@ func TestServiceTerminate(t *testing.T) {
 	}
 }
 
+func TestRequestSuspension(t *testing.T) {
+	defer data.CleanTestDB(t, testDB)
+	fxt := newFixture(t)
+
+	path := srv.GetURL(conf.PayServer.Config, payPath)
+	fxt.Endpoint.PaymentReceiverAddress = &path
+	fxt.Channel.ReceiptBalance = 50
+	fxt.Channel.FreeUnits = 3
+	fxt.Channel.ServiceStatus = data.ServiceActive
+
+	sess := data.NewTestSession(fxt.Channel.ID)
+	sess.UnitsUsed = 20
+
+	data.SaveToTestDB(t, testDB, fxt.Channel, fxt.Endpoint)
+	data.InsertToTestDB(t, testDB, sess)
+
+	key, err := data.TestToPrivateKey(fxt.UserAcc.PrivateKey, data.TestPassword)
+	util.TestExpectResult(t, "to private key", nil, err)
+
+	pscAddr := data.HexFromBytes(common.HexToAddress("0x1").Bytes())
+
+	res, err := RequestSuspension(testDB, fxt.Channel, pscAddr, key)
+	util.TestExpectResult(t, "RequestSuspension", nil, err)
+	if res.ServiceSuspended {
+		t.Fatal("active service reported as suspended")
+	}
+
+	fxt.Channel.ServiceStatus = data.ServiceSuspended
+	data.SaveToTestDB(t, testDB, fxt.Channel)
+
+	res, err = RequestSuspension(testDB, fxt.Channel, pscAddr, key)
+	util.TestExpectResult(t, "RequestSuspension", nil, err)
+	if !res.ServiceSuspended || res.UnitsUsed != 20 || res.FreeUnits != 3 {
+		t.Fatalf("wrong suspension result: %+v", res)
+	}
+	legacy := newTestPayload(t, 50, fxt.Channel, fxt.Offering, fxt.UserAcc)
+	if res := sendTestRequest(t, legacy); res.Error == nil ||
+		res.Error.Code != errCodeStaleRequest {
+		t.Fatalf("unexpected response error: %v", res.Error)
+	}
+}
+
+func TestNonceCache(t *testing.T) {
+	now := time.Now()
+	cache := newNonceCache(time.Minute, 2, now)
+
+	if cache.issuedBefore(now) {
+		t.Fatal("request is issued after cache creation")
+	}
+	if !cache.issuedBefore(now.Add(-time.Second)) {
+		t.Fatal("request is issued before cache creation")
+	}
+
+	if !cache.add("a", now) || cache.add("a", now) {
+		t.Fatal("nonce is not remembered")
+	}
+
+	// Expired nonce is accepted again.
+	now = now.Add(time.Minute)
+	if !cache.add("a", now) {
+		t.Fatal("expired nonce is not accepted")
+	}
+
+	// The oldest nonce is forgotten beyond the size limit.
+	cache.add("b", now)
+	cache.add("c", now)
+	if len(cache.nonces) != 2 || cache.order.Len() != 2 {
+		t.Fatal("nonce cache is not bounded")
+	}
+	if !cache.add("a", now) {
+		t.Fatal("the oldest nonce is not forgotten")
+	}
+}
+
 func TestMain(m *testing.M) {
 	conf.DB = data.NewDBConfig()
 	conf.Log = log.NewWriterConfig()
@@ -2,6 +2,7 @@ package pay
 
 import (
 	"net/http"
+	"time"
 
 	reform "gopkg.in/reform.v1"
 
@@ -12,12 +13,15 @@ import (
 // Config is a configuration for a pay server.
 type Config struct {
 	*srv.Config
+	MaxRequestAge    uint // In seconds, 0 to not check age and nonce.
+	RequireFreshness bool // Reject requests without time and nonce.
 }
 
 // NewConfig creates a default pay server configuration.
 func NewConfig() *Config {
 	return &Config{
-		Config: srv.NewConfig(),
+		Config:        srv.NewConfig(),
+		MaxRequestAge: 300,
 	}
 }
 
@@ -25,8 +29,10 @@ func NewConfig() *Config {
 type Server struct {
 	*srv.Server
 
+	conf   *Config
 	db     *reform.DB
 	logger log.Logger
+	nonces *nonceCache
 }
 
 const payPath = "/v1/pmtChannel/pay"
@@ -35,8 +41,12 @@ const payPath = "/v1/pmtChannel/pay"
 func NewServer(conf *Config, logger log.Logger, db *reform.DB) *Server {
 	s := &Server{
 		Server: srv.NewServer(conf.Config),
+		conf:   conf,
 		logger: logger.Add("type", "pay.Server"),
 		db:     db,
+		nonces: newNonceCache(
+			2*time.Duration(conf.MaxRequestAge)*time.Second,
+			maxNonces, time.Now()),
 	}
 
 	s.HandleFunc(payPath,
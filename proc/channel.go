@@ -21,6 +21,11 @@ var (
 		data.ServiceActive:    struct{}{},
 		data.ServiceSuspended: struct{}{},
 	}
+	// Not a transition, but service statuses with endpoint messages issued.
+	rotateTransitions = transition{
+		data.ServiceActive:    struct{}{},
+		data.ServiceSuspended: struct{}{},
+	}
 )
 
 func checkJobExists(tx *reform.TX, rel, ty string) error {
@@ -145,3 +150,57 @@ func (p *Processor) TerminateChannel(id, jobCreator string,
 	return p.alterServiceStatus(id, jobCreator,
 		jobType, jobType, terminateTransitions, true)
 }
+
+// RotateChannel tries to re-issue an endpoint message with new access
+// credentials for a given channel. For agents only.
+func (p *Processor) RotateChannel(id, jobCreator string) (string, error) {
+	return p.alterServiceStatus(id, jobCreator,
+		data.JobAgentPreEndpointMsgRotate, data.JobAgentPreEndpointMsgRotate,
+		rotateTransitions, false)
+}
+
+// RotateProductChannels tries to re-issue endpoint messages with new access
+// credentials for all channels of a given product. Channels which are
+// already being rotated are skipped. For agents only.
+func (p *Processor) RotateProductChannels(
+	product, jobCreator string) ([]string, error) {
+	rows, err := p.db.Query(`
+		SELECT channels.id
+		  FROM channels
+		  JOIN offerings ON offerings.id = channels.offering
+		 WHERE offerings.product = $1
+		   AND channels.service_status IN ($2, $3)`,
+		product, data.ServiceActive, data.ServiceSuspended)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		channels = append(channels, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var jobs []string
+	for _, v := range channels {
+		id, err := p.RotateChannel(v, jobCreator)
+		if err == ErrSameJobExists || err == ErrBadServiceStatus {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, id)
+	}
+
+	return jobs, nil
+}
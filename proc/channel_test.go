@@ -136,6 +136,43 @@ func TestTerminateChannelClient(t *testing.T) {
 		data.ServiceSuspended, data.JobClientPreServiceTerminate, false, true)
 }
 
+func TestRotateChannel(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	fxt.Channel.ServiceStatus = data.ServicePending
+	data.SaveToTestDB(t, db, fxt.Channel)
+	_, err := proc.RotateChannel(fxt.Channel.ID, data.JobUser)
+	util.TestExpectResult(t, "RotateChannel", ErrBadServiceStatus, err)
+
+	fxt.Channel.ServiceStatus = data.ServiceActive
+	data.SaveToTestDB(t, db, fxt.Channel)
+
+	jobs, err := proc.RotateProductChannels(fxt.Product.ID, data.JobUser)
+	util.TestExpectResult(t, "RotateProductChannels", nil, err)
+	if len(jobs) != 1 {
+		t.Fatalf("unexpected number of jobs: %d", len(jobs))
+	}
+
+	job := &data.Job{ID: jobs[0]}
+	defer data.DeleteFromTestDB(t, db, job)
+
+	data.ReloadFromTestDB(t, db, job)
+	if job.Type != data.JobAgentPreEndpointMsgRotate ||
+		job.RelatedID != fxt.Channel.ID {
+		t.Fatalf("bad job data")
+	}
+
+	_, err = proc.RotateChannel(fxt.Channel.ID, data.JobUser)
+	util.TestExpectResult(t, "RotateChannel", ErrSameJobExists, err)
+
+	jobs, err = proc.RotateProductChannels(fxt.Product.ID, data.JobUser)
+	util.TestExpectResult(t, "RotateProductChannels", nil, err)
+	if len(jobs) != 0 {
+		t.Fatalf("unexpected number of jobs: %d", len(jobs))
+	}
+}
+
 func TestMain(m *testing.M) {
 	conf.DB = data.NewDBConfig()
 	conf.Job = job.NewConfig()
@@ -18,7 +18,10 @@ func HandlersMap(worker *worker.Worker) job.HandlerMap {
 		data.JobAgentPreServiceSuspend:              worker.AgentPreServiceSuspend,
 		data.JobAgentPreServiceUnsuspend:            worker.AgentPreServiceUnsuspend,
 		data.JobAgentPreServiceTerminate:            worker.AgentPreServiceTerminate,
+		data.JobAgentPreChannelDispute:              worker.AgentPreChannelDispute,
+		data.JobAgentPreCooperativeClose:            worker.AgentPreCooperativeClose,
 		data.JobAgentPreEndpointMsgCreate:           worker.AgentPreEndpointMsgCreate,
+		data.JobAgentPreEndpointMsgRotate:           worker.AgentPreEndpointMsgRotate,
 		data.JobAgentPreOfferingMsgBCPublish:        worker.AgentPreOfferingMsgBCPublish,
 		data.JobAgentAfterOfferingMsgBCPublish:      worker.AgentAfterOfferingMsgBCPublish,
 		data.JobAgentPreOfferingPopUp:               worker.AgentPreOfferingPopUp,
@@ -32,11 +35,18 @@ func HandlersMap(worker *worker.Worker) job.HandlerMap {
 		data.JobClientPreChannelCreate:               worker.ClientPreChannelCreate,
 		data.JobClientAfterChannelCreate:             worker.ClientAfterChannelCreate,
 		data.JobClientEndpointGet:                    worker.ClientEndpointGet,
+		data.JobClientEndpointRefresh:                worker.ClientEndpointRefresh,
+		data.JobClientVerifyEndpoint:                 worker.ClientVerifyEndpoint,
 		data.JobClientAfterUncooperativeClose:        worker.ClientAfterUncooperativeClose,
 		data.JobClientAfterCooperativeClose:          worker.ClientAfterCooperativeClose,
 		data.JobClientPreUncooperativeClose:          worker.ClientPreUncooperativeClose,
 		data.JobClientPreChannelTopUp:                worker.ClientPreChannelTopUp,
 		data.JobClientAfterChannelTopUp:              worker.ClientAfterChannelTopUp,
+		data.JobClientAskChannelTopUp:                worker.ClientAskChannelTopUp,
+		data.JobClientBudgetWarning:                  worker.ClientBudgetWarning,
+		data.JobClientAgentVanished:                  worker.ClientAgentVanished,
+		data.JobClientVerifyUsage:                    worker.ClientVerifyUsage,
+		data.JobClientUsageDiscrepancy:               worker.ClientUsageDiscrepancy,
 		data.JobClientPreUncooperativeCloseRequest:   worker.ClientPreUncooperativeCloseRequest,
 		data.JobClientAfterUncooperativeCloseRequest: worker.ClientAfterUncooperativeCloseRequest,
 		data.JobClientPreServiceTerminate:            worker.ClientPreServiceTerminate,
@@ -45,6 +55,7 @@ func HandlersMap(worker *worker.Worker) job.HandlerMap {
 		data.JobClientAfterOfferingMsgBCPublish:      worker.ClientAfterOfferingMsgBCPublish,
 		data.JobCompleteServiceTransition:            worker.CompleteServiceTransition,
 		data.JobClientRecordClosing:                  worker.ClientRecordClosing,
+		data.JobClientPreChannelFailover:             worker.ClientPreChannelFailover,
 
 		// Common jobs.
 		data.JobPreAccountAddBalanceApprove:   worker.PreAccountAddBalanceApprove,
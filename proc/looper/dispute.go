@@ -0,0 +1,42 @@
+package looper
+
+import (
+	"fmt"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// AgentChannelDisputes creates AgentPreChannelDispute jobs for agent channels
+// in challenge period having client balance proofs to dispute with.
+func AgentChannelDisputes(logger log.Logger, db *reform.DB) []*data.Job {
+	logger = logger.Add("method", "AgentChannelDisputes")
+
+	recs, err := db.SelectAllFrom(data.ChannelTable, `
+		WHERE channel_status IN ($1, $2)
+		  AND receipt_balance > 0
+		  AND receipt_signature IS NOT NULL
+		  AND agent IN (SELECT eth_addr FROM accounts)`,
+		data.ChannelWaitChallenge, data.ChannelInChallenge)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil
+	}
+
+	var jobs []*data.Job
+	for _, v := range recs {
+		jobs = append(jobs, &data.Job{
+			Type:        data.JobAgentPreChannelDispute,
+			RelatedType: data.JobChannel,
+			RelatedID:   v.(*data.Channel).ID,
+			CreatedBy:   data.JobTask,
+			Data:        []byte("{}"),
+		})
+	}
+
+	logger.Debug(fmt.Sprintf("found %d channels to dispute", len(jobs)))
+
+	return jobs
+}
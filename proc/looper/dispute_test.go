@@ -0,0 +1,38 @@
+package looper
+
+import (
+	"testing"
+
+	"github.com/privatix/dappctrl/data"
+)
+
+func TestAgentChannelDisputes(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	if jobs := AgentChannelDisputes(logger, db); len(jobs) != 0 {
+		t.Fatalf("unexpected jobs for active channel: %d", len(jobs))
+	}
+
+	fxt.Channel.ChannelStatus = data.ChannelInChallenge
+	data.SaveToTestDB(t, db, fxt.Channel)
+
+	// Nothing to dispute with.
+	if jobs := AgentChannelDisputes(logger, db); len(jobs) != 0 {
+		t.Fatalf("unexpected jobs for zero balance: %d", len(jobs))
+	}
+
+	fxt.Channel.ReceiptBalance = 1
+	data.SaveToTestDB(t, db, fxt.Channel)
+
+	jobs := AgentChannelDisputes(logger, db)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got: %d", len(jobs))
+	}
+
+	if jobs[0].Type != data.JobAgentPreChannelDispute ||
+		jobs[0].RelatedType != data.JobChannel ||
+		jobs[0].RelatedID != fxt.Channel.ID {
+		t.Fatalf("wrong dispute job: %+v", jobs[0])
+	}
+}
@@ -13,12 +13,22 @@ import (
 
 // Config is a looper configuration.
 type Config struct {
-	AutoOfferingPopUpTimeout uint64 // In milliseconds.
+	AutoOfferingPopUpTimeout   uint64 // In milliseconds.
+	UsageSamplesCompactTimeout uint64 // In milliseconds.
+	PruneTimeout               uint64 // In milliseconds.
+	PruneArchiveDir            string
+	DisputeTimeout             uint64 // In milliseconds.
+	SettlementTimeout          uint64 // In milliseconds.
 }
 
 // NewConfig creates default looper configuration.
 func NewConfig() *Config {
-	return &Config{}
+	return &Config{
+		UsageSamplesCompactTimeout: 3600000,
+		PruneTimeout:               3600000,
+		DisputeTimeout:             60000,
+		SettlementTimeout:          3600000,
+	}
 }
 
 var (
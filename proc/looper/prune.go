@@ -0,0 +1,79 @@
+package looper
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// archiveRows appends rows as JSON lines to a gzipped file.
+func archiveRows(name string, rows []json.RawMessage) error {
+	file, err := os.OpenFile(name,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	for _, v := range rows {
+		if _, err := zw.Write(append(v, '\n')); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// PruneTables deletes rows of tables according to their retention policies
+// read from settings. If an archive directory is not empty, deleted rows
+// are archived there into gzipped JSON lines files, one per table and run.
+// The function creates no jobs.
+func PruneTables(logger log.Logger, db *reform.DB, archiveDir string,
+	timeNowFunc func() time.Time) []*data.Job {
+	logger = logger.Add("method", "PruneTables")
+
+	now := timeNowFunc()
+
+	for _, table := range data.RetentionTables {
+		logger := logger.Add("table", table)
+
+		policy, err := data.ReadRetentionPolicy(db.Querier, table)
+		if err != nil {
+			logger.Warn(err.Error())
+			continue
+		}
+
+		var archive func(rows []json.RawMessage) error
+		if archiveDir != "" {
+			name := filepath.Join(archiveDir, fmt.Sprintf(
+				"%s-%s.jsonl.gz", table, now.Format("20060102T150405")))
+			archive = func(rows []json.RawMessage) error {
+				return archiveRows(name, rows)
+			}
+		}
+
+		n, err := data.PruneTable(db, table, policy, now, archive)
+		if err != nil {
+			logger.Error(err.Error())
+		}
+
+		if n != 0 {
+			logger.Add("deleted", n).Info("table is pruned")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package looper
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestPruneTables(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	maxAgeSetting := &data.Setting{
+		Key:   data.SettingRetentionJobsMaxAge,
+		Value: "1",
+		Name:  "jobs max age",
+	}
+	maxCountSetting := &data.Setting{
+		Key:   data.SettingRetentionJobsMaxCount,
+		Value: "0",
+		Name:  "jobs max count",
+	}
+	data.InsertToTestDB(t, db, maxAgeSetting, maxCountSetting)
+	defer data.DeleteFromTestDB(t, db, maxAgeSetting, maxCountSetting)
+
+	now := time.Now()
+	newJob := func(status, relType, relID string,
+		created time.Time) *data.Job {
+		job := data.NewTestJob(data.JobClientAfterChannelCreate,
+			data.JobUser, relType)
+		job.Status = status
+		job.RelatedID = relID
+		job.CreatedAt = created
+		return job
+	}
+
+	old := now.AddDate(0, 0, -2)
+	pruned := newJob(data.JobDone, data.JobChannel, util.NewUUID(), old)
+	active := newJob(data.JobActive, data.JobChannel, util.NewUUID(), old)
+	related := newJob(data.JobDone, data.JobChannel, fxt.Channel.ID, old)
+	recent := newJob(data.JobDone, data.JobChannel, util.NewUUID(), now)
+	income := newJob(data.JobDone, data.JobChannel, util.NewUUID(), old)
+	income.Type = data.JobAgentAfterUncooperativeClose
+	data.InsertToTestDB(t, db, pruned, active, related, recent, income)
+	defer data.DeleteFromTestDB(t, db, active, related, recent, income)
+
+	dir, err := ioutil.TempDir("", "dappctrl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	PruneTables(logger, db, dir, func() time.Time { return now })
+
+	if err := db.Reload(pruned); err == nil {
+		t.Fatal("old job is not pruned")
+	}
+	data.ReloadFromTestDB(t, db, active, related, recent, income)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Size() == 0 {
+		t.Fatal("pruned job is not archived")
+	}
+}
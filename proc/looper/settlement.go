@@ -0,0 +1,114 @@
+package looper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/eth"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// settlementRetryPeriod is a period after which a cooperative close of
+// a still active channel is submitted again.
+const settlementRetryPeriod = 24 * time.Hour
+
+// AgentSettlements creates AgentPreCooperativeClose jobs for terminated but
+// still active agent channels according to settlement policies read from
+// settings. Channels are closed after a number of idle days or once their
+// receipt balances reach a threshold. If a max gas price is set, no jobs
+// are created until suggested gas price falls to it.
+func AgentSettlements(logger log.Logger, db *reform.DB,
+	ethBack eth.Backend, timeNowFunc func() time.Time) []*data.Job {
+	logger = logger.Add("method", "AgentSettlements")
+
+	idleDays, err := data.ReadUintSetting(
+		db.Querier, data.SettingSettlementIdleDays)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	threshold, err := data.ReadUint64Setting(
+		db.Querier, data.SettingSettlementBalanceThreshold)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	if idleDays == 0 && threshold == 0 {
+		return nil
+	}
+
+	maxGasPrice, err := data.ReadUint64Setting(
+		db.Querier, data.SettingSettlementMaxGasPrice)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	if maxGasPrice != 0 {
+		gasPrice, err := ethBack.SuggestGasPrice(context.Background())
+		if err != nil {
+			logger.Error(err.Error())
+			return nil
+		}
+
+		if !gasPrice.IsUint64() || gasPrice.Uint64() > maxGasPrice {
+			logger.Add("gasPrice", gasPrice).Debug(
+				"gas price is too high for settlements")
+			return nil
+		}
+	}
+
+	now := timeNowFunc()
+
+	args := []interface{}{data.ServiceTerminated, data.ChannelActive,
+		data.JobChannel, "CooperativeClose",
+		now.Add(-settlementRetryPeriod)}
+
+	var policies []string
+	if idleDays != 0 {
+		args = append(args, now.AddDate(0, 0, -int(idleDays)))
+		policies = append(policies,
+			fmt.Sprintf("service_changed_time < $%d", len(args)))
+	}
+	if threshold != 0 {
+		args = append(args, threshold)
+		policies = append(policies,
+			fmt.Sprintf("receipt_balance >= $%d", len(args)))
+	}
+
+	recs, err := db.SelectAllFrom(data.ChannelTable, `
+		WHERE service_status = $1
+		  AND channel_status = $2
+		  AND receipt_balance > 0
+		  AND receipt_signature IS NOT NULL
+		  AND agent IN (SELECT eth_addr FROM accounts)
+		  AND NOT EXISTS (SELECT 1 FROM eth_txs
+				   WHERE related_type = $3
+				     AND related_id = channels.id
+				     AND method = $4
+				     AND issued > $5)
+		  AND (`+strings.Join(policies, " OR ")+`)`, args...)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil
+	}
+
+	var jobs []*data.Job
+	for _, v := range recs {
+		jobs = append(jobs, &data.Job{
+			Type:        data.JobAgentPreCooperativeClose,
+			RelatedType: data.JobChannel,
+			RelatedID:   v.(*data.Channel).ID,
+			CreatedBy:   data.JobTask,
+			Data:        []byte("{}"),
+		})
+	}
+
+	logger.Debug(fmt.Sprintf("found %d channels to settle", len(jobs)))
+
+	return jobs
+}
@@ -0,0 +1,95 @@
+package looper
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestAgentSettlements(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	now := time.Now()
+	timeNowFunc := func() time.Time { return now }
+
+	changed := now.AddDate(0, 0, -2)
+	fxt.Channel.ServiceStatus = data.ServiceTerminated
+	fxt.Channel.ServiceChangedTime = &changed
+	fxt.Channel.ReceiptBalance = 10
+	data.SaveToTestDB(t, db, fxt.Channel)
+
+	settlements := func(exp int) {
+		t.Helper()
+		jobs := AgentSettlements(logger, db, ethBackend, timeNowFunc)
+		if len(jobs) != exp {
+			t.Fatalf("expected %d jobs, got: %d", exp, len(jobs))
+		}
+		for _, v := range jobs {
+			if v.Type != data.JobAgentPreCooperativeClose ||
+				v.RelatedType != data.JobChannel ||
+				v.RelatedID != fxt.Channel.ID {
+				t.Fatalf("wrong settlement job: %+v", v)
+			}
+		}
+	}
+
+	// No policies are set.
+	settlements(0)
+
+	setting := func(key, value string) *data.Setting {
+		return &data.Setting{Key: key, Value: value, Name: key}
+	}
+
+	idle := setting(data.SettingSettlementIdleDays, "3")
+	threshold := setting(data.SettingSettlementBalanceThreshold, "11")
+	maxGasPrice := setting(data.SettingSettlementMaxGasPrice, "10")
+	data.InsertToTestDB(t, db, idle, threshold, maxGasPrice)
+	defer data.DeleteFromTestDB(t, db, idle, threshold, maxGasPrice)
+
+	// Neither idle days nor threshold is reached.
+	ethBackend.GasPrice = big.NewInt(10)
+	settlements(0)
+
+	idle.Value = "1"
+	data.SaveToTestDB(t, db, idle)
+	settlements(1)
+
+	// Gas price is too high.
+	ethBackend.GasPrice = big.NewInt(11)
+	settlements(0)
+	ethBackend.GasPrice = big.NewInt(10)
+
+	idle.Value = "0"
+	threshold.Value = "10"
+	data.SaveToTestDB(t, db, idle, threshold)
+	settlements(1)
+
+	// Cooperative close is recently submitted.
+	tx := &data.EthTx{
+		ID:          util.NewUUID(),
+		Hash:        data.HexFromBytes([]byte("hash")),
+		Method:      "CooperativeClose",
+		Status:      data.TxSent,
+		Issued:      now,
+		AddrFrom:    fxt.Channel.Agent,
+		AddrTo:      fxt.Channel.Agent,
+		TxRaw:       []byte("{}"),
+		RelatedType: data.JobChannel,
+		RelatedID:   fxt.Channel.ID,
+	}
+	data.InsertToTestDB(t, db, tx)
+	defer data.DeleteFromTestDB(t, db, tx)
+	settlements(0)
+
+	tx.Issued = now.Add(-2 * settlementRetryPeriod)
+	data.SaveToTestDB(t, db, tx)
+	settlements(1)
+
+	fxt.Channel.ChannelStatus = data.ChannelClosedCoop
+	data.SaveToTestDB(t, db, fxt.Channel)
+	settlements(0)
+}
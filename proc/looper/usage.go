@@ -0,0 +1,45 @@
+package looper
+
+import (
+	"time"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// CompactUsageSamples downsamples usage samples older than a raw period
+// to hourly ones and deletes samples older than a retention period. Both
+// periods are read from settings. The function creates no jobs.
+func CompactUsageSamples(logger log.Logger, db *reform.DB,
+	timeNowFunc func() time.Time) []*data.Job {
+	logger = logger.Add("method", "CompactUsageSamples")
+
+	now := timeNowFunc()
+
+	rawPeriod, err := data.ReadUintSetting(
+		db.Querier, data.SettingUsageSamplesRawPeriod)
+	if err != nil {
+		logger.Warn(err.Error())
+	} else if rawPeriod != 0 {
+		before := now.Add(-time.Duration(rawPeriod) * time.Hour)
+		if err := data.DownsampleUsageSamples(
+			db.Querier, before); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
+	retention, err := data.ReadUintSetting(
+		db.Querier, data.SettingUsageSamplesRetention)
+	if err != nil {
+		logger.Warn(err.Error())
+	} else if retention != 0 {
+		before := now.AddDate(0, 0, -int(retention))
+		if err := data.DeleteUsageSamples(db.Querier, before); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package looper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/privatix/dappctrl/data"
+)
+
+func TestCompactUsageSamples(t *testing.T) {
+	fxt := data.NewTestFixture(t, db)
+	defer fxt.Close()
+
+	rawPeriodSetting := &data.Setting{
+		Key:   data.SettingUsageSamplesRawPeriod,
+		Value: "1",
+		Name:  "raw period",
+	}
+	retentionSetting := &data.Setting{
+		Key:   data.SettingUsageSamplesRetention,
+		Value: "1",
+		Name:  "retention",
+	}
+	data.InsertToTestDB(t, db, rawPeriodSetting, retentionSetting)
+	defer data.DeleteFromTestDB(t, db, rawPeriodSetting, retentionSetting)
+
+	sess := data.NewTestSession(fxt.Channel.ID)
+	data.InsertToTestDB(t, db, sess)
+	defer data.DeleteFromTestDB(t, db, sess)
+
+	now := time.Now()
+	hour := now.Add(-5 * time.Hour).Truncate(time.Hour)
+	newSample := func(tm time.Time, units uint64) *data.UsageSample {
+		return &data.UsageSample{
+			Session: sess.ID,
+			Channel: fxt.Channel.ID,
+			Time:    tm,
+			Units:   units,
+		}
+	}
+	data.InsertToTestDB(t, db,
+		newSample(now.AddDate(0, 0, -2), 1),
+		newSample(hour.Add(time.Minute), 2),
+		newSample(hour.Add(2*time.Minute), 3),
+		newSample(now, 4))
+
+	CompactUsageSamples(logger, db, func() time.Time { return now })
+
+	recs, err := db.SelectAllFrom(data.UsageSampleTable,
+		"WHERE session = $1 ORDER BY time", sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 {
+		t.Fatalf("wrong number of usage samples: %d", len(recs))
+	}
+
+	merged := recs[0].(*data.UsageSample)
+	if !merged.Time.Equal(hour) || merged.Units != 5 ||
+		!merged.Downsampled {
+		t.Fatalf("wrong downsampled usage sample: %+v", merged)
+	}
+
+	if raw := recs[1].(*data.UsageSample); raw.Units != 4 ||
+		raw.Downsampled {
+		t.Fatalf("wrong raw usage sample: %+v", raw)
+	}
+}
@@ -10,13 +10,16 @@ import (
 	"github.com/AlekSi/pointer"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"gopkg.in/reform.v1"
 
 	"github.com/privatix/dappctrl/country"
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/eth"
+	"github.com/privatix/dappctrl/job"
 	"github.com/privatix/dappctrl/messages"
+	"github.com/privatix/dappctrl/messages/ept"
 	"github.com/privatix/dappctrl/util"
 	"github.com/privatix/dappctrl/util/log"
 )
@@ -93,6 +96,22 @@ func (w *Worker) AgentAfterChannelCreate(job *data.Job) error {
 		Block:         uint32(ethLog.Block),
 	}
 
+	if err := data.SetChannelKey(channel, offering.Hash); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	channel.FreeUnits, err = data.TrialUnits(
+		tx.Querier, offering, channel.Client)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+	if channel.FreeUnits < uint64(offering.FreeUnits) {
+		logger.Add("freeUnits", channel.FreeUnits).Info(
+			"client trial is used up")
+	}
+
 	if err := tx.Insert(channel); err != nil {
 		logger.Error(err.Error())
 		return ErrInternal
@@ -264,11 +283,50 @@ func (w *Worker) AgentPreServiceTerminate(job *data.Job) error {
 		return nil
 	}
 
-	return w.agentCooperativeClose(logger, job, channel)
+	onTerminate, err := data.ReadBoolSetting(w.db.Querier,
+		data.SettingSettlementOnTerminate)
+	if err != nil {
+		logger.Warn(err.Error())
+		onTerminate = true
+	}
+
+	if !onTerminate {
+		logger.Info("cooperative close is left to settlement policies")
+		return nil
+	}
+
+	return w.agentCooperativeClose(logger, job, channel, 0)
 }
 
+// AgentPreCooperativeClose submits the latest client balance proof of a
+// terminated channel according to settlement policies.
+func (w *Worker) AgentPreCooperativeClose(job *data.Job) error {
+	logger := w.logger.Add("method", "AgentPreCooperativeClose", "job", job)
+
+	channel, err := w.relatedChannel(logger, job,
+		data.JobAgentPreCooperativeClose)
+	if err != nil {
+		return err
+	}
+
+	if channel.ServiceStatus != data.ServiceTerminated ||
+		channel.ChannelStatus != data.ChannelActive {
+		logger.Add("channel", channel).Warn(
+			"channel is not terminated or not active")
+		return nil
+	}
+
+	if channel.ReceiptBalance == 0 {
+		return nil
+	}
+
+	return w.agentCooperativeClose(logger, job, channel, 0)
+}
+
+// agentCooperativeClose submits the latest client balance proof of a
+// channel. If gas price is 0, a suggested one is used.
 func (w *Worker) agentCooperativeClose(logger log.Logger, job *data.Job,
-	channel *data.Channel) error {
+	channel *data.Channel, gasPrice uint64) error {
 	logger = logger.Add("channel", channel)
 
 	offering, err := w.offering(logger, channel.Offering)
@@ -327,10 +385,15 @@ func (w *Worker) agentCooperativeClose(logger log.Logger, job *data.Job,
 
 	auth := bind.NewKeyedTransactor(accKey)
 	auth.GasLimit = w.gasConf.PSC.CooperativeClose
-	auth.GasPrice, err = w.ethBack.SuggestGasPrice(context.Background())
-	if err != nil {
-		logger.Error(err.Error())
-		return ErrInternal
+	if gasPrice != 0 {
+		auth.GasPrice = new(big.Int).SetUint64(gasPrice)
+	} else {
+		auth.GasPrice, err = w.ethBack.SuggestGasPrice(
+			context.Background())
+		if err != nil {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
 	}
 
 	tx, err := w.ethBack.CooperativeClose(auth, agentAddr,
@@ -346,6 +409,198 @@ func (w *Worker) agentCooperativeClose(logger log.Logger, job *data.Job,
 		job.RelatedID, agent.EthAddr, data.HexFromBytes(w.pscAddr.Bytes()))
 }
 
+// AgentPreChannelDispute submits the latest client balance proof of a
+// channel in challenge period before the period ends. Gas price of a pending
+// cooperative close is increased as the end of the period approaches.
+func (w *Worker) AgentPreChannelDispute(job *data.Job) error {
+	logger := w.logger.Add("method", "AgentPreChannelDispute", "job", job)
+
+	channel, err := w.relatedChannel(logger, job,
+		data.JobAgentPreChannelDispute)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.Add("channel", channel)
+
+	if channel.ChannelStatus != data.ChannelWaitChallenge &&
+		channel.ChannelStatus != data.ChannelInChallenge {
+		logger.Warn("channel is not in challenge period")
+		return nil
+	}
+
+	if channel.ReceiptBalance == 0 || channel.ReceiptSignature == nil {
+		logger.Warn("no balance proof to dispute with")
+		return nil
+	}
+
+	offering, err := w.offering(logger, channel.Offering)
+	if err != nil {
+		return err
+	}
+
+	offeringHash, err := w.toOfferingHashArr(logger, offering.Hash)
+	if err != nil {
+		return err
+	}
+
+	clientAddr, err := data.HexToAddress(channel.Client)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrParseEthAddr
+	}
+
+	agentAddr, err := data.HexToAddress(channel.Agent)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrParseEthAddr
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := w.blocksTillChallangeEnd(ctx, logger,
+		clientAddr, agentAddr, channel.Block, offeringHash)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.Add("blocksTillChallangeEnd", blocks)
+
+	if blocks <= 0 {
+		logger.Error("challenge period is over")
+		job.Status = data.JobCanceled
+		w.db.Save(job)
+		return nil
+	}
+
+	gasPrice, err := w.disputeGasPrice(ctx, logger, uint64(blocks))
+	if err != nil {
+		return err
+	}
+
+	var closeTx data.EthTx
+	err = w.db.SelectOneTo(&closeTx, `
+		WHERE related_type=$1 AND related_id=$2 AND method=$3
+		ORDER BY issued DESC LIMIT 1`,
+		data.JobChannel, channel.ID, "CooperativeClose")
+	if err == reform.ErrNoRows {
+		return w.agentCooperativeClose(logger, job, channel, gasPrice)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return w.agentEscalateDispute(ctx, logger, job, channel,
+		&closeTx, gasPrice)
+}
+
+// disputeGasPrice returns a suggested gas price increased proportionally to
+// the passed part of a challenge period.
+func (w *Worker) disputeGasPrice(ctx context.Context, logger log.Logger,
+	blocksLeft uint64) (uint64, error) {
+	suggested, err := w.ethBack.SuggestGasPrice(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, ErrInternal
+	}
+
+	period, err := data.ReadUintSetting(w.db.Querier,
+		data.SettingsPeriodChallange)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, ErrInternal
+	}
+
+	increase, err := data.ReadUintSetting(w.db.Querier,
+		data.SettingDisputeMaxGasIncrease)
+	if err != nil {
+		logger.Warn(err.Error())
+		increase = 0
+	}
+
+	passed := uint64(0)
+	if blocksLeft < uint64(period) {
+		passed = uint64(period) - blocksLeft
+	}
+
+	percent := big.NewInt(100)
+	if period != 0 {
+		percent.Add(percent, new(big.Int).SetUint64(
+			uint64(increase)*passed/uint64(period)))
+	}
+
+	price := new(big.Int).Mul(suggested, percent)
+	return price.Div(price, big.NewInt(100)).Uint64(), nil
+}
+
+// agentEscalateDispute increases gas price of a pending cooperative close
+// transaction, if a given gas price is higher than the current one. Failed
+// cooperative close is submitted again.
+func (w *Worker) agentEscalateDispute(ctx context.Context, logger log.Logger,
+	disputeJob *data.Job, channel *data.Channel, closeTx *data.EthTx,
+	gasPrice uint64) error {
+	logger = logger.Add("ethTx", closeTx.ID, "gasPrice", gasPrice)
+
+	// Transactions resent with increased gas price are related to the
+	// original one.
+	var current data.EthTx
+	err := w.db.SelectOneTo(&current, `
+		WHERE id=$1 OR (related_type=$2 AND related_id=$1)
+		ORDER BY gas_price DESC LIMIT 1`,
+		closeTx.ID, data.JobTransaction)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	txHash, err := data.HexToHash(current.Hash)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	_, pending, err := w.ethBack.GetTransactionByHash(ctx, txHash)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrEthGetTransaction
+	}
+
+	if !pending {
+		receipt, err := w.ethBack.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			logger.Error(err.Error())
+			return ErrEthGetTransaction
+		}
+
+		if receipt.Status == types.ReceiptStatusFailed {
+			logger.Warn("cooperative close failed, resubmitting")
+			return w.agentCooperativeClose(logger, disputeJob, channel,
+				gasPrice)
+		}
+
+		logger.Info("cooperative close is mined")
+		return nil
+	}
+
+	if gasPrice <= current.GasPrice {
+		return nil
+	}
+
+	err = job.AddWithData(w.queue, nil, data.JobIncreaseTxGasPrice,
+		data.JobTransaction, closeTx.ID, data.JobTask,
+		&data.JobPublishData{GasPrice: gasPrice})
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+		return ErrAddJob
+	}
+
+	logger.Warn("cooperative close gas price is increased")
+
+	return nil
+}
+
 func (w *Worker) agentUpdateServiceStatus(logger log.Logger, job *data.Job,
 	jobType string) (*data.Channel, error) {
 	channel, err := w.relatedChannel(logger, job, jobType)
@@ -393,17 +648,78 @@ func (w *Worker) AgentPreEndpointMsgCreate(job *data.Job) error {
 
 	logger = logger.Add("channel", channel)
 
+	newEndpoint, password, err := w.newAgentEndpoint(logger, channel)
+	if err != nil {
+		return err
+	}
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	if err = tx.Insert(newEndpoint); err != nil {
+		logger.Error(err.Error())
+		tx.Rollback()
+		return err
+	}
+
+	passwordHash, salt, err := hashChannelPassword(logger, password)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	offering, err := w.offering(logger, channel.Offering)
+	if err != nil {
+		return err
+	}
+
+	channel.Password = passwordHash
+	channel.Salt = salt
+
+	if offering.BillingType == data.BillingPrepaid ||
+		offering.SetupPrice > 0 {
+		channel.ServiceStatus = data.ServiceSuspending
+
+	} else {
+		channel.ServiceStatus = data.ServiceActivating
+	}
+	changedTime := time.Now().Add(time.Minute)
+	channel.ServiceChangedTime = &changedTime
+
+	if err = tx.Update(channel); err != nil {
+		logger.Error(err.Error())
+		tx.Rollback()
+		return ErrInternal
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(err.Error())
+		tx.Rollback()
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// newAgentEndpoint makes a new endpoint message for a channel with freshly
+// generated credentials and seals it for the channel client. It returns the
+// endpoint record and the generated password.
+func (w *Worker) newAgentEndpoint(logger log.Logger,
+	channel *data.Channel) (*data.Endpoint, string, error) {
 	msg, err := w.ept.EndpointMessage(channel.ID)
 	if err != nil {
 		logger.Error(err.Error())
-		return ErrMakeEndpointMsg
+		return nil, "", ErrMakeEndpointMsg
 	}
 
 	logger = logger.Add("endpointMsg", msg)
 
 	template, err := w.templateByHash(logger, msg.TemplateHash)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	logger = logger.Add("template", template)
@@ -411,12 +727,12 @@ func (w *Worker) AgentPreEndpointMsgCreate(job *data.Job) error {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		logger.Error(err.Error())
-		return ErrInternal
+		return nil, "", ErrInternal
 	}
 
 	client, err := w.user(logger, channel.Client)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	logger = logger.Add("client", client.EthAddr)
@@ -424,25 +740,37 @@ func (w *Worker) AgentPreEndpointMsgCreate(job *data.Job) error {
 	clientPub, err := data.ToBytes(client.PublicKey)
 	if err != nil {
 		logger.Error(err.Error())
-		return ErrInternal
+		return nil, "", ErrInternal
 	}
 
 	agent, err := w.account(logger, channel.Agent)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	logger = logger.Add("agent", agent.EthAddr)
 
 	agentKey, err := w.key(logger, agent)
 	if err != nil {
-		return err
+		return nil, "", err
+	}
+
+	envelope, err := data.ReadBoolSetting(w.db.Querier,
+		data.SettingMessageEnvelope)
+	if err != nil {
+		logger.Warn(err.Error())
 	}
 
-	msgSealed, err := messages.AgentSeal(msgBytes, clientPub, agentKey)
+	var msgSealed []byte
+	if envelope {
+		msgSealed, err = messages.AgentSealEnvelope(messages.TypeEndpoint,
+			ept.Version, msgBytes, clientPub, agentKey, time.Now())
+	} else {
+		msgSealed, err = messages.AgentSeal(msgBytes, clientPub, agentKey)
+	}
 	if err != nil {
 		logger.Error(err.Error())
-		return ErrEndpointMsgSeal
+		return nil, "", ErrEndpointMsgSeal
 	}
 
 	hash := crypto.Keccak256(msgSealed)
@@ -467,60 +795,75 @@ func (w *Worker) AgentPreEndpointMsgCreate(job *data.Job) error {
 		AdditionalParams:       params,
 	}
 
-	tx, err := w.db.Begin()
+	return newEndpoint, msg.Password, nil
+}
+
+// hashChannelPassword hashes a channel password with a random salt.
+func hashChannelPassword(logger log.Logger,
+	password string) (data.Base64String, uint64, error) {
+	salt, err := rand.Int(rand.Reader, big.NewInt(9*1e18))
 	if err != nil {
 		logger.Error(err.Error())
-		return ErrInternal
+		return "", 0, ErrInternal
 	}
 
-	if err = tx.Insert(newEndpoint); err != nil {
+	hash, err := data.HashPassword(password, string(salt.Uint64()))
+	if err != nil {
 		logger.Error(err.Error())
-		tx.Rollback()
-		return err
+		return "", 0, ErrGeneratePasswordHash
 	}
 
-	salt, err := rand.Int(rand.Reader, big.NewInt(9*1e18))
+	return hash, salt.Uint64(), nil
+}
+
+// AgentPreEndpointMsgRotate regenerates access credentials of a channel and
+// replaces its endpoint message with a newly sealed one.
+func (w *Worker) AgentPreEndpointMsgRotate(job *data.Job) error {
+	logger := w.logger.Add("method", "AgentPreEndpointMsgRotate", "job", job)
+
+	channel, err := w.relatedChannel(logger, job,
+		data.JobAgentPreEndpointMsgRotate)
 	if err != nil {
 		return err
 	}
 
-	passwordHash, err := data.HashPassword(msg.Password, string(salt.Uint64()))
-	if err != nil {
-		logger.Error(err.Error())
-		return ErrGeneratePasswordHash
+	logger = logger.Add("channel", channel)
+
+	if channel.ServiceStatus != data.ServiceActive &&
+		channel.ServiceStatus != data.ServiceSuspended {
+		logger.Warn(ErrInvalidServiceStatus.Error())
+		return ErrInvalidServiceStatus
 	}
 
-	offering, err := w.offering(logger, channel.Offering)
+	oldEndpoint, err := w.endpoint(logger, channel.ID)
 	if err != nil {
 		return err
 	}
 
-	channel.Password = passwordHash
-	channel.Salt = salt.Uint64()
+	newEndpoint, password, err := w.newAgentEndpoint(logger, channel)
+	if err != nil {
+		return err
+	}
 
-	if offering.BillingType == data.BillingPrepaid ||
-		offering.SetupPrice > 0 {
-		channel.ServiceStatus = data.ServiceSuspending
+	// Endpoint record is kept to preserve references to it.
+	newEndpoint.ID = oldEndpoint.ID
 
-	} else {
-		channel.ServiceStatus = data.ServiceActivating
+	passwordHash, salt, err := hashChannelPassword(logger, password)
+	if err != nil {
+		return err
 	}
-	changedTime := time.Now().Add(time.Minute)
-	channel.ServiceChangedTime = &changedTime
 
-	if err = tx.Update(channel); err != nil {
-		logger.Error(err.Error())
-		tx.Rollback()
-		return ErrInternal
-	}
+	channel.Password = passwordHash
+	channel.Salt = salt
 
-	if err = tx.Commit(); err != nil {
-		logger.Error(err.Error())
-		tx.Rollback()
-		return ErrInternal
-	}
+	return w.db.InTransaction(func(tx *reform.TX) error {
+		if err := tx.Update(newEndpoint); err != nil {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
 
-	return nil
+		return w.saveRecord(logger, tx.Querier, channel)
+	})
 }
 
 // AgentPreOfferingMsgBCPublish publishes offering to blockchain.
@@ -299,6 +299,141 @@ func TestAgentPreServiceTerminate(t *testing.T) {
 	testAgentPreServiceTerminate(t, 1)
 }
 
+func TestAgentPreCooperativeClose(t *testing.T) {
+	env := newWorkerTest(t)
+	fixture := env.newTestFixture(t, data.JobAgentPreCooperativeClose,
+		data.JobChannel)
+	defer env.close()
+	defer fixture.close()
+
+	// Channel service is not terminated.
+	runJob(t, env.worker.AgentPreCooperativeClose, fixture.job)
+	if len(env.ethBack.CallStack) != 0 {
+		t.Fatal("cooperative close must not be called")
+	}
+
+	fixture.Channel.ServiceStatus = data.ServiceTerminated
+	fixture.Channel.ReceiptBalance = 1
+	env.updateInTestDB(t, fixture.Channel)
+
+	runJob(t, env.worker.AgentPreCooperativeClose, fixture.job)
+	testCooperativeCloseCalled(t, env, fixture)
+
+	testCommonErrors(t, env.worker.AgentPreCooperativeClose, *fixture.job)
+}
+
+func TestAgentPreServiceTerminateWithoutClose(t *testing.T) {
+	env := newWorkerTest(t)
+	fixture := env.newTestFixture(t, data.JobAgentPreServiceTerminate,
+		data.JobChannel)
+	defer env.close()
+	defer fixture.close()
+
+	onTerminate := &data.Setting{
+		Key:   data.SettingSettlementOnTerminate,
+		Value: "false",
+		Name:  data.SettingSettlementOnTerminate,
+	}
+	env.insertToTestDB(t, onTerminate)
+	defer env.deleteFromTestDB(t, onTerminate)
+
+	fixture.Channel.ReceiptBalance = 1
+	env.updateInTestDB(t, fixture.Channel)
+
+	runJob(t, env.worker.AgentPreServiceTerminate, fixture.job)
+	testServiceStatusChanged(t, fixture.job, env, data.ServiceTerminated)
+
+	if len(env.ethBack.CallStack) != 0 {
+		t.Fatal("cooperative close must be left to settlement policies")
+	}
+}
+
+func TestAgentPreChannelDispute(t *testing.T) {
+	env := newWorkerTest(t)
+	fixture := env.newTestFixture(t, data.JobAgentPreChannelDispute,
+		data.JobChannel)
+	defer env.close()
+	defer fixture.close()
+
+	periodSetting := &data.Setting{
+		Key:   data.SettingsPeriodChallange,
+		Value: "100",
+		Name:  data.SettingsPeriodChallange,
+	}
+	increaseSetting := &data.Setting{
+		Key:   data.SettingDisputeMaxGasIncrease,
+		Value: "200",
+		Name:  data.SettingDisputeMaxGasIncrease,
+	}
+	env.insertToTestDB(t, periodSetting, increaseSetting)
+	defer env.deleteFromTestDB(t, periodSetting, increaseSetting)
+
+	// Channel is not in challenge period.
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+	if len(env.ethBack.CallStack) != 0 {
+		t.Fatal("cooperative close must not be called")
+	}
+
+	fixture.Channel.ChannelStatus = data.ChannelInChallenge
+	fixture.Channel.ReceiptBalance = 1
+	env.updateInTestDB(t, fixture.Channel)
+
+	env.ethBack.BlockNumber = big.NewInt(100)
+	env.ethBack.SettleBlock = 150
+
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+
+	closeTx := &data.EthTx{}
+	env.selectOneTo(t, closeTx, "WHERE related_id=$1 AND method=$2",
+		fixture.Channel.ID, "CooperativeClose")
+	defer env.deleteFromTestDB(t, closeTx)
+
+	// Half of challenge period is passed on the next block, so gas price
+	// is increased by about a half of the maximum increase.
+	env.ethBack.TxIsPending = true
+
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+
+	increase := &data.Job{}
+	env.selectOneTo(t, increase, "WHERE type=$1 AND related_id=$2",
+		data.JobIncreaseTxGasPrice, closeTx.ID)
+	env.deleteFromTestDB(t, increase)
+
+	var jdata data.JobPublishData
+	if err := json.Unmarshal(increase.Data, &jdata); err != nil {
+		t.Fatal(err)
+	}
+	if exp := env.ethBack.GasPrice.Uint64() * 202 / 100; jdata.GasPrice != exp {
+		t.Fatalf("wrong increased gas price: %d, expected: %d",
+			jdata.GasPrice, exp)
+	}
+
+	// Cooperative close is mined.
+	env.ethBack.TxIsPending = false
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+	env.jobNotCreated(t, closeTx.ID, data.JobIncreaseTxGasPrice)
+
+	// Cooperative close is mined but failed, so it is submitted again.
+	env.ethBack.TxFailed = true
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+	env.ethBack.TxFailed = false
+
+	resentTx := &data.EthTx{}
+	env.selectOneTo(t, resentTx,
+		"WHERE related_id=$1 AND method=$2 AND id<>$3",
+		fixture.Channel.ID, "CooperativeClose", closeTx.ID)
+	env.deleteFromTestDB(t, resentTx)
+
+	// Challenge period is over.
+	env.ethBack.SettleBlock = 1
+	runJob(t, env.worker.AgentPreChannelDispute, fixture.job)
+	if fixture.job.Status != data.JobCanceled {
+		t.Fatal("job must be canceled")
+	}
+
+	testCommonErrors(t, env.worker.AgentPreChannelDispute, *fixture.job)
+}
+
 func testCooperativeCloseCalled(t *testing.T, env *workerTest,
 	fixture *workerTestFixture) {
 	// Test eth transaction was recorder.
@@ -373,6 +508,48 @@ func TestAgentPreEndpointMsgCreate(t *testing.T) {
 	testCommonErrors(t, env.worker.AgentPreEndpointMsgCreate, *fxt.job)
 }
 
+func TestAgentPreEndpointMsgRotate(t *testing.T) {
+	env := newWorkerTest(t)
+	fxt := env.newTestFixture(t, data.JobAgentPreEndpointMsgRotate,
+		data.JobChannel)
+	defer env.close()
+	defer fxt.close()
+
+	fxt.Channel.ServiceStatus = data.ServicePending
+	env.updateInTestDB(t, fxt.Channel)
+
+	err := env.worker.AgentPreEndpointMsgRotate(fxt.job)
+	util.TestExpectResult(t, "AgentPreEndpointMsgRotate",
+		ErrInvalidServiceStatus, err)
+
+	fxt.Channel.ServiceStatus = data.ServiceActive
+	env.updateInTestDB(t, fxt.Channel)
+
+	runJob(t, env.worker.AgentPreEndpointMsgRotate, fxt.job)
+
+	endpoint := &data.Endpoint{}
+	env.findTo(t, endpoint, fxt.Endpoint.ID)
+	if endpoint.RawMsg == fxt.Endpoint.RawMsg ||
+		endpoint.Hash == fxt.Endpoint.Hash {
+		t.Fatal("endpoint message is not re-issued")
+	}
+
+	rawMsgBytes := data.TestToBytes(t, endpoint.RawMsg)
+	expectedHash := ethcrypto.Keccak256(rawMsgBytes)
+	if data.HexFromBytes(expectedHash) != endpoint.Hash {
+		t.Fatal("wrong hash stored")
+	}
+
+	channel := &data.Channel{}
+	env.findTo(t, channel, fxt.Channel.ID)
+	if channel.Password == fxt.Channel.Password ||
+		channel.Salt == fxt.Channel.Salt {
+		t.Fatal("password is not changed in channel")
+	}
+
+	testCommonErrors(t, env.worker.AgentPreEndpointMsgRotate, *fxt.job)
+}
+
 func TestAgentPreOfferingMsgBCPublish(t *testing.T) {
 	env := newWorkerTest(t)
 	fixture := env.newTestFixture(t, data.JobAgentPreOfferingMsgBCPublish,
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
 	"github.com/AlekSi/pointer"
@@ -16,13 +15,17 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"gopkg.in/reform.v1"
 
+	"github.com/privatix/dappctrl/agent/somcsrv"
+	"github.com/privatix/dappctrl/client/budget"
 	"github.com/privatix/dappctrl/client/somc"
-	"github.com/privatix/dappctrl/country"
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/eth"
+	"github.com/privatix/dappctrl/job"
 	"github.com/privatix/dappctrl/messages"
 	"github.com/privatix/dappctrl/messages/ept"
 	"github.com/privatix/dappctrl/messages/offer"
+	"github.com/privatix/dappctrl/pay"
+	"github.com/privatix/dappctrl/proc"
 	"github.com/privatix/dappctrl/util"
 	"github.com/privatix/dappctrl/util/log"
 )
@@ -142,6 +145,8 @@ type ClientPreChannelCreateData struct {
 	Offering string `json:"offering"`
 	GasPrice uint64 `json:"gasPrice"`
 	Deposit  uint64 `json:"deposit"`
+	// Failover is a channel replaced by the channel being created.
+	Failover string `json:"failover,omitempty"`
 }
 
 // ClientPreChannelCreate triggers a channel creation.
@@ -165,6 +170,11 @@ func (w *Worker) ClientPreChannelCreate(job *data.Job) error {
 
 	logger = logger.Add("account", acc, "offering", offering)
 
+	if offering.ExpiresAt != nil && !time.Now().Before(*offering.ExpiresAt) {
+		logger.Warn("offering is expired")
+		return ErrOfferingExpired
+	}
+
 	deposit := jdata.Deposit
 	if jdata.Deposit == 0 {
 		deposit = data.ComputePrice(offering, offering.MinUnits)
@@ -195,10 +205,8 @@ func (w *Worker) ClientPreChannelCreate(job *data.Job) error {
 		return ErrInternal
 	}
 
-	msgRaw, _ := messages.UnpackSignature(msgRawBytes)
-
-	msg := offer.Message{}
-	if err := json.Unmarshal(msgRaw, &msg); err != nil {
+	msg, _, err := offer.Unpack(msgRawBytes)
+	if err != nil {
 		logger.Error(err.Error())
 		return ErrInternal
 	}
@@ -263,44 +271,29 @@ func (w *Worker) ClientAfterChannelCreate(job *data.Job) error {
 
 	logger = logger.Add("channel", ch, "ethLog", ethLog)
 
+	offering, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return err
+	}
+
 	ch.Block = uint32(ethLog.Block)
 	ch.ChannelStatus = data.ChannelActive
-	if err = w.saveRecord(logger, w.db.Querier, ch); err != nil {
-		return err
+	if err := data.SetChannelKey(ch, offering.Hash); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
 	}
 
-	key, err := w.keyFromChannelData(logger, ch.ID)
-	if err != nil {
+	if err = w.saveRecord(logger, w.db.Querier, ch); err != nil {
 		return err
 	}
 
-	logger = logger.Add("endpointKey", key)
-
-	var endpointMsgSealed []byte
+	logger = logger.Add("endpointKey", *ch.Key)
 
-	offering, err := w.offering(logger, ch.Offering)
+	endpointMsgSealed, err := w.fetchEndpointMessage(logger, ch, offering)
 	if err != nil {
 		return err
 	}
 
-	if err == nil {
-		client, err := w.somcClientBuilder.NewClient(offering.SOMCType, offering.SOMCData)
-		if err != nil {
-			logger.Error(err.Error())
-			return ErrGetEndpoint
-		}
-		rawMsg, err := client.Endpoint(key)
-		if err != nil {
-			logger.Error(err.Error())
-			return ErrGetEndpoint
-		}
-		endpointMsgSealed, err = data.ToBytes(rawMsg)
-		if err != nil {
-			logger.Error(err.Error())
-			return ErrGetEndpoint
-		}
-	}
-
 	err = w.addJobWithData(logger, nil, data.JobClientEndpointGet,
 		data.JobChannel, ch.ID, &data.JobEndpointCreateData{EndpointSealed: endpointMsgSealed})
 	if err != nil {
@@ -316,6 +309,50 @@ func (w *Worker) ClientAfterChannelCreate(job *data.Job) error {
 		data.JobAccountUpdateBalances, data.JobAccount, client.ID)
 }
 
+// fetchEndpointMessage requests a sealed endpoint message of a channel from
+// its agent.
+func (w *Worker) fetchEndpointMessage(logger log.Logger, ch *data.Channel,
+	offering *data.Offering) ([]byte, error) {
+	key := *ch.Key
+
+	client, err := w.somcClientBuilder.NewClient(
+		offering.SOMCType, offering.SOMCData)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrGetEndpoint
+	}
+
+	acc, err := w.account(logger, ch.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	prvKey, err := w.key(logger, acc)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := somcsrv.NewEndpointAuth(key, prvKey, time.Now())
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	rawMsg, err := client.Endpoint(key, auth)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrGetEndpoint
+	}
+
+	sealed, err := data.ToBytes(rawMsg)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrGetEndpoint
+	}
+
+	return sealed, nil
+}
+
 func (w *Worker) extractEndpointMessage(logger log.Logger,
 	ch *data.Channel, sealed []byte) (*ept.Message, error) {
 	client, err := w.account(logger, ch.Client)
@@ -339,16 +376,16 @@ func (w *Worker) extractEndpointMessage(logger log.Logger,
 		return nil, err
 	}
 
-	mdata, err := messages.ClientOpen(sealed, pub, key)
+	env, mdata, err := messages.ClientOpenEnvelope(
+		sealed, messages.TypeEndpoint, pub, key)
 	if err != nil {
 		logger.Error(err.Error())
 		return nil, ErrDecryptEndpointMsg
 	}
 
-	var msg ept.Message
-	err = json.Unmarshal(mdata, &msg)
+	msg, err := ept.Decode(env.Version, mdata)
 	if err != nil {
-		logger.Error(err.Error())
+		logger.Add("version", env.Version).Error(err.Error())
 		return nil, ErrInternal
 	}
 
@@ -361,7 +398,7 @@ func (w *Worker) extractEndpointMessage(logger log.Logger,
 		return nil, ErrInvalidEndpoint
 	}
 
-	return &msg, nil
+	return msg, nil
 }
 
 // ClientEndpointGet decodes endpoint message, saves it in the DB and
@@ -391,13 +428,9 @@ func (w *Worker) ClientEndpointGet(job *data.Job) error {
 		return err
 	}
 
-	url := strings.Replace(w.countryConfig.URLTemplate,
-		"{{ip}}", msg.ServiceEndpointAddress, 1)
-
 	var countryStatus string
 
-	c, err := country.GetCountry(w.countryConfig.Timeout, url,
-		w.countryConfig.Field)
+	c, err := w.country.Country(msg.ServiceEndpointAddress)
 	if err != nil || len(c) != 2 {
 		countryStatus = data.CountryStatusUnknown
 	} else if c == offer.Country {
@@ -408,7 +441,7 @@ func (w *Worker) ClientEndpointGet(job *data.Job) error {
 
 	params, _ := json.Marshal(msg.AdditionalParams)
 
-	return w.db.InTransaction(func(tx *reform.TX) error {
+	err = w.db.InTransaction(func(tx *reform.TX) error {
 		raddr := pointer.ToString(msg.PaymentReceiverAddress)
 		saddr := pointer.ToString(msg.ServiceEndpointAddress)
 		endp := data.Endpoint{
@@ -429,6 +462,11 @@ func (w *Worker) ClientEndpointGet(job *data.Job) error {
 			return ErrInternal
 		}
 
+		if err := w.addJob(logger, tx, data.JobClientVerifyEndpoint,
+			data.JobEndpoint, endp.ID); err != nil {
+			return err
+		}
+
 		ch.ServiceStatus = data.ServiceSuspended
 		changedTime := time.Now()
 		ch.ServiceChangedTime = &changedTime
@@ -442,6 +480,260 @@ func (w *Worker) ClientEndpointGet(job *data.Job) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return w.activateFailoverChannel(logger, ch)
+}
+
+// ClientEndpointRefresh requests an endpoint message of a channel from its
+// agent again and updates a channel endpoint, if the agent has re-issued it
+// with new access credentials.
+func (w *Worker) ClientEndpointRefresh(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientEndpointRefresh", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientEndpointRefresh)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.Add("channel", ch)
+
+	if ch.Key == nil {
+		logger.Warn(ErrEndpointNotFound.Error())
+		return ErrEndpointNotFound
+	}
+
+	endp, err := w.endpoint(logger, ch.ID)
+	if err != nil {
+		return err
+	}
+
+	offer, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := w.fetchEndpointMessage(logger, ch, offer)
+	if err != nil {
+		return err
+	}
+
+	rawMsg := data.FromBytes(sealed)
+	if rawMsg == endp.RawMsg {
+		logger.Info("endpoint message is not changed")
+		return nil
+	}
+
+	msg, err := w.extractEndpointMessage(logger, ch, sealed)
+	if err != nil {
+		return err
+	}
+
+	params, _ := json.Marshal(msg.AdditionalParams)
+
+	endp.Hash = msg.TemplateHash
+	endp.RawMsg = rawMsg
+	endp.PaymentReceiverAddress = pointer.ToString(msg.PaymentReceiverAddress)
+	endp.ServiceEndpointAddress = pointer.ToString(msg.ServiceEndpointAddress)
+	endp.Username = pointer.ToString(msg.Username)
+	endp.Password = pointer.ToString(msg.Password)
+	endp.AdditionalParams = params
+
+	logger.Info("endpoint message is re-issued by agent")
+
+	return w.saveRecord(logger, w.db.Querier, endp)
+}
+
+// ClientVerifyEndpoint verifies an ip type of a service endpoint address
+// against an offering. An agent rating is reduced, if a country or an ip
+// type of the endpoint doesn't match the offering. A channel is closed in
+// this case, if it's enabled in settings.
+func (w *Worker) ClientVerifyEndpoint(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientVerifyEndpoint", "job", job)
+
+	endp, err := w.relatedEndpoint(logger, job, data.JobClientVerifyEndpoint)
+	if err != nil {
+		return err
+	}
+
+	ch, err := w.channel(logger, endp.Channel)
+	if err != nil {
+		return err
+	}
+
+	offer, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return err
+	}
+
+	endp.IPTypeStatus = pointer.ToString(
+		w.ipTypeStatus(logger, endp, offer))
+	if err := w.saveRecord(logger, w.db.Querier, endp); err != nil {
+		return err
+	}
+
+	if (endp.CountryStatus == nil ||
+		*endp.CountryStatus != data.CountryStatusInvalid) &&
+		*endp.IPTypeStatus != data.CountryStatusInvalid {
+		return nil
+	}
+
+	logger.Add("countryStatus", endp.CountryStatus,
+		"ipTypeStatus", endp.IPTypeStatus).Warn(
+		"endpoint doesn't match offering")
+
+	if err := w.penalizeAgent(logger, ch.Agent); err != nil {
+		return err
+	}
+
+	autoClose, err := data.ReadBoolSetting(w.db.Querier,
+		data.SettingClientVerifyAutoClose)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	if !autoClose {
+		return nil
+	}
+
+	// The agent is not expected to close the channel cooperatively.
+	return w.clientCloseChannel(logger, ch, true)
+}
+
+func (w *Worker) ipTypeStatus(logger log.Logger,
+	endp *data.Endpoint, offer *data.Offering) string {
+	if w.ipType == nil || endp.ServiceEndpointAddress == nil {
+		return data.CountryStatusUnknown
+	}
+
+	ipType, err := w.ipType.IPType(*endp.ServiceEndpointAddress)
+	if err != nil {
+		logger.Warn(err.Error())
+		return data.CountryStatusUnknown
+	}
+
+	if ipType != offer.IPType {
+		return data.CountryStatusInvalid
+	}
+
+	return data.CountryStatusValid
+}
+
+// penalizeAgent reduces current rating of an agent. The penalty is also
+// applied on every ratings update, see worker.updateRatings.
+func (w *Worker) penalizeAgent(logger log.Logger, agent data.HexString) error {
+	penalty, err := w.verifyRatingPenalty(logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.db.Exec(`UPDATE ratings SET val = val * (100 - $1) / 100
+				WHERE eth_addr = $2`, penalty, agent)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (w *Worker) verifyRatingPenalty(logger log.Logger) (uint64, error) {
+	penalty, err := data.ReadUint64Setting(w.db.Querier,
+		data.SettingClientVerifyRatingPenalty)
+	if err != nil {
+		logger.Warn(err.Error())
+		return 0, nil
+	}
+
+	if penalty > 100 {
+		penalty = 100
+	}
+
+	return penalty, nil
+}
+
+// applyVerificationPenalties reduces ratings of agents by penalties for
+// endpoints not matching offerings.
+func (w *Worker) applyVerificationPenalties(logger log.Logger,
+	rating map[data.HexString]uint64) error {
+	penalty, err := w.verifyRatingPenalty(logger)
+	if err != nil || penalty == 0 {
+		return err
+	}
+
+	rows, err := w.db.Query(`
+		SELECT channels.agent, count(*)
+		  FROM endpoints
+		  JOIN channels ON channels.id = endpoints.channel
+		 WHERE endpoints.country_status = $1
+		       OR endpoints.ip_type_status = $1
+		 GROUP BY channels.agent`, data.CountryStatusInvalid)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var agent data.HexString
+		var mismatches int
+		if err := rows.Scan(&agent, &mismatches); err != nil {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
+
+		val, ok := rating[agent]
+		if !ok {
+			continue
+		}
+		for ; mismatches > 0 && val > 0; mismatches-- {
+			val = val * (100 - penalty) / 100
+		}
+		rating[agent] = val
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// activateFailoverChannel activates a channel created by failover, so that
+// the service adapter is notified to connect to the new endpoint.
+func (w *Worker) activateFailoverChannel(logger log.Logger,
+	ch *data.Channel) error {
+	var j data.Job
+	err := w.db.SelectOneTo(&j, "WHERE related_id = $1 AND type = $2",
+		ch.ID, data.JobClientPreChannelCreate)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error(err.Error())
+		}
+		return nil
+	}
+
+	var jdata ClientPreChannelCreateData
+	if err := json.Unmarshal(j.Data, &jdata); err != nil ||
+		jdata.Failover == "" {
+		return nil
+	}
+
+	logger.Info("activating failover channel, replaced channel: " +
+		jdata.Failover)
+
+	// Processor is not used here, as it refuses to activate a channel
+	// having active jobs, this one included.
+	err = job.AddSimple(w.queue, nil, data.JobClientPreServiceUnsuspend,
+		data.JobChannel, ch.ID, data.JobTask)
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+		return ErrAddJob
+	}
+	return nil
 }
 
 // ClientAfterUncooperativeClose changed channel status
@@ -678,8 +970,16 @@ func (w *Worker) ClientPreUncooperativeClose(job *data.Job) error {
 		return err
 	}
 	if blocks > 0 {
+		// Reschedule the close to the end of the challenge period.
 		logger.Add("blocksTillChallangeEnd", blocks).Warn("in challange period")
-		return ErrChallengePeriodIsNotOver
+		job.Status = data.JobCanceled
+		if err := w.db.Save(job); err != nil {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
+		return w.addJobWithDelay(logger, nil,
+			data.JobClientPreUncooperativeClose, data.JobChannel,
+			ch.ID, time.Duration(blocks)*eth.BlockDuration)
 	}
 
 	tx, err := w.settle(ctx, logger, acc, agent, ch.Block, offerHash)
@@ -790,6 +1090,190 @@ func (w *Worker) ClientPreChannelTopUp(job *data.Job) error {
 		jdata.GasPrice, uint64(jdata.Deposit))
 }
 
+// ClientAskChannelTopUp asks user to top up a channel. User is notified
+// through subscription to the channel changes and decides whether to top up.
+func (w *Worker) ClientAskChannelTopUp(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientAskChannelTopUp", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientAskChannelTopUp)
+	if err != nil {
+		return err
+	}
+
+	var jdata data.JobTopUpChannelData
+	if err := w.unmarshalDataTo(logger, job.Data, &jdata); err != nil {
+		return err
+	}
+
+	logger.Add("channel", ch, "deposit", jdata.Deposit).Info(
+		"asking user to top up channel")
+
+	return nil
+}
+
+// ClientBudgetWarning warns user that spending of an account reaches
+// a threshold of its budgets. User is notified through subscription
+// to the account changes.
+func (w *Worker) ClientBudgetWarning(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientBudgetWarning", "job", job)
+
+	acc, err := w.accountByPK(logger, job.RelatedID)
+	if err != nil {
+		return err
+	}
+
+	var jdata data.JobBudgetWarningData
+	if err := w.unmarshalDataTo(logger, job.Data, &jdata); err != nil {
+		return err
+	}
+
+	for _, v := range jdata.Warnings {
+		logger.Add("account", acc.EthAddr, "budget", v.Budget,
+			"spent", v.Spent, "threshold", v.Threshold).Warn(
+			"spending budget threshold reached")
+	}
+
+	return nil
+}
+
+// ClientAgentVanished requests uncooperative close of a channel, which agent
+// stopped to respond. User is notified through subscription to the channel
+// changes.
+func (w *Worker) ClientAgentVanished(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientAgentVanished", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientAgentVanished)
+	if err != nil {
+		return err
+	}
+
+	var jdata data.JobAgentVanishedData
+	if err := w.unmarshalDataTo(logger, job.Data, &jdata); err != nil {
+		return err
+	}
+
+	logger.Add("channel", ch.ID, "agent", ch.Agent,
+		"failures", jdata.Failures, "since", jdata.Since).Warn(
+		"agent vanished, closing channel uncooperatively")
+
+	return w.clientCloseChannel(logger, ch, true)
+}
+
+// ClientVerifyUsage asks an agent whether it suspended a service and
+// compares units the agent charges with units measured by a client adapter.
+// Discrepancies exceeding a tolerance are reported to user.
+func (w *Worker) ClientVerifyUsage(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientVerifyUsage", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientVerifyUsage)
+	if err != nil {
+		return err
+	}
+
+	offer, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return err
+	}
+
+	if offer.UnitPrice == 0 || offer.BillingInterval == 0 {
+		logger.Debug("units are not billed")
+		return nil
+	}
+
+	key, err := w.accountKey(logger, ch.Client)
+	if err != nil {
+		return err
+	}
+
+	res, err := pay.RequestSuspension(w.db, ch,
+		data.HexFromBytes(w.pscAddr.Bytes()), key)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrRequestSuspension
+	}
+
+	if !res.ServiceSuspended {
+		logger.Debug("service is not suspended by agent")
+		return nil
+	}
+
+	var measured uint64
+	if err := w.db.QueryRow(`
+		SELECT COALESCE(sum(units_used), 0)
+		  FROM sessions
+		 WHERE channel = $1`, ch.ID).Scan(&measured); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	// Free units granted by the agent are not billed.
+	measured = unitsAbove(measured, res.FreeUnits)
+	charged := unitsAbove(res.UnitsUsed, res.FreeUnits)
+
+	var paid uint64
+	if ch.ReceiptBalance > offer.SetupPrice {
+		paid = (ch.ReceiptBalance - offer.SetupPrice) / offer.UnitPrice
+	}
+
+	interval := uint64(offer.BillingInterval)
+	lag := uint64(offer.MaxBillingUnitLag)
+	if measured/interval > paid+lag {
+		logger.Debug("suspension is explained by measured usage")
+		return nil
+	}
+
+	tolerance, err := data.ReadUintSetting(w.db.Querier,
+		data.SettingClientUsageTolerance)
+	if err != nil {
+		logger.Warn(err.Error())
+		tolerance = 10
+	}
+
+	if charged <= measured ||
+		(charged-measured)*100 <= measured*uint64(tolerance) {
+		return nil
+	}
+
+	logger.Add("measured", measured, "charged", charged).Warn(
+		"agent charged more units than measured")
+
+	return w.addJobWithData(logger, nil, data.JobClientUsageDiscrepancy,
+		data.JobChannel, ch.ID, &data.JobUsageDiscrepancyData{
+			Measured: measured,
+			Charged:  charged,
+		})
+}
+
+func unitsAbove(units, free uint64) uint64 {
+	if units <= free {
+		return 0
+	}
+	return units - free
+}
+
+// ClientUsageDiscrepancy notifies user that an agent charged more units
+// than a client measured. User is notified through subscription to the
+// channel changes.
+func (w *Worker) ClientUsageDiscrepancy(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientUsageDiscrepancy", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientUsageDiscrepancy)
+	if err != nil {
+		return err
+	}
+
+	var jdata data.JobUsageDiscrepancyData
+	if err := w.unmarshalDataTo(logger, job.Data, &jdata); err != nil {
+		return err
+	}
+
+	logger.Add("channel", ch.ID, "agent", ch.Agent,
+		"measured", jdata.Measured, "charged", jdata.Charged).Warn(
+		"usage discrepancy detected")
+
+	return nil
+}
+
 // ClientAfterChannelTopUp updates deposit of a channel.
 func (w *Worker) ClientAfterChannelTopUp(job *data.Job) error {
 	return w.afterChannelTopUp(job, data.JobClientAfterChannelTopUp)
@@ -905,15 +1389,73 @@ func (w *Worker) ClientAfterUncooperativeCloseRequest(job *data.Job) error {
 		return ErrInternal
 	}
 
-	challengePeriod, err := data.ReadUintSetting(w.db.Querier,
-		data.SettingsPeriodChallange)
+	delay, err := w.challengeDelay(logger, ch)
 	if err != nil {
 		return err
 	}
 
+	logger.Add("delay", delay).Info(
+		"uncooperative close scheduled to the end of challenge period")
+
 	return w.addJobWithDelay(logger, nil,
 		data.JobClientPreUncooperativeClose, data.JobChannel,
-		ch.ID, time.Duration(challengePeriod)*eth.BlockDuration)
+		ch.ID, delay)
+}
+
+// challengeDelay returns time till the end of a channel challenge period.
+// The period is taken from the blockchain, the challenge period setting is
+// used when the blockchain is not available.
+func (w *Worker) challengeDelay(
+	logger log.Logger, ch *data.Channel) (time.Duration, error) {
+	blocks, err := w.channelChallengeBlocks(logger, ch)
+	if err != nil {
+		logger.Warn(err.Error())
+
+		period, err := data.ReadUintSetting(w.db.Querier,
+			data.SettingsPeriodChallange)
+		if err != nil {
+			return 0, err
+		}
+		blocks = int64(period)
+	}
+
+	if blocks < 0 {
+		blocks = 0
+	}
+
+	return time.Duration(blocks) * eth.BlockDuration, nil
+}
+
+func (w *Worker) channelChallengeBlocks(
+	logger log.Logger, ch *data.Channel) (int64, error) {
+	agent, err := data.HexToAddress(ch.Agent)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, ErrParseEthAddr
+	}
+
+	client, err := data.HexToAddress(ch.Client)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, ErrParseEthAddr
+	}
+
+	offer, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return 0, err
+	}
+
+	offerHash, err := data.HexToHash(offer.Hash)
+	if err != nil {
+		logger.Error(err.Error())
+		return 0, ErrParseOfferingHash
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	return w.blocksTillChallangeEnd(
+		ctx, logger, client, agent, ch.Block, offerHash)
 }
 
 // ClientAfterOfferingMsgBCPublish creates offering.
@@ -1015,7 +1557,8 @@ func (w *Worker) clientRetrieveAndSaveOffering(logger log.Logger,
 		data.HexFromBytes(hash.Bytes()), job.RelatedID,
 		somcType, somcData)
 	if err != nil {
-		if err == ErrTemplateByHashNotFound || err == ErrOfferingNotActive || err == ErrOfferingExists {
+		if err == ErrTemplateByHashNotFound || err == ErrOfferingNotActive ||
+			err == ErrOfferingExists || err == ErrOfferingExpired {
 			job.Status = data.JobCanceled
 			w.db.Save(job)
 			return nil
@@ -1075,11 +1618,9 @@ func (w *Worker) fillOfferingFromMsg(logger log.Logger, offering []byte,
 		return nil, ErrOfferingNotActive
 	}
 
-	msgRaw, sig := messages.UnpackSignature(offering)
-
-	msg := offer.Message{}
-	if err := json.Unmarshal(msgRaw, &msg); err != nil {
-		logger.Add("msgRaw", msgRaw).Error(err.Error())
+	msg, env, err := offer.Unpack(offering)
+	if err != nil {
+		logger.Error(err.Error())
 		return nil, ErrInternal
 	}
 
@@ -1091,17 +1632,28 @@ func (w *Worker) fillOfferingFromMsg(logger log.Logger, offering []byte,
 		return nil, ErrInternal
 	}
 
-	if !messages.VerifySignature(pubk, crypto.Keccak256(msgRaw), sig) {
+	if !env.Verify(pubk) {
 		return nil, ErrWrongOfferingMsgSignature
 	}
 
+	if msg.Expired(time.Now()) {
+		logger.Warn("offering is expired")
+		return nil, ErrOfferingExpired
+	}
+
+	var expiresAt *time.Time
+	if msg.Expiry != 0 {
+		expiry := time.Unix(msg.Expiry, 0)
+		expiresAt = &expiry
+	}
+
 	template, err := w.templateByHash(logger, msg.TemplateHash)
 	if err != nil {
 		return nil, err
 	}
 
 	// Validate offering JSON compliant with offering template JSON
-	if !offer.ValidMsg(template.Raw, msg) {
+	if !offer.ValidMsg(template.Raw, *msg) {
 		return nil, ErrOfferNotCorrespondToTemplate
 	}
 
@@ -1142,6 +1694,7 @@ func (w *Worker) fillOfferingFromMsg(logger log.Logger, offering []byte,
 		AdditionalParams:   msg.ServiceSpecificParameters,
 		SOMCType:           somcType,
 		SOMCData:           somcData,
+		ExpiresAt:          expiresAt,
 	}, nil
 }
 
@@ -1313,6 +1866,10 @@ func (w *Worker) updateRatings(logger log.Logger) error {
 		from, to = to, from
 	}
 
+	if err := w.applyVerificationPenalties(logger, rating); err != nil {
+		return err
+	}
+
 	return w.db.InTransaction(func(tx *reform.TX) error {
 		if _, err := tx.Exec("DELETE FROM ratings"); err != nil {
 			logger.Error(err.Error())
@@ -1385,3 +1942,143 @@ func getInitialRate(events []closingEvent) uint64 {
 	// geometric_progression_sum * ratio_of_coops_over_uncoops
 	return uint64((2*amount - 2*amount/float64(uint(2)<<(uint(len(events))-1))) * nCoop / float64(len(events)))
 }
+
+// ClientPreChannelFailover closes a failed channel and accepts the next best
+// matching offering instead of it.
+func (w *Worker) ClientPreChannelFailover(job *data.Job) error {
+	logger := w.logger.Add("method", "ClientPreChannelFailover", "job", job)
+
+	ch, err := w.relatedChannel(logger, job, data.JobClientPreChannelFailover)
+	if err != nil {
+		return err
+	}
+
+	offering, err := w.offering(logger, ch.Offering)
+	if err != nil {
+		return err
+	}
+
+	acc, err := w.account(logger, ch.Client)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.Add("channel", ch, "offering", offering)
+
+	if err := w.clientFailoverClose(logger, ch); err != nil {
+		return err
+	}
+
+	alt, err := w.alternativeOffering(logger, offering)
+	if err != nil {
+		return err
+	}
+
+	// The deposit is set explicitly to be counted in spending budgets.
+	deposit := data.ComputePrice(alt, alt.MinUnits)
+	err = budget.Check(logger, w.db, w.queue, acc.ID, deposit, data.JobTask)
+	if err == budget.ErrBudgetExceeded {
+		logger.Warn("alternative offering exceeds spending budget")
+		return nil
+	}
+	if err != nil {
+		return ErrInternal
+	}
+
+	logger.Info("accepting alternative offering: " + alt.ID)
+
+	jdata := &ClientPreChannelCreateData{
+		Account:  acc.ID,
+		Offering: alt.ID,
+		Deposit:  deposit,
+		Failover: ch.ID,
+	}
+	return w.addJobWithData(logger, nil, data.JobClientPreChannelCreate,
+		data.JobChannel, util.NewUUID(), jdata)
+}
+
+func (w *Worker) clientFailoverClose(logger log.Logger, ch *data.Channel) error {
+	uncoop, err := data.ReadBoolSetting(w.db.Querier,
+		data.SettingClientFailoverUncooperative)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+
+	return w.clientCloseChannel(logger, ch, uncoop)
+}
+
+// clientCloseChannel terminates a service of a channel. If uncoop is false,
+// the agent is expected to close the channel cooperatively.
+func (w *Worker) clientCloseChannel(logger log.Logger,
+	ch *data.Channel, uncoop bool) error {
+	if ch.ChannelStatus != data.ChannelActive {
+		return nil
+	}
+
+	_, err := w.processor.TerminateChannel(ch.ID, data.JobTask, false)
+	if err != nil && err != proc.ErrBadServiceStatus &&
+		err != proc.ErrSameJobExists {
+		logger.Error(err.Error())
+		return ErrTerminateChannel
+	}
+
+	if !uncoop {
+		// Waiting for the agent to close the channel cooperatively.
+		return nil
+	}
+
+	err = job.AddWithData(w.queue, nil,
+		data.JobClientPreUncooperativeCloseRequest, data.JobChannel,
+		ch.ID, data.JobTask, &data.JobPublishData{})
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+		return ErrAddJob
+	}
+	return nil
+}
+
+// alternativeOffering finds the best active offering with the same product,
+// country and IP type as a given one, which is not more expensive than
+// the failover price ceiling.
+func (w *Worker) alternativeOffering(logger log.Logger,
+	offering *data.Offering) (*data.Offering, error) {
+	maxPrice, err := data.ReadUint64Setting(w.db.Querier,
+		data.SettingClientFailoverMaxPrice)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+	if maxPrice == 0 {
+		maxPrice = offering.UnitPrice
+	}
+
+	var alt data.Offering
+	err = w.db.SelectOneTo(&alt, `
+		LEFT JOIN ratings ON ratings.eth_addr = offerings.agent
+		WHERE offerings.status IN ('registered', 'popped_up')
+		  AND NOT offerings.is_local
+		  AND offerings.current_supply > 0
+		  AND offerings.agent NOT IN (SELECT eth_addr FROM accounts)
+		  AND offerings.id != $1
+		  AND offerings.agent != $2
+		  AND offerings.product = $3
+		  AND offerings.country = $4
+		  AND offerings.ip_type = $5
+		  AND offerings.unit_price <= $6
+		  AND (offerings.expires_at IS NULL
+		       OR offerings.expires_at > now())
+		ORDER BY COALESCE(ratings.val, 0) DESC,
+		      offerings.unit_price,
+		      offerings.block_number_updated DESC
+		LIMIT 1`, offering.ID, offering.Agent, offering.Product,
+		offering.Country, offering.IPType, maxPrice)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Warn(ErrNoAlternativeOffering.Error())
+			return nil, ErrNoAlternativeOffering
+		}
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	return &alt, nil
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AlekSi/pointer"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 
@@ -18,8 +21,10 @@ import (
 	"github.com/privatix/dappctrl/messages"
 	"github.com/privatix/dappctrl/messages/ept"
 	"github.com/privatix/dappctrl/messages/offer"
+	"github.com/privatix/dappctrl/pay"
 	"github.com/privatix/dappctrl/proc"
 	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/srv"
 )
 
 func TestClientPreChannelCreate(t *testing.T) {
@@ -46,6 +51,14 @@ func TestClientPreChannelCreate(t *testing.T) {
 		Offering: fxt.Offering.ID,
 	})
 
+	expired := time.Now().Add(-time.Minute)
+	fxt.Offering.ExpiresAt = &expired
+	env.updateInTestDB(t, fxt.Offering)
+	util.TestExpectResult(t, "Job run", ErrOfferingExpired,
+		env.worker.ClientPreChannelCreate(fxt.job))
+	fxt.Offering.ExpiresAt = nil
+	env.updateInTestDB(t, fxt.Offering)
+
 	minDeposit := data.ComputePrice(fxt.Offering, fxt.Offering.MinUnits)
 	env.ethBack.BalancePSC = minDeposit - 1
 	util.TestExpectResult(t, "Job run", ErrInsufficientPSCBalance,
@@ -216,6 +229,8 @@ func testClientEndpointGet(t *testing.T,
 		fxt.Channel.ID, fxt.Endpoint.ID)
 	defer data.DeleteFromTestDB(t, db, &endp)
 
+	env.deleteJob(t, data.JobClientVerifyEndpoint, data.JobEndpoint, endp.ID)
+
 	params, _ := json.Marshal(msg.AdditionalParams)
 	if endp.Template != fxt.Offering.Template ||
 		strings.Trim(string(endp.Hash), " ") !=
@@ -254,6 +269,40 @@ func TestClientEndpointGet(t *testing.T) {
 	}
 }
 
+func TestClientEndpointRefresh(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientEndpointRefresh, data.JobChannel)
+	defer fxt.close()
+
+	swapAgentWithClient(t, fxt)
+
+	msg := ept.Message{
+		TemplateHash:           fxt.TemplateAccess.Hash,
+		Username:               fxt.Channel.ID,
+		Password:               "new-password",
+		PaymentReceiverAddress: "1.2.3.4:5678",
+		ServiceEndpointAddress: "test-endpoint-addr",
+	}
+	sealed := sealMessage(t, env, fxt, &msg)
+
+	testClient.V = data.FromBytes(sealed)
+	defer func() { testClient.V = "" }()
+
+	runJob(t, env.worker.ClientEndpointRefresh, fxt.job)
+
+	var endp data.Endpoint
+	env.findTo(t, &endp, fxt.Endpoint.ID)
+	if endp.RawMsg != data.FromBytes(sealed) ||
+		endp.Password == nil || *endp.Password != msg.Password {
+		t.Fatal("endpoint is not updated")
+	}
+
+	testCommonErrors(t, env.worker.ClientEndpointRefresh, *fxt.job)
+}
+
 func TestClientPreChannelTopUp(t *testing.T) {
 	env := newWorkerTest(t)
 	defer env.close()
@@ -470,6 +519,124 @@ func TestClientPreUncooperativeClose(t *testing.T) {
 	}
 }
 
+func TestClientPreUncooperativeCloseInChallenge(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientPreUncooperativeClose, data.JobChannel)
+	defer fxt.close()
+
+	env.ethBack.SettleBlock = 100
+
+	runJob(t, env.worker.ClientPreUncooperativeClose, fxt.job)
+
+	var j data.Job
+	env.selectOneTo(t, &j, "WHERE related_id = $1 AND type = $2"+
+		" AND status = $3", fxt.Channel.ID,
+		data.JobClientPreUncooperativeClose, data.JobActive)
+	defer env.deleteFromTestDB(t, &j)
+
+	if j.ID == fxt.job.ID || !j.NotBefore.After(time.Now()) {
+		t.Fatal("uncooperative close is not rescheduled")
+	}
+
+	var ch data.Channel
+	env.findTo(t, &ch, fxt.Channel.ID)
+	if ch.ChannelStatus == data.ChannelWaitUncoop {
+		t.Fatal("channel settled in challenge period")
+	}
+}
+
+func TestClientAgentVanished(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientAgentVanished, data.JobChannel)
+	defer fxt.close()
+
+	runJob(t, env.worker.ClientAgentVanished, fxt.job)
+
+	env.deleteJob(t, data.JobClientPreServiceTerminate, data.JobChannel,
+		fxt.Channel.ID)
+	env.deleteJob(t, data.JobClientPreUncooperativeCloseRequest,
+		data.JobChannel, fxt.Channel.ID)
+
+	testCommonErrors(t, env.worker.ClientAgentVanished, *fxt.job)
+}
+
+func TestClientVerifyUsage(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientVerifyUsage, data.JobChannel)
+	defer fxt.close()
+
+	var result pay.PaymentResult
+	paySrv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			res, _ := json.Marshal(&result)
+			json.NewEncoder(w).Encode(&srv.Response{Result: res})
+		}))
+	defer paySrv.Close()
+
+	fxt.Offering.SetupPrice = 0
+	fxt.Offering.UnitPrice = 1
+	fxt.Offering.BillingInterval = 1
+	fxt.Offering.MaxBillingUnitLag = 2
+	fxt.Channel.ReceiptBalance = 10
+	fxt.Endpoint.PaymentReceiverAddress = &paySrv.URL
+	env.updateInTestDB(t, fxt.Offering)
+	env.updateInTestDB(t, fxt.Channel)
+	env.updateInTestDB(t, fxt.Endpoint)
+
+	sess := data.NewTestSession(fxt.Channel.ID)
+	sess.UnitsUsed = 10
+	env.insertToTestDB(t, sess)
+	defer env.deleteFromTestDB(t, sess)
+
+	// Service is not suspended by the agent.
+	runJob(t, env.worker.ClientVerifyUsage, fxt.job)
+	env.jobNotCreated(t, fxt.Channel.ID, data.JobClientUsageDiscrepancy)
+
+	// Suspension is explained by measured usage.
+	result = pay.PaymentResult{ServiceSuspended: true, UnitsUsed: 20}
+	sess.UnitsUsed = 20
+	env.updateInTestDB(t, sess)
+	runJob(t, env.worker.ClientVerifyUsage, fxt.job)
+	env.jobNotCreated(t, fxt.Channel.ID, data.JobClientUsageDiscrepancy)
+
+	// Charged units are covered by free units granted by the agent.
+	result = pay.PaymentResult{
+		ServiceSuspended: true, UnitsUsed: 10, FreeUnits: 10}
+	sess.UnitsUsed = 3
+	env.updateInTestDB(t, sess)
+	runJob(t, env.worker.ClientVerifyUsage, fxt.job)
+	env.jobNotCreated(t, fxt.Channel.ID, data.JobClientUsageDiscrepancy)
+
+	result = pay.PaymentResult{ServiceSuspended: true, UnitsUsed: 13}
+	sess.UnitsUsed = 10
+	env.updateInTestDB(t, sess)
+	runJob(t, env.worker.ClientVerifyUsage, fxt.job)
+
+	var j data.Job
+	env.selectOneTo(t, &j, "WHERE related_id = $1 AND type = $2",
+		fxt.Channel.ID, data.JobClientUsageDiscrepancy)
+	defer env.deleteFromTestDB(t, &j)
+
+	var jdata data.JobUsageDiscrepancyData
+	if err := json.Unmarshal(j.Data, &jdata); err != nil {
+		t.Fatal(err)
+	}
+	if jdata.Measured != 10 || jdata.Charged != 13 {
+		t.Fatalf("wrong discrepancy: %+v", jdata)
+	}
+
+	testCommonErrors(t, env.worker.ClientVerifyUsage, *fxt.job)
+}
+
 func TestClientAfterUncooperativeClose(t *testing.T) {
 	env := newWorkerTest(t)
 	defer env.close()
@@ -1020,3 +1187,140 @@ func TestClientRecordClosing(t *testing.T) {
 		}
 	}
 }
+
+func TestClientPreChannelFailover(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientPreChannelFailover, data.JobChannel)
+	defer fxt.close()
+
+	fxt.Channel.ServiceStatus = data.ServiceActive
+	env.updateInTestDB(t, fxt.Channel)
+
+	err := env.worker.ClientPreChannelFailover(fxt.job)
+	util.TestExpectResult(t, "ClientPreChannelFailover",
+		ErrNoAlternativeOffering, err)
+
+	env.deleteJob(t, data.JobClientPreServiceTerminate, data.JobChannel,
+		fxt.Channel.ID)
+
+	agent := data.NewTestUser()
+	alt := data.NewTestOffering(agent.EthAddr,
+		fxt.Product.ID, fxt.TemplateOffer.ID)
+	alt.Status = data.OfferRegistered
+
+	// Cheaper, but expired offering.
+	expired := data.NewTestOffering(agent.EthAddr,
+		fxt.Product.ID, fxt.TemplateOffer.ID)
+	expired.Status = data.OfferRegistered
+	expired.UnitPrice = alt.UnitPrice - 1
+	expired.ExpiresAt = pointer.ToTime(time.Now().Add(-time.Minute))
+
+	env.insertToTestDB(t, agent, alt, expired)
+	defer env.deleteFromTestDB(t, expired, alt, agent)
+
+	limit := &data.SpendingBudget{
+		ID:     util.NewUUID(),
+		Period: data.BudgetLifetime,
+		Amount: data.ComputePrice(alt, alt.MinUnits) - 1,
+	}
+	env.insertToTestDB(t, limit)
+
+	runJob(t, env.worker.ClientPreChannelFailover, fxt.job)
+	env.deleteFromTestDB(t, limit)
+
+	env.deleteJob(t, data.JobClientPreServiceTerminate, data.JobChannel,
+		fxt.Channel.ID)
+	if err := env.db.SelectOneTo(&data.Job{},
+		"WHERE type = $1 AND data->>'failover' = $2",
+		data.JobClientPreChannelCreate, fxt.Channel.ID); err == nil {
+		t.Fatal("failover exceeded spending budget")
+	}
+
+	runJob(t, env.worker.ClientPreChannelFailover, fxt.job)
+
+	env.deleteJob(t, data.JobClientPreServiceTerminate, data.JobChannel,
+		fxt.Channel.ID)
+
+	var job data.Job
+	env.selectOneTo(t, &job, "WHERE type = $1 AND data->>'failover' = $2",
+		data.JobClientPreChannelCreate, fxt.Channel.ID)
+	defer env.deleteFromTestDB(t, &job)
+
+	var jdata ClientPreChannelCreateData
+	if err := json.Unmarshal(job.Data, &jdata); err != nil {
+		t.Fatal(err)
+	}
+
+	if jdata.Offering != alt.ID ||
+		jdata.Deposit != data.ComputePrice(alt, alt.MinUnits) {
+		t.Fatalf("expected %s offering to be accepted, but got %s",
+			alt.ID, jdata.Offering)
+	}
+}
+
+type ipTypeResolverMock string
+
+func (r ipTypeResolverMock) IPType(ip string) (string, error) {
+	return string(r), nil
+}
+
+func TestClientVerifyEndpoint(t *testing.T) {
+	env := newWorkerTest(t)
+	defer env.close()
+
+	fxt := env.newTestFixture(t,
+		data.JobClientVerifyEndpoint, data.JobEndpoint)
+	defer fxt.close()
+
+	fxt.Channel.ServiceStatus = data.ServiceActive
+	fxt.Endpoint.ServiceEndpointAddress = pointer.ToString("1.2.3.4")
+	fxt.Endpoint.CountryStatus = pointer.ToString(data.CountryStatusValid)
+	env.updateInTestDB(t, fxt.Channel)
+	env.updateInTestDB(t, fxt.Endpoint)
+
+	rating := &data.Rating{EthAddr: fxt.Channel.Agent, Val: 100}
+	penalty := &data.Setting{
+		Key:   data.SettingClientVerifyRatingPenalty,
+		Value: "50",
+		Name:  "penalty",
+	}
+	autoClose := &data.Setting{
+		Key:   data.SettingClientVerifyAutoClose,
+		Value: "true",
+		Name:  "autoclose",
+	}
+	env.insertToTestDB(t, rating, penalty, autoClose)
+	defer env.deleteFromTestDB(t, autoClose, penalty, rating)
+
+	check := func(wantedStatus string, wantedRating uint64) {
+		t.Helper()
+		runJob(t, env.worker.ClientVerifyEndpoint, fxt.job)
+
+		env.findTo(t, fxt.Endpoint, fxt.Endpoint.ID)
+		env.findTo(t, rating, string(rating.EthAddr))
+		if fxt.Endpoint.IPTypeStatus == nil ||
+			*fxt.Endpoint.IPTypeStatus != wantedStatus ||
+			rating.Val != wantedRating {
+			t.Fatalf("wanted %s ip type status and %d rating,"+
+				" got %v and %d", wantedStatus, wantedRating,
+				fxt.Endpoint.IPTypeStatus, rating.Val)
+		}
+	}
+
+	// No ip type resolver.
+	check(data.CountryStatusUnknown, 100)
+
+	env.worker.SetIPTypeResolver(ipTypeResolverMock(fxt.Offering.IPType))
+	check(data.CountryStatusValid, 100)
+
+	env.worker.SetIPTypeResolver(ipTypeResolverMock(data.OfferingDatacenter))
+	check(data.CountryStatusInvalid, 50)
+
+	env.deleteJob(t, data.JobClientPreServiceTerminate, data.JobChannel,
+		fxt.Channel.ID)
+	env.deleteJob(t, data.JobClientPreUncooperativeCloseRequest,
+		data.JobChannel, fxt.Channel.ID)
+}
@@ -85,6 +85,9 @@ const (
 	ErrTxNoGasIncrease
 	ErrEthTxIsMined
 	ErrTxNotFound
+	ErrNoAlternativeOffering
+	ErrOfferingExpired
+	ErrRequestSuspension
 )
 
 var errMsgs = errors.Messages{
@@ -167,6 +170,9 @@ var errMsgs = errors.Messages{
 	ErrTxNoGasIncrease:               "gas price must be bigger than before",
 	ErrEthTxIsMined:                  "transaction is mined",
 	ErrTxNotFound:                    "transaction not found",
+	ErrNoAlternativeOffering:         "no alternative offering found",
+	ErrOfferingExpired:               "offering is expired",
+	ErrRequestSuspension:             "could not request service suspension",
 }
 
 func init() {
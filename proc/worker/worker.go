@@ -50,7 +50,8 @@ type Worker struct {
 	queue             job.Queue
 	processor         *proc.Processor
 	ethConfig         *eth.Config
-	countryConfig     *country.Config
+	country           country.Resolver
+	ipType            country.IPTypeResolver
 	torHostName       data.Base64String
 	somcClientBuilder somc.ClientBuilderInterface
 }
@@ -58,7 +59,7 @@ type Worker struct {
 // NewWorker returns new instance of worker.
 func NewWorker(logger log.Logger, db *reform.DB, ethBack eth.Backend,
 	gasConc *GasConf, pscAddr common.Address, payAddr string,
-	pwdGetter data.PWDGetter, countryConf *country.Config, eptConf *ept.Config,
+	pwdGetter data.PWDGetter, country country.Resolver, eptConf *ept.Config,
 	torHostname string, somcClientBuilder somc.ClientBuilderInterface) (*Worker, error) {
 
 	l := logger.Add("type", "proc/worker.Worker")
@@ -83,7 +84,7 @@ func NewWorker(logger log.Logger, db *reform.DB, ethBack eth.Backend,
 		ethBack:           ethBack,
 		pscAddr:           pscAddr,
 		pwdGetter:         pwdGetter,
-		countryConfig:     countryConf,
+		country:           country,
 		torHostName:       data.FromBytes([]byte(torHostname)),
 		somcClientBuilder: somcClientBuilder,
 	}, nil
@@ -94,6 +95,11 @@ func (w *Worker) SetQueue(queue job.Queue) {
 	w.queue = queue
 }
 
+// SetIPTypeResolver sets a resolver used to verify ip types of endpoints.
+func (w *Worker) SetIPTypeResolver(ipType country.IPTypeResolver) {
+	w.ipType = ipType
+}
+
 // SetProcessor sets a processor.
 func (w *Worker) SetProcessor(processor *proc.Processor) {
 	w.processor = processor
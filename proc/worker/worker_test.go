@@ -84,7 +84,8 @@ func newWorkerTest(t *testing.T) *workerTest {
 	testClient = somc.NewTestClient()
 
 	worker, err := NewWorker(logger, db, ethBack, conf.Gas, conf.pscAddr,
-		conf.PayServer.Addr, pwdStorage, conf.Country, conf.EptMsg,
+		conf.PayServer.Addr, pwdStorage,
+		country.NewHTTPResolver(conf.Country), conf.EptMsg,
 		"testhostname", somc.NewTestClientBuilder(testClient))
 	if err != nil {
 		panic(err)
@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"fmt"
+
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/report"
+	"github.com/privatix/dappctrl/report/bugsnag"
+	"github.com/privatix/dappctrl/report/crashdir"
+	"github.com/privatix/dappctrl/report/sentry"
+	"github.com/privatix/dappctrl/report/webhook"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// Reporter backends.
+const (
+	Bugsnag  = "bugsnag"
+	Sentry   = "sentry"
+	Webhook  = "webhook"
+	CrashDir = "crashdir"
+	None     = "none"
+)
+
+// Config is a configuration for a choice of reporter backend. Bugsnag is
+// configured separately.
+type Config struct {
+	Backend  string
+	Sentry   *sentry.Config
+	Webhook  *webhook.Config
+	CrashDir *crashdir.Config
+}
+
+// NewConfig creates a default reporter backend configuration.
+func NewConfig() *Config {
+	return &Config{
+		Backend:  Bugsnag,
+		Sentry:   sentry.NewConfig(),
+		Webhook:  webhook.NewConfig(),
+		CrashDir: crashdir.NewConfig(),
+	}
+}
+
+type nopSender struct{}
+
+func (nopSender) Send(e *report.Event) error { return nil }
+
+// sendRemote checks whether sending error reports to remote servers is
+// allowed in settings.
+func sendRemote(db *reform.DB, logger log.Logger) bool {
+	val, err := data.ReadBoolSetting(db.Querier,
+		data.SettingErrorSendRemote)
+	if err != nil {
+		logger.Add("key", data.SettingErrorSendRemote).Warn(err.Error())
+		return false
+	}
+
+	return val
+}
+
+// NewReporter creates a reporter for a configured backend. Sentry and
+// webhook reporters, as well as Bugsnag, are enabled only if sending error
+// reports to remote servers is allowed in settings. Error reports of all
+// the backends but Bugsnag have account addresses scrubbed.
+func NewReporter(conf *Config, bconf *bugsnag.Config, db *reform.DB,
+	logger bugsnag.Log, version string) (report.Reporter, error) {
+	var sender report.Sender
+	var enable bool
+
+	switch conf.Backend {
+	case Bugsnag, "":
+		return bugsnag.NewClient(bconf, db, logger, version)
+	case Sentry:
+		s, err := sentry.NewSender(conf.Sentry, version)
+		if err != nil {
+			return nil, err
+		}
+		sender, enable = s, sendRemote(db, logger)
+	case Webhook:
+		s, err := webhook.NewSender(conf.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		sender, enable = s, sendRemote(db, logger)
+	case CrashDir:
+		s, err := crashdir.NewSender(conf.CrashDir)
+		if err != nil {
+			return nil, err
+		}
+		sender, enable = s, true
+	case None:
+		sender = nopSender{}
+	default:
+		return nil, fmt.Errorf("unknown reporter backend: %s",
+			conf.Backend)
+	}
+
+	return report.NewClient(sender, enable, version, logger), nil
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"sync"
+)
+
+// Sender sends error reports to a backend.
+type Sender interface {
+	Send(e *Event) error
+}
+
+// Printer prints internal messages of reporters.
+type Printer interface {
+	Printf(format string, v ...interface{})
+}
+
+// Client is a reporter sending error reports with a given sender.
+type Client struct {
+	sender      Sender
+	enable      bool
+	version     string
+	printer     Printer
+	mtx         sync.Mutex
+	panicIgnore bool
+}
+
+var (
+	hunterMtx sync.Mutex
+	hunter    *Client
+)
+
+// NewClient creates a new reporter client. Failures to send reports are
+// printed with a given printer, if it's not nil. The last created client is
+// used by PanicHunter.
+func NewClient(sender Sender, enable bool,
+	version string, printer Printer) *Client {
+	c := &Client{
+		sender:  sender,
+		enable:  enable,
+		version: version,
+		printer: printer,
+	}
+
+	hunterMtx.Lock()
+	hunter = c
+	hunterMtx.Unlock()
+
+	return c
+}
+
+// Enable returns true if client is enabled.
+func (c *Client) Enable() bool {
+	return c.enable
+}
+
+// Notify takes three arguments:
+// err - standard error;
+// sync - if true then the function waits for the end of sending;
+// skip - how many errors to remove from stacktrace.
+func (c *Client) Notify(err error, sync bool, skip int) {
+	if !c.enable {
+		return
+	}
+
+	level := LevelError
+	if sync {
+		level = LevelFatal
+	}
+
+	e := NewEvent(err, level, c.version, skip+1)
+	if sync {
+		c.send(e)
+		return
+	}
+	go c.send(e)
+}
+
+func (c *Client) send(e *Event) {
+	if err := c.sender.Send(e); err != nil && c.printer != nil {
+		c.printer.Printf("failed to send notify: %v", err)
+	}
+}
+
+// PanicIgnore disables reporting of the next panic by PanicHunter.
+func (c *Client) PanicIgnore() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.panicIgnore = true
+}
+
+// PanicHunter catches panic, reports it with the last created client, if
+// it's enabled, and panics again.
+func PanicHunter() {
+	err := recover()
+	if err == nil {
+		return
+	}
+
+	hunterMtx.Lock()
+	c := hunter
+	hunterMtx.Unlock()
+
+	if c != nil && c.enable {
+		c.mtx.Lock()
+		ignore := c.panicIgnore
+		c.mtx.Unlock()
+
+		if !ignore {
+			c.send(NewEvent(err, LevelFatal, c.version, 2))
+		}
+	}
+
+	panic(err)
+}
@@ -0,0 +1,98 @@
+package crashdir
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/privatix/dappctrl/report"
+)
+
+const (
+	fileExt  = ".json"
+	fileTime = "20060102T150405.000"
+)
+
+// Config is a configuration of a crash directory reporter.
+type Config struct {
+	Dir      string
+	MaxFiles uint // Zero for no limit.
+}
+
+// NewConfig creates a default crash directory reporter configuration.
+func NewConfig() *Config {
+	return &Config{
+		Dir:      "crash",
+		MaxFiles: 100,
+	}
+}
+
+// Sender writes error reports into a directory, one file per report.
+type Sender struct {
+	conf *Config
+	mtx  sync.Mutex
+}
+
+// NewSender creates a new crash directory sender.
+func NewSender(conf *Config) (*Sender, error) {
+	if err := os.MkdirAll(conf.Dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Sender{conf: conf}, nil
+}
+
+// Send sends an error report.
+func (s *Sender) Send(e *report.Event) error {
+	data, err := json.MarshalIndent(e, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(s.conf.Dir, fmt.Sprintf("%s-%s%s",
+		e.Time.Format(fileTime), e.ID, fileExt))
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := ioutil.WriteFile(name, data, 0600); err != nil {
+		return err
+	}
+
+	return s.removeOld()
+}
+
+// removeOld deletes the oldest reports exceeding max files. Report file
+// names start with their time, so they are sorted by name.
+func (s *Sender) removeOld() error {
+	if s.conf.MaxFiles == 0 {
+		return nil
+	}
+
+	infos, err := ioutil.ReadDir(s.conf.Dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, v := range infos {
+		if !v.IsDir() && strings.HasSuffix(v.Name(), fileExt) {
+			names = append(names, v.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for len(names) > int(s.conf.MaxFiles) {
+		err := os.Remove(filepath.Join(s.conf.Dir, names[0]))
+		if err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+
+	return nil
+}
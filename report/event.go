@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/privatix/dappctrl/util"
+)
+
+// Event is an error report sent by reporters other than Bugsnag.
+type Event struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Version string    `json:"version"`
+	Stack   []Frame   `json:"stack"`
+}
+
+// Frame is a stack frame of an error report.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Event levels.
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Account addresses are written as hex strings with or without 0x prefix.
+var addressRegexp = regexp.MustCompile(`\b(0[xX])?[0-9a-fA-F]{40}\b`)
+
+// ScrubAddress is a replacement of account addresses in error reports.
+const ScrubAddress = "0x[address]"
+
+// Scrub removes account addresses from a string.
+func Scrub(s string) string {
+	return addressRegexp.ReplaceAllString(s, ScrubAddress)
+}
+
+// scrubFile removes local directories, which can contain user names, from
+// a source file path. The path is made relative to GOPATH using a package
+// path of a function.
+func scrubFile(function, file string) string {
+	pkg := function
+	slash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[slash+1:], "."); dot >= 0 {
+		pkg = pkg[:slash+1+dot]
+	}
+
+	if pkg == "" {
+		return path.Base(file)
+	}
+
+	return pkg + "/" + path.Base(file)
+}
+
+// NewEvent creates a new error report with account addresses scrubbed. Skip
+// is a number of stack frames to skip above the caller.
+func NewEvent(err interface{}, level, version string, skip int) *Event {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{
+			Function: frame.Function,
+			File:     scrubFile(frame.Function, frame.File),
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+
+	return &Event{
+		ID:      util.NewUUID(),
+		Time:    time.Now().UTC(),
+		Level:   level,
+		Message: Scrub(fmt.Sprint(err)),
+		Version: version,
+		Stack:   stack,
+	}
+}
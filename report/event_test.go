@@ -0,0 +1,35 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScrub(t *testing.T) {
+	for _, v := range []struct{ in, out string }{
+		{"account 0x5d98bc82cbbd99bcd413cb67dbe823bb5d98bc82 is locked",
+			"account " + ScrubAddress + " is locked"},
+		{"account 5D98BC82CBBD99BCD413CB67DBE823BB5D98BC82",
+			"account " + ScrubAddress},
+		{"hash 0x5d98bc82cbbd99bcd413cb67dbe823bb5d98bc82cbbd99bcd413cb67",
+			"hash 0x5d98bc82cbbd99bcd413cb67dbe823bb5d98bc82cbbd99bcd413cb67"},
+	} {
+		if out := Scrub(v.in); out != v.out {
+			t.Fatalf("wrong scrubbed string: %s", out)
+		}
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	e := NewEvent(errors.New(
+		"0x5d98bc82cbbd99bcd413cb67dbe823bb5d98bc82"), LevelError, "v1", 0)
+
+	if e.Message != ScrubAddress || e.Level != LevelError ||
+		e.Version != "v1" || len(e.Stack) == 0 ||
+		!strings.HasSuffix(e.Stack[0].Function, "TestNewEvent") ||
+		!strings.HasPrefix(e.Stack[0].File,
+			"github.com/privatix/dappctrl/report/") {
+		t.Fatalf("wrong event: %+v", e)
+	}
+}
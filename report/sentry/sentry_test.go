@@ -0,0 +1,58 @@
+package sentry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/privatix/dappctrl/report"
+)
+
+func TestSend(t *testing.T) {
+	var auth string
+	var ev event
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/sentry/api/42/store/" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			auth = r.Header.Get("X-Sentry-Auth")
+			json.NewDecoder(r.Body).Decode(&ev)
+		}))
+	defer srv.Close()
+
+	conf := NewConfig()
+	conf.DSN = strings.Replace(srv.URL, "://", "://key@", 1) + "/sentry/42"
+
+	s, err := NewSender(conf, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := report.NewEvent(errors.New("failure"), report.LevelFatal, "v1", 0)
+	if err := s.Send(e); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(auth, "sentry_key=key") ||
+		ev.Message != "failure" || ev.Level != report.LevelFatal ||
+		len(ev.EventID) != 32 || len(ev.Exception.Values) != 1 {
+		t.Fatalf("wrong sentry event: %s %+v", auth, ev)
+	}
+
+	frames := ev.Exception.Values[0].Stacktrace.Frames
+	if !strings.HasSuffix(frames[len(frames)-1].Function, "TestSend") {
+		t.Fatalf("wrong sentry stack: %+v", frames)
+	}
+
+	for _, v := range []string{"http://host/42", "http://key@host/"} {
+		conf.DSN = v
+		if _, err := NewSender(conf, "v1"); err == nil {
+			t.Fatalf("sender created with dsn %s", v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/privatix/dappctrl/report"
+)
+
+// Config is a configuration of a webhook reporter. Error reports are posted
+// to a given URL as report.Event JSON objects with given extra headers.
+type Config struct {
+	URL     string
+	Headers map[string]string
+	Timeout uint // In milliseconds.
+}
+
+// NewConfig creates a default webhook reporter configuration.
+func NewConfig() *Config {
+	return &Config{
+		Headers: make(map[string]string),
+		Timeout: 10000,
+	}
+}
+
+// Sender posts error reports to a webhook.
+type Sender struct {
+	conf   *Config
+	client *http.Client
+}
+
+// NewSender creates a new webhook sender.
+func NewSender(conf *Config) (*Sender, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("no webhook url")
+	}
+
+	return &Sender{
+		conf: conf,
+		client: &http.Client{
+			Timeout: time.Duration(conf.Timeout) * time.Millisecond,
+		},
+	}, nil
+}
+
+// Send sends an error report.
+func (s *Sender) Send(e *report.Event) error {
+	return report.PostJSON(s.client, s.conf.URL, s.conf.Headers, e)
+}
@@ -72,6 +72,11 @@ func (c *Client) StopSession(key string) error {
 	return c.callSess(nil, "stopSession", key)
 }
 
+// ConnFailed reports a failure to connect to an endpoint.
+func (c *Client) ConnFailed(key string) error {
+	return c.callSess(nil, "connFailed", key)
+}
+
 // SetProductConfig sets product config.
 func (c *Client) SetProductConfig(config map[string]string) error {
 	return c.callSess(nil, "setProductConfig", config)
@@ -10,20 +10,33 @@ import (
 
 // Handler is a session RPC handler.
 type Handler struct {
-	countryConf *country.Config
-	db          *reform.DB
-	logger      log.Logger
-	queue       job.Queue
+	country  country.Resolver
+	db       *reform.DB
+	logger   log.Logger
+	queue    job.Queue
+	failures []FailureReporter
+}
+
+// FailureReporter is notified about connection failures reported by
+// a service adapter.
+type FailureReporter interface {
+	ChannelFailed(channel string)
+	ChannelRecovered(channel string)
 }
 
 // NewHandler creates a new session handler.
 func NewHandler(logger log.Logger, db *reform.DB,
-	countryConf *country.Config, queue job.Queue) *Handler {
+	country country.Resolver, queue job.Queue) *Handler {
 	logger = logger.Add("type", "sess.Handler")
 	return &Handler{
-		db:          db,
-		logger:      logger,
-		countryConf: countryConf,
-		queue:       queue,
+		db:      db,
+		logger:  logger,
+		country: country,
+		queue:   queue,
 	}
 }
+
+// AddFailureReporter adds a reporter to notify about connection failures.
+func (h *Handler) AddFailureReporter(reporter FailureReporter) {
+	h.failures = append(h.failures, reporter)
+}
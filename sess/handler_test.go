@@ -25,7 +25,7 @@ var (
 	client  *rpc.Client
 )
 
-func newTestCountryConfig() *country.Config {
+func newTestCountryResolver() country.Resolver {
 	const countryField = "testCountry"
 
 	cs := country.NewServerMock(countryField, "YY")
@@ -35,7 +35,7 @@ func newTestCountryConfig() *country.Config {
 	conf.Field = countryField
 	conf.URLTemplate = cs.Server.URL
 
-	return conf
+	return country.NewHTTPResolver(conf)
 }
 
 func newTestFixture(t *testing.T) *data.TestFixture {
@@ -50,7 +50,7 @@ func newTestFixture(t *testing.T) *data.TestFixture {
 func newClient(queue job.Queue) *rpc.Client {
 	server := rpc.NewServer()
 	handler = sess.NewHandler(log.NewMultiLogger(),
-		db, newTestCountryConfig(), queue)
+		db, newTestCountryResolver(), queue)
 	if err := server.RegisterName("sess", handler); err != nil {
 		panic(err)
 	}
@@ -59,3 +59,18 @@ func (h *Handler) findCurrentSession(
 	}
 	return &sess, nil
 }
+
+// limitTrialByIP reduces free units of a channel, which exceed a trial
+// limit of its product for a given client IP address.
+func limitTrialByIP(logger log.Logger,
+	tx *reform.TX, ch *data.Channel, ip string) error {
+	free, err := data.TrialUnitsByIP(tx.Querier, ch, ip)
+	if err != nil || free == ch.FreeUnits {
+		return err
+	}
+
+	logger.Add("freeUnits", free).Info("client IP trial is used up")
+
+	ch.FreeUnits = free
+	return tx.UpdateColumns(ch, "free_units")
+}
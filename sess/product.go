@@ -3,7 +3,6 @@ package sess
 import (
 	"encoding/json"
 	"net"
-	"strings"
 
 	"github.com/privatix/dappctrl/country"
 	"github.com/privatix/dappctrl/data"
@@ -58,13 +57,9 @@ func serviceEndpointAddress(
 }
 
 func (h *Handler) findCountry(logger log.Logger, ip string) string {
-	url := strings.Replace(h.countryConf.URLTemplate, "{{ip}}", ip, 1)
+	logger = logger.Add("ip", ip)
 
-	logger = logger.Add("url", url, "field", h.countryConf.Field,
-		"timeout", h.countryConf.Timeout, "ip", ip)
-
-	country2, err := country.GetCountry(
-		h.countryConf.Timeout, url, h.countryConf.Field)
+	country2, err := h.country.Country(ip)
 	if err != nil {
 		logger.Error(err.Error())
 		return country.UndefinedCountry
@@ -122,6 +122,12 @@ func (h *Handler) StartSession(product, productPassword,
 			return err
 		}
 
+		if prod.IsServer && ipPtr != nil {
+			if err := limitTrialByIP(logger, tx, ch, ip); err != nil {
+				return err
+			}
+		}
+
 		if ch.ServiceStatus == data.ServiceActivating {
 			err := job.AddWithData(h.queue, tx,
 				data.JobCompleteServiceTransition,
@@ -139,6 +145,10 @@ func (h *Handler) StartSession(product, productPassword,
 		return nil, ErrInternal
 	}
 
+	for _, v := range h.failures {
+		v.ChannelRecovered(ch.ID)
+	}
+
 	return &offer, nil
 }
 
@@ -172,6 +182,7 @@ func (h *Handler) UpdateSession(product, productPassword, clientKey string,
 	}
 	logger = logger.Add("session", sess)
 
+	prevUnits := sess.UnitsUsed
 	if units != 0 {
 		// TODO: Use unit size instead of this hardcode.
 		units /= 1024 * 1024
@@ -190,7 +201,23 @@ func (h *Handler) UpdateSession(product, productPassword, clientKey string,
 
 	logger.Info("updating session")
 
-	if err := h.db.Save(sess); err != nil {
+	err = h.db.InTransaction(func(tx *reform.TX) error {
+		if err := tx.Save(sess); err != nil {
+			return err
+		}
+
+		if sess.UnitsUsed <= prevUnits {
+			return nil
+		}
+
+		return tx.Insert(&data.UsageSample{
+			Session: sess.ID,
+			Channel: ch.ID,
+			Time:    sess.LastUsageTime,
+			Units:   sess.UnitsUsed - prevUnits,
+		})
+	})
+	if err != nil {
 		logger.Error(err.Error())
 		return ErrInternal
 	}
@@ -220,6 +247,18 @@ func (h *Handler) StopSession(product, productPassword, clientKey string) error
 		return nil
 	}
 
+	// Service of a client was not suspended by the client, so it might be
+	// suspended by an agent. The agent is asked whether it suspended the
+	// service before usage is verified.
+	if !prod.IsServer && ch.ServiceStatus == data.ServiceActive {
+		err = job.AddSimple(h.queue, nil, data.JobClientVerifyUsage,
+			data.JobChannel, ch.ID, data.JobSessionServer)
+		if err != nil && err != job.ErrDuplicatedJob {
+			logger.Error(err.Error())
+			return ErrInternal
+		}
+	}
+
 	status := data.ServiceSuspended
 	if ch.ServiceStatus == data.ServiceTerminating {
 		status = data.ServiceTerminated
@@ -253,3 +292,36 @@ func (h *Handler) StopSession(product, productPassword, clientKey string) error
 
 	return nil
 }
+
+// ConnFailed signals that a service adapter failed to connect to an endpoint.
+// For clients only.
+func (h *Handler) ConnFailed(product, productPassword, clientKey string) error {
+	logger := h.logger.Add("method", "ConnFailed", "product", product,
+		"clientKey", clientKey)
+
+	logger.Info("connection failure report")
+
+	prod, err := h.checkProductPassword(logger, product, productPassword)
+	if err != nil {
+		return err
+	}
+
+	ch, err := h.findClientChannel(logger, prod, clientKey, true)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range h.failures {
+		v.ChannelFailed(ch.ID)
+	}
+
+	// Agent could have re-issued credentials of the channel.
+	err = job.AddSimple(h.queue, nil, data.JobClientEndpointRefresh,
+		data.JobChannel, ch.ID, data.JobSessionServer)
+	if err != nil && err != job.ErrDuplicatedJob {
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -48,7 +48,7 @@ func TestServiceReady(t *testing.T) {
 		})
 
 		h := sess.NewHandler(log.NewMultiLogger(),
-			db, newTestCountryConfig(), queueMock)
+			db, newTestCountryResolver(), queueMock)
 		fxt.Channel.ServiceStatus = data.ServiceActivating
 		data.SaveToTestDB(t, fxt.DB, fxt.Channel)
 		err := h.ServiceReady(prod, prodPass, clientKey)
@@ -262,4 +262,99 @@ func TestStopSession(t *testing.T) {
 			fxt.T.Fatalf("wrong session stopped time")
 		}
 	})
+
+	t.Run("VerifyUsage", func(t *testing.T) {
+		var types []string
+		queueMock := job.QueueMock(func(method int, tx *reform.TX,
+			j *data.Job, _ []string, _ string, _ job.SubFunc) error {
+			if method == job.MockAdd {
+				types = append(types, j.Type)
+			}
+			return nil
+		})
+
+		h := sess.NewHandler(log.NewMultiLogger(),
+			db, newTestCountryResolver(), queueMock)
+		err := h.StopSession(fxt.Product.ID, data.TestPassword, fxt.Channel.ID)
+		util.TestExpectResult(t, "StopSession", nil, err)
+
+		if len(types) != 2 || types[0] != data.JobClientVerifyUsage {
+			t.Fatalf("usage verification is not requested: %v", types)
+		}
+	})
+}
+
+func TestUpdateSessionUsageSamples(t *testing.T) {
+	fxt := newTestFixture(t)
+	defer fxt.Close()
+
+	_, err := handler.StartSession(fxt.Product.ID, data.TestPassword,
+		fxt.Channel.ID, "1.2.3.4", 1234)
+	util.TestExpectResult(t, "Start", nil, err)
+
+	var sess data.Session
+	if err := db.FindOneTo(&sess, "channel", fxt.Channel.ID); err != nil {
+		fxt.T.Fatalf("cannot find new session: %s", err)
+	}
+	defer db.Delete(&sess)
+
+	fxt.Product.UsageRepType = data.ProductUsageIncremental
+	data.SaveToTestDB(t, fxt.DB, fxt.Product)
+
+	const units = 3 * 1024 * 1024
+	for i := 0; i < 2; i++ {
+		err := handler.UpdateSession(fxt.Product.ID, data.TestPassword,
+			fxt.Channel.ID, units)
+		util.TestExpectResult(t, "UpdateSession", nil, err)
+	}
+
+	// No sample is expected for an update without usage.
+	err = handler.UpdateSession(fxt.Product.ID, data.TestPassword,
+		fxt.Channel.ID, 0)
+	util.TestExpectResult(t, "UpdateSession", nil, err)
+
+	samples, err := db.SelectAllFrom(data.UsageSampleTable,
+		"WHERE session = $1", sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(samples) != 2 {
+		t.Fatalf("wrong number of usage samples: %d", len(samples))
+	}
+	for _, v := range samples {
+		if s := v.(*data.UsageSample); s.Channel != fxt.Channel.ID ||
+			s.Units != 3 {
+			t.Fatalf("wrong usage sample: %+v", s)
+		}
+	}
+}
+
+func TestConnFailed(t *testing.T) {
+	fxt := newTestFixture(t)
+	defer fxt.Close()
+
+	var added *data.Job
+	queueMock := job.QueueMock(func(method int, tx *reform.TX,
+		j *data.Job, _ []string, _ string, _ job.SubFunc) error {
+		if method != job.MockAdd {
+			t.Errorf("method=%v, want %v", method, job.MockAdd)
+		}
+		added = j
+		return nil
+	})
+
+	h := sess.NewHandler(log.NewMultiLogger(),
+		db, newTestCountryResolver(), queueMock)
+
+	err := h.ConnFailed(fxt.Product.ID, data.TestPassword, "bad-channel")
+	util.TestExpectResult(t, "ConnFailed", sess.ErrChannelNotFound, err)
+
+	err = h.ConnFailed(fxt.Product.ID, data.TestPassword, fxt.Channel.ID)
+	util.TestExpectResult(t, "ConnFailed", nil, err)
+
+	if added == nil || added.Type != data.JobClientEndpointRefresh ||
+		added.RelatedID != fxt.Channel.ID {
+		t.Fatalf("endpoint refresh job was not added")
+	}
 }
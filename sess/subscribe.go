@@ -32,7 +32,12 @@ func (h *Handler) handleConnChange(product string, logger log.Logger,
 	}
 
 	s := ch.ServiceStatus
-	if s != data.ServiceActivating && s != data.ServiceSuspending && s != data.ServiceTerminating {
+	rotate := job.Type == data.JobAgentPreEndpointMsgRotate
+	if rotate && s != data.ServiceActive {
+		return
+	}
+	if !rotate && s != data.ServiceActivating &&
+		s != data.ServiceSuspending && s != data.ServiceTerminating {
 		return
 	}
 
@@ -46,18 +51,27 @@ func (h *Handler) handleConnChange(product string, logger log.Logger,
 		return
 	}
 
-	status := ConnStart
+	statuses := []string{ConnStart}
 	if job.Type == data.JobClientPreServiceSuspend ||
 		job.Type == data.JobAgentPreServiceSuspend ||
 		job.Type == data.JobClientPreServiceTerminate ||
 		job.Type == data.JobAgentPreServiceTerminate {
-		status = ConnStop
+		statuses = []string{ConnStop}
 	}
 
-	err = ntf.Notify(sub.ID, &ConnChangeResult{ch.ID, status})
-	if err != nil {
-		logger.Warn(fmt.Sprintf("could not notify: %v", err))
-		close(closeCh)
+	// Connections made with old credentials are dropped by restarting
+	// a service for a channel.
+	if rotate {
+		statuses = []string{ConnStop, ConnStart}
+	}
+
+	for _, status := range statuses {
+		err = ntf.Notify(sub.ID, &ConnChangeResult{ch.ID, status})
+		if err != nil {
+			logger.Warn(fmt.Sprintf("could not notify: %v", err))
+			close(closeCh)
+			return
+		}
 	}
 }
 
@@ -88,6 +102,7 @@ func (h *Handler) ConnChange(ctx context.Context,
 	}
 	jobTypes := []string{
 		data.JobAgentPreEndpointMsgCreate,
+		data.JobAgentPreEndpointMsgRotate,
 		data.JobAgentPreServiceSuspend,
 		data.JobClientPreServiceSuspend,
 		data.JobAgentPreServiceUnsuspend,
@@ -116,6 +116,8 @@ func TestConnChange(t *testing.T) {
 						{data.JobAgentPreServiceSuspend, data.ServiceSuspending},
 						{data.JobAgentPreServiceTerminate, data.ServiceTerminated}, // ignored.
 						{data.JobAgentPreServiceTerminate, data.ServiceTerminating},
+						{data.JobAgentPreEndpointMsgRotate, data.ServiceSuspended}, // ignored.
+						{data.JobAgentPreEndpointMsgRotate, data.ServiceActive},
 					} {
 						j.Type = cs.t
 						fxt.Channel.ServiceStatus = cs.s
@@ -147,6 +149,8 @@ func TestConnChange(t *testing.T) {
 			sess.ConnStart,
 			sess.ConnStop,
 			sess.ConnStop,
+			sess.ConnStop,
+			sess.ConnStart,
 		} {
 			ret := <-ch
 			if ret.Channel != fxt.Channel.ID || ret.Status != wantStatus {
@@ -83,7 +83,8 @@ func TestUpdatePassword(t *testing.T) {
 	pwdStorage.Set(data.TestPassword)
 	handler := ui.NewHandler(logger, db, nil, pwdStorage,
 		data.EncryptedKey, data.RoleClient, nil,
-		somc.NewTestClientBuilder(testSOMCClient), testToken, &testGasPriceSuggestor)
+		somc.NewTestClientBuilder(testSOMCClient), testToken, &testGasPriceSuggestor,
+		testLogFeed)
 	err := server.RegisterName("ui2", handler)
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"gopkg.in/reform.v1"
+
+	"github.com/privatix/dappctrl/client/budget"
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// GetSpendingBudgets returns all spending budgets.
+func (h *Handler) GetSpendingBudgets(
+	tkn string) ([]data.SpendingBudget, error) {
+	logger := h.logger.Add("method", "GetSpendingBudgets")
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	recs, err := h.selectAllFrom(logger, data.SpendingBudgetTable,
+		"ORDER BY account NULLS FIRST, period")
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]data.SpendingBudget, len(recs))
+	for k, v := range recs {
+		ret[k] = *v.(*data.SpendingBudget)
+	}
+
+	return ret, nil
+}
+
+// SetSpendingBudget sets a spending budget of a client account for
+// a given period. The budget is global, if no account is given.
+// Returns id of the budget.
+func (h *Handler) SetSpendingBudget(tkn string, account *string,
+	period string, amount uint64) (*string, error) {
+	logger := h.logger.Add("method", "SetSpendingBudget",
+		"account", account, "period", period, "amount", amount)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	if h.userRole == data.RoleAgent {
+		logger.Warn(ErrNotAllowedForAgent.Error())
+		return nil, ErrNotAllowedForAgent
+	}
+
+	if amount == 0 || (period != data.BudgetDaily &&
+		period != data.BudgetMonthly && period != data.BudgetLifetime) {
+		logger.Warn(ErrBadSpendingBudget.Error())
+		return nil, ErrBadSpendingBudget
+	}
+
+	if account != nil {
+		var acc data.Account
+		if err := h.findByPrimaryKey(logger,
+			ErrAccountNotFound, &acc, *account); err != nil {
+			return nil, err
+		}
+	}
+
+	var b data.SpendingBudget
+	err := h.db.SelectOneTo(&b, `
+		WHERE account IS NOT DISTINCT FROM $1 AND period = $2`,
+		account, period)
+	if err == reform.ErrNoRows {
+		b = data.SpendingBudget{
+			ID:      util.NewUUID(),
+			Account: account,
+			Period:  period,
+		}
+	} else if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+	b.Amount = amount
+
+	if err := h.db.Save(&b); err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	return &b.ID, nil
+}
+
+// DeleteSpendingBudget deletes a spending budget.
+func (h *Handler) DeleteSpendingBudget(tkn, id string) error {
+	logger := h.logger.Add("method", "DeleteSpendingBudget", "id", id)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return ErrAccessDenied
+	}
+
+	var b data.SpendingBudget
+	if err := h.findByPrimaryKey(logger,
+		ErrSpendingBudgetNotFound, &b, id); err != nil {
+		return err
+	}
+
+	if err := h.db.Delete(&b); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (h *Handler) checkBudgets(
+	logger log.Logger, account string, amount uint64) error {
+	err := budget.Check(logger, h.db, h.queue, account, amount, data.JobUser)
+	switch err {
+	case nil:
+		return nil
+	case budget.ErrBudgetExceeded:
+		return ErrBudgetExceeded
+	default:
+		return ErrInternal
+	}
+}
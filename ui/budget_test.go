@@ -0,0 +1,92 @@
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/ui"
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestSetSpendingBudget(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "SetSpendingBudget")
+	defer fxt.close()
+
+	_, err := handler.SetSpendingBudget("wrong-token",
+		nil, data.BudgetDaily, 100)
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	_, err = handler.SetSpendingBudget(testToken.v, nil, "weekly", 100)
+	assertErrEqual(ui.ErrBadSpendingBudget, err)
+
+	_, err = handler.SetSpendingBudget(testToken.v,
+		nil, data.BudgetDaily, 0)
+	assertErrEqual(ui.ErrBadSpendingBudget, err)
+
+	_, err = handler.SetSpendingBudget(testToken.v,
+		pointer.ToString(util.NewUUID()), data.BudgetDaily, 100)
+	assertErrEqual(ui.ErrAccountNotFound, err)
+
+	id, err := handler.SetSpendingBudget(testToken.v,
+		&fxt.UserAcc.ID, data.BudgetMonthly, 100)
+	assertErrEqual(nil, err)
+	defer data.DeleteFromTestDB(t, db, &data.SpendingBudget{ID: *id})
+
+	id2, err := handler.SetSpendingBudget(testToken.v,
+		&fxt.UserAcc.ID, data.BudgetMonthly, 200)
+	assertErrEqual(nil, err)
+	if *id2 != *id {
+		t.Fatal("existing budget expected to be updated")
+	}
+
+	budgets, err := handler.GetSpendingBudgets(testToken.v)
+	assertErrEqual(nil, err)
+	if len(budgets) != 1 || budgets[0].Amount != 200 {
+		t.Fatalf("wrong budgets returned: %+v", budgets)
+	}
+}
+
+func TestDeleteSpendingBudget(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "DeleteSpendingBudget")
+	defer fxt.close()
+
+	b := &data.SpendingBudget{
+		ID:     util.NewUUID(),
+		Period: data.BudgetLifetime,
+		Amount: 100,
+	}
+	data.InsertToTestDB(t, db, b)
+
+	err := handler.DeleteSpendingBudget("wrong-token", b.ID)
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	err = handler.DeleteSpendingBudget(testToken.v, util.NewUUID())
+	assertErrEqual(ui.ErrSpendingBudgetNotFound, err)
+
+	err = handler.DeleteSpendingBudget(testToken.v, b.ID)
+	assertErrEqual(nil, err)
+}
+
+func TestTopUpChannelBudgetExceeded(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "TopUpChannel")
+	defer fxt.close()
+
+	setTestJobQueueToExpectJobAdd(t, new(data.Job))
+
+	b := &data.SpendingBudget{
+		ID:      util.NewUUID(),
+		Account: &fxt.UserAcc.ID,
+		Period:  data.BudgetDaily,
+		Amount:  100,
+	}
+	data.InsertToTestDB(t, db, b)
+	defer data.DeleteFromTestDB(t, db, b)
+
+	err := handler.TopUpChannel(testToken.v, fxt.Channel.ID, 101, 123)
+	assertErrEqual(ui.ErrBudgetExceeded, err)
+
+	err = handler.TopUpChannel(testToken.v, fxt.Channel.ID, 100, 123)
+	assertErrEqual(nil, err)
+}
@@ -94,6 +94,20 @@ func (h *Handler) TopUpChannel(
 		return err
 	}
 
+	if err := h.checkTopUpPolicy(logger, ch, jobData.Deposit); err != nil {
+		return err
+	}
+
+	var acc data.Account
+	if err := h.findByColumn(logger, ErrAccountNotFound,
+		&acc, "eth_addr", ch.Client); err != nil {
+		return err
+	}
+
+	if err := h.checkBudgets(logger, acc.ID, jobData.Deposit); err != nil {
+		return err
+	}
+
 	return job.AddWithData(h.queue, nil, data.JobClientPreChannelTopUp,
 		data.JobChannel, ch.ID, data.JobUser, jobData)
 }
@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/proc"
+	"github.com/privatix/dappctrl/util/log"
 )
 
 func (h *Handler) getEndpointsConditions(
@@ -55,3 +58,85 @@ func (h *Handler) GetEndpoints(
 
 	return endpoints, nil
 }
+
+// RotateChannelEndpoint re-issues an endpoint message with new access
+// credentials for a given agent channel. Returns an id of a created job.
+func (h *Handler) RotateChannelEndpoint(tkn, channel string) (string, error) {
+	logger := h.logger.Add("method", "RotateChannelEndpoint",
+		"channel", channel)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return "", ErrAccessDenied
+	}
+
+	if h.userRole != data.RoleAgent {
+		logger.Warn(ErrNotAllowedForClient.Error())
+		return "", ErrNotAllowedForClient
+	}
+
+	items, err := h.selectAllFrom(logger, data.ChannelTable,
+		fmt.Sprintf("WHERE id = %s AND %s",
+			h.db.Placeholder(1), agentChannelsCondition), channel)
+	if err != nil {
+		return "", err
+	}
+
+	if len(items) != 1 {
+		logger.Warn(ErrChannelNotFound.Error())
+		return "", ErrChannelNotFound
+	}
+
+	id, err := h.processor.RotateChannel(channel, data.JobUser)
+	if err != nil {
+		return "", rotateError(logger, err)
+	}
+
+	return id, nil
+}
+
+// RotateProductEndpoints re-issues endpoint messages with new access
+// credentials for all channels of a given product. Returns ids of created
+// jobs.
+func (h *Handler) RotateProductEndpoints(
+	tkn, product string) ([]string, error) {
+	logger := h.logger.Add("method", "RotateProductEndpoints",
+		"product", product)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	if h.userRole != data.RoleAgent {
+		logger.Warn(ErrNotAllowedForClient.Error())
+		return nil, ErrNotAllowedForClient
+	}
+
+	var prod data.Product
+	if err := h.findByPrimaryKey(
+		logger, ErrProductNotFound, &prod, product); err != nil {
+		return nil, err
+	}
+
+	ids, err := h.processor.RotateProductChannels(product, data.JobUser)
+	if err != nil {
+		return nil, rotateError(logger, err)
+	}
+
+	return ids, nil
+}
+
+func rotateError(logger log.Logger, err error) error {
+	switch err {
+	case proc.ErrBadServiceStatus:
+		logger.Warn(err.Error())
+		return ErrBadServiceStatus
+	case proc.ErrSameJobExists:
+		logger.Warn(err.Error())
+		return ErrAlreadyActiveJob
+	}
+
+	logger.Error(err.Error())
+	return ErrInternal
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/proc"
 	"github.com/privatix/dappctrl/ui"
 	"github.com/privatix/dappctrl/util"
 )
@@ -54,3 +55,52 @@ func TestGetEndpoints(t *testing.T) {
 		assertResult(res, err, v.expected)
 	}
 }
+
+func TestRotateEndpoints(t *testing.T) {
+	fxt, assertErrEquals := newTest(t, "RotateEndpoints")
+	defer fxt.close()
+
+	j := new(data.Job)
+	queueMock := setTestJobQueueToExpectJobAdd(t, j)
+	handler.SetProcessor(proc.NewProcessor(conf.Proc, db, queueMock))
+
+	_, err := handler.RotateChannelEndpoint("wrong-token", fxt.Channel.ID)
+	assertErrEquals(ui.ErrAccessDenied, err)
+
+	_, err = handler.RotateProductEndpoints("wrong-token", fxt.Product.ID)
+	assertErrEquals(ui.ErrAccessDenied, err)
+
+	handler.SetMockRole(data.RoleClient)
+	_, err = handler.RotateChannelEndpoint(testToken.v, fxt.Channel.ID)
+	assertErrEquals(ui.ErrNotAllowedForClient, err)
+
+	handler.SetMockRole(data.RoleAgent)
+	_, err = handler.RotateChannelEndpoint(testToken.v, util.NewUUID())
+	assertErrEquals(ui.ErrChannelNotFound, err)
+
+	_, err = handler.RotateProductEndpoints(testToken.v, util.NewUUID())
+	assertErrEquals(ui.ErrProductNotFound, err)
+
+	fxt.Channel.ServiceStatus = data.ServicePending
+	data.SaveToTestDB(t, db, fxt.Channel)
+	_, err = handler.RotateChannelEndpoint(testToken.v, fxt.Channel.ID)
+	assertErrEquals(ui.ErrBadServiceStatus, err)
+
+	fxt.Channel.ServiceStatus = data.ServiceActive
+	data.SaveToTestDB(t, db, fxt.Channel)
+
+	_, err = handler.RotateChannelEndpoint(testToken.v, fxt.Channel.ID)
+	assertErrEquals(nil, err)
+	if j.Type != data.JobAgentPreEndpointMsgRotate ||
+		j.RelatedID != fxt.Channel.ID {
+		t.Fatal("expected job not created")
+	}
+
+	*j = data.Job{}
+	ids, err := handler.RotateProductEndpoints(testToken.v, fxt.Product.ID)
+	assertErrEquals(nil, err)
+	if len(ids) != 1 || j.Type != data.JobAgentPreEndpointMsgRotate ||
+		j.RelatedID != fxt.Channel.ID {
+		t.Fatal("expected job not created")
+	}
+}
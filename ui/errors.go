@@ -43,6 +43,17 @@ const (
 	ErrTxIsUnderpriced
 	ErrSuccessJobNonReactivatable
 	ErrAlreadyActiveJob
+	ErrBadTopUpPolicy
+	ErrTopUpCapExceeded
+	ErrBadSpendingBudget
+	ErrSpendingBudgetNotFound
+	ErrBudgetExceeded
+	ErrBadIncomeReport
+	ErrBadUsageSeries
+	ErrBadLogBackfill
+	ErrNotAllowedForClient
+	ErrBadServiceStatus
+	ErrBadOfferingExpiry
 )
 
 var errMsgs = errors.Messages{
@@ -82,6 +93,17 @@ var errMsgs = errors.Messages{
 	ErrTxIsUnderpriced:            "transaction new gas price must be bigger than before",
 	ErrSuccessJobNonReactivatable: "succeessful job can't be reactivated",
 	ErrAlreadyActiveJob:           "already active job",
+	ErrBadTopUpPolicy:             "bad top up policy",
+	ErrTopUpCapExceeded:           "top up exceeds channel policy caps",
+	ErrBadSpendingBudget:          "bad spending budget",
+	ErrSpendingBudgetNotFound:     "spending budget not found",
+	ErrBudgetExceeded:             "spending budget exceeded",
+	ErrBadIncomeReport:            "bad income report parameters",
+	ErrBadUsageSeries:             "bad usage series parameters",
+	ErrBadLogBackfill:             "too many log events to backfill",
+	ErrNotAllowedForClient:        "operation not allowed for client",
+	ErrBadServiceStatus:           "service status forbids operation",
+	ErrBadOfferingExpiry:          "offering expiry is in the past",
 }
 
 func init() { errors.InjectMessages(errMsgs) }
@@ -18,6 +18,12 @@ type Suggestor interface {
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 }
 
+// LogFeed delivers log events as they are written.
+type LogFeed interface {
+	Subscribe(id string, cb func(*data.LogEvent))
+	Unsubscribe(id string)
+}
+
 // Handler is an UI RPC handler.
 type Handler struct {
 	logger            log.Logger
@@ -30,6 +36,7 @@ type Handler struct {
 	somcClientBuilder somc.ClientBuilderInterface
 	token             TokenMakeChecker
 	suggestor         Suggestor
+	logFeed           LogFeed
 }
 
 // NewHandler creates a new handler.
@@ -38,7 +45,7 @@ func NewHandler(logger log.Logger, db *reform.DB,
 	encryptKeyFunc data.EncryptedKeyFunc, userRole string,
 	processor *proc.Processor,
 	somcClientBuilder somc.ClientBuilderInterface,
-	token TokenMakeChecker, suggestor Suggestor) *Handler {
+	token TokenMakeChecker, suggestor Suggestor, logFeed LogFeed) *Handler {
 	logger = logger.Add("type", "ui.Handler")
 	return &Handler{
 		logger:            logger,
@@ -51,5 +58,6 @@ func NewHandler(logger log.Logger, db *reform.DB,
 		somcClientBuilder: somcClientBuilder,
 		token:             token,
 		suggestor:         suggestor,
+		logFeed:           logFeed,
 	}
 }
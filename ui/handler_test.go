@@ -13,6 +13,7 @@ import (
 
 	"github.com/privatix/dappctrl/client/somc"
 	"github.com/privatix/dappctrl/data"
+	dblog "github.com/privatix/dappctrl/data/log"
 	"github.com/privatix/dappctrl/job"
 	"github.com/privatix/dappctrl/proc"
 	"github.com/privatix/dappctrl/ui"
@@ -43,6 +44,7 @@ var (
 	testSOMCClient        *somc.TestClient
 	testToken             *dumbToken
 	testGasPriceSuggestor gasPriceSuggestor
+	testLogFeed           = dblog.NewFeed()
 )
 
 type dumbToken struct {
@@ -151,7 +153,8 @@ func TestMain(m *testing.M) {
 	testToken = &dumbToken{}
 	handler = ui.NewHandler(logger, db, nil, pwdStorage,
 		data.TestEncryptedKey, data.RoleAgent, nil,
-		somc.NewTestClientBuilder(testSOMCClient), testToken, &testGasPriceSuggestor)
+		somc.NewTestClientBuilder(testSOMCClient), testToken, &testGasPriceSuggestor,
+		testLogFeed)
 	if err := server.RegisterName("ui", handler); err != nil {
 		panic(err)
 	}
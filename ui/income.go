@@ -1,5 +1,13 @@
 package ui
 
+import (
+	"bytes"
+	"time"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+)
+
 // GetOfferingIncome returns total receipt balance from all channels of
 // offering with given id.
 func (h *Handler) GetOfferingIncome(
@@ -36,3 +44,95 @@ func (h *Handler) GetTotalIncome(tkn string) (*uint, error) {
 		`SELECT SUM(channels.receipt_balance)
 			FROM channels`)
 }
+
+// GetIncomeReport returns agent income split into day, week or month
+// buckets and optionally grouped by offerings, products or clients.
+func (h *Handler) GetIncomeReport(tkn, bucket, groupBy,
+	dateFrom, dateTo string) ([]data.IncomeReportItem, error) {
+	logger := h.logger.Add("method", "GetIncomeReport", "bucket", bucket,
+		"groupBy", groupBy, "dateFrom", dateFrom, "dateTo", dateTo)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	return h.incomeReport(logger, bucket, groupBy, dateFrom, dateTo)
+}
+
+// ExportIncomeReport returns agent income report in csv or json format.
+func (h *Handler) ExportIncomeReport(tkn, format, bucket, groupBy,
+	dateFrom, dateTo string) (*string, error) {
+	logger := h.logger.Add("method", "ExportIncomeReport", "format", format,
+		"bucket", bucket, "groupBy", groupBy, "dateFrom", dateFrom,
+		"dateTo", dateTo)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	if format != data.IncomeCSV && format != data.IncomeJSON {
+		logger.Warn(ErrBadIncomeReport.Error())
+		return nil, ErrBadIncomeReport
+	}
+
+	items, err := h.incomeReport(logger, bucket, groupBy, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := data.WriteIncomeReport(&buf, items, format); err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	ret := buf.String()
+	return &ret, nil
+}
+
+func (h *Handler) incomeReport(logger log.Logger, bucket, groupBy,
+	dateFrom, dateTo string) ([]data.IncomeReportItem, error) {
+	switch bucket {
+	case data.IncomeDay, data.IncomeWeek, data.IncomeMonth:
+	default:
+		logger.Warn(ErrBadIncomeReport.Error())
+		return nil, ErrBadIncomeReport
+	}
+
+	switch groupBy {
+	case data.IncomeTotal, data.IncomeByOffering,
+		data.IncomeByProduct, data.IncomeByClient:
+	default:
+		logger.Warn(ErrBadIncomeReport.Error())
+		return nil, ErrBadIncomeReport
+	}
+
+	for _, v := range []string{dateFrom, dateTo} {
+		if v != "" && !validReportDate(v) {
+			logger.Warn(ErrBadIncomeReport.Error())
+			return nil, ErrBadIncomeReport
+		}
+	}
+
+	items, err := data.IncomeReport(h.db.Querier,
+		bucket, groupBy, dateFrom, dateTo)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	return items, nil
+}
+
+// validReportDate checks that a report period bound is a date or an RFC 3339
+// timestamp.
+func validReportDate(v string) bool {
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if _, err := time.Parse(layout, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
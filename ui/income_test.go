@@ -1,7 +1,9 @@
 package ui_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/privatix/dappctrl/ui"
@@ -69,3 +71,93 @@ func TestIncome(t *testing.T) {
 		ch3.ReceiptBalance)
 	assertResult(expected, actual, err)
 }
+
+func TestIncomeReport(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "GetIncomeReport")
+	defer fxt.close()
+
+	closed := time.Date(2018, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	ch1 := *fxt.Channel
+	ch1.ID = util.NewUUID()
+	ch1.ChannelStatus = data.ChannelClosedCoop
+	ch1.ServiceChangedTime = &closed
+	ch1.ReceiptBalance = 10
+
+	ch2 := *fxt.Channel
+	ch2.ID = util.NewUUID()
+	ch2.PreparedAt = time.Date(2018, 1, 20, 0, 0, 0, 0, time.UTC)
+	ch2.ReceiptBalance = 20
+
+	ch3 := *fxt.Channel
+	ch3.ID = util.NewUUID()
+	ch3.Block = ch1.Block + 1
+	ch3.ChannelStatus = data.ChannelClosedUncoop
+	ch3.ServiceChangedTime = &closed
+	ch3.ReceiptBalance = 7
+
+	closing1 := &data.Closing{
+		ID:      util.NewUUID(),
+		Type:    data.ClosingCoop,
+		Agent:   ch1.Agent,
+		Client:  ch1.Client,
+		Balance: ch1.ReceiptBalance,
+		Block:   ch1.Block,
+	}
+	closing3 := &data.Closing{
+		ID:      util.NewUUID(),
+		Type:    data.ClosingUncoop,
+		Agent:   ch3.Agent,
+		Client:  ch3.Client,
+		Balance: 5,
+		Block:   ch3.Block,
+	}
+
+	data.InsertToTestDB(t, fxt.DB, &ch1, &ch2, &ch3, closing1, closing3)
+	defer data.DeleteFromTestDB(t, fxt.DB,
+		closing3, closing1, &ch3, &ch2, &ch1)
+
+	_, err := handler.GetIncomeReport("wrong-token",
+		data.IncomeMonth, data.IncomeTotal, "", "")
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	_, err = handler.GetIncomeReport(testToken.v,
+		"year", data.IncomeTotal, "", "")
+	assertErrEqual(ui.ErrBadIncomeReport, err)
+
+	_, err = handler.GetIncomeReport(testToken.v,
+		data.IncomeMonth, "agent", "", "")
+	assertErrEqual(ui.ErrBadIncomeReport, err)
+
+	_, err = handler.GetIncomeReport(testToken.v,
+		data.IncomeMonth, data.IncomeTotal, "01/01/2018", "")
+	assertErrEqual(ui.ErrBadIncomeReport, err)
+
+	items, err := handler.GetIncomeReport(testToken.v, data.IncomeMonth,
+		data.IncomeByOffering, "2018-01-01", "2018-02-01")
+	assertErrEqual(nil, err)
+	if len(items) != 1 || items[0].Group != fxt.Offering.ID ||
+		items[0].Settled != 15 || items[0].Unsettled != 20 {
+		t.Fatalf("wrong income report: %+v", items)
+	}
+
+	items, err = handler.GetIncomeReport(testToken.v, data.IncomeDay,
+		data.IncomeTotal, "2018-01-01", "2018-02-01")
+	assertErrEqual(nil, err)
+	if len(items) != 2 || items[0].Settled != 15 ||
+		items[1].Unsettled != 20 {
+		t.Fatalf("wrong income report: %+v", items)
+	}
+
+	_, err = handler.ExportIncomeReport(testToken.v, "xml",
+		data.IncomeMonth, data.IncomeTotal, "", "")
+	assertErrEqual(ui.ErrBadIncomeReport, err)
+
+	csv, err := handler.ExportIncomeReport(testToken.v, data.IncomeCSV,
+		data.IncomeMonth, data.IncomeTotal, "2018-01-01", "2018-02-01")
+	assertErrEqual(nil, err)
+	if lines := strings.Split(strings.TrimSpace(*csv), "\n"); len(lines) != 2 ||
+		!strings.HasSuffix(lines[1], ",,15,20") {
+		t.Fatalf("wrong csv income report: %s", *csv)
+	}
+}
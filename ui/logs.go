@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"gopkg.in/reform.v1"
+
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/util/log"
 )
@@ -163,3 +166,139 @@ func (h *Handler) GetLogs(tkn string, levels []string, searchText,
 
 	return &GetLogsResult{result, totalItems}, err
 }
+
+// LogEventsFilter is a filter of LogEvents subscription. Empty fields match
+// any log event.
+type LogEventsFilter struct {
+	Levels     []string `json:"levels"`
+	Channel    string   `json:"channel"`
+	Offering   string   `json:"offering"`
+	JobType    string   `json:"jobType"`
+	SearchText string   `json:"searchText"`
+}
+
+// contextID returns an id of an object logged into log event context either
+// as an id or as a whole object.
+func contextID(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		for _, k := range []string{"id", "ID"} {
+			if id, ok := v[k].(string); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+func (f *LogEventsFilter) matchRelated(ctx map[string]interface{},
+	key, id string) bool {
+	if id == "" {
+		return true
+	}
+
+	if contextID(ctx[key]) == id {
+		return true
+	}
+
+	job, ok := ctx["job"].(map[string]interface{})
+	return ok && job["RelatedType"] == key && job["RelatedID"] == id
+}
+
+func (f *LogEventsFilter) match(e *data.LogEvent) bool {
+	if len(f.Levels) != 0 {
+		found := false
+		for _, v := range f.Levels {
+			found = found || v == string(e.Level)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.SearchText != "" {
+		text := strings.ToLower(f.SearchText)
+		if !strings.Contains(strings.ToLower(e.Message), text) &&
+			!strings.Contains(strings.ToLower(string(e.Context)), text) {
+			return false
+		}
+	}
+
+	if f.Channel == "" && f.Offering == "" && f.JobType == "" {
+		return true
+	}
+
+	var ctx map[string]interface{}
+	if err := json.Unmarshal(e.Context, &ctx); err != nil {
+		return false
+	}
+
+	if f.JobType != "" {
+		job, _ := ctx["job"].(map[string]interface{})
+		if ctx["jobType"] != f.JobType &&
+			(job == nil || job["Type"] != f.JobType) {
+			return false
+		}
+	}
+
+	return f.matchRelated(ctx, data.JobChannel, f.Channel) &&
+		f.matchRelated(ctx, data.JobOffering, f.Offering)
+}
+
+// maxLogBackfill is a maximum number of log events to backfill.
+const maxLogBackfill = 1000
+
+// maxLogBackfillScan is a maximum number of log events scanned to find
+// events to backfill.
+const maxLogBackfillScan = 100000
+
+// logEventsBackfill returns up to a given number of the last log events
+// matching a filter, ordered by time.
+func (h *Handler) logEventsBackfill(logger log.Logger,
+	filter *LogEventsFilter, backfill uint) ([]*data.LogEvent, error) {
+	if backfill == 0 {
+		return nil, nil
+	}
+
+	var tail string
+	var args []interface{}
+	if len(filter.Levels) != 0 {
+		tail = fmt.Sprintf("WHERE level IN (%s) ", strings.Join(
+			h.db.Placeholders(1, len(filter.Levels)), ","))
+		for _, v := range filter.Levels {
+			args = append(args, v)
+		}
+	}
+	tail += fmt.Sprintf("ORDER BY time DESC LIMIT %d", maxLogBackfillScan)
+
+	rows, err := h.db.SelectRows(data.LogEventView, tail, args...)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+	defer rows.Close()
+
+	var result []*data.LogEvent
+	for uint(len(result)) < backfill {
+		var e data.LogEvent
+		if err := h.db.NextRow(&e, rows); err != nil {
+			if err == reform.ErrNoRows {
+				break
+			}
+			logger.Error(err.Error())
+			return nil, ErrInternal
+		}
+
+		if filter.match(&e) {
+			result = append(result, &e)
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result, nil
+}
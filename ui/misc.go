@@ -20,7 +20,8 @@ const (
 		status in ('registered', 'popped_up')
 			AND NOT is_local
 			AND current_supply > 0
-			AND agent NOT IN (SELECT eth_addr FROM accounts)`
+			AND agent NOT IN (SELECT eth_addr FROM accounts)
+			AND (expires_at IS NULL OR expires_at > now())`
 
 	activeOfferingSorting = `
 		      ORDER BY block_number_updated DESC
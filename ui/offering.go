@@ -2,7 +2,6 @@ package ui
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -13,7 +12,6 @@ import (
 
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/job"
-	"github.com/privatix/dappctrl/messages"
 	"github.com/privatix/dappctrl/messages/offer"
 	"github.com/privatix/dappctrl/proc/worker"
 	"github.com/privatix/dappctrl/util"
@@ -101,6 +99,10 @@ func (h *Handler) AcceptOffering(tkn string, account data.HexString,
 		return nil, err
 	}
 
+	if err := h.checkBudgets(logger, acc.ID, deposit); err != nil {
+		return nil, err
+	}
+
 	rid := util.NewUUID()
 	jobData := &worker.ClientPreChannelCreateData{Account: acc.ID,
 		Offering: offering, GasPrice: gasPrice, Deposit: deposit}
@@ -392,17 +394,18 @@ func (h *Handler) setOfferingHash(logger log.Logger, offering *data.Offering,
 	}
 	msg := offer.OfferingMessage(agent, template, offering)
 
-	msgBytes, err := json.Marshal(msg)
+	agentKey, err := h.pwdStorage.GetKey(agent)
 	if err != nil {
 		return handleErr(err)
 	}
 
-	agentKey, err := h.pwdStorage.GetKey(agent)
+	envelope, err := data.ReadBoolSetting(h.db.Querier,
+		data.SettingMessageEnvelope)
 	if err != nil {
-		return handleErr(err)
+		logger.Warn(err.Error())
 	}
 
-	packed, err := messages.PackWithSignature(msgBytes, agentKey)
+	packed, err := offer.Pack(msg, agentKey, !envelope, time.Now())
 	if err != nil {
 		return handleErr(err)
 	}
@@ -457,6 +460,11 @@ func (h *Handler) prepareOffering(
 		logger.Error(ErrBillingType.Error())
 		return ErrBillingType
 	}
+
+	if offering.ExpiresAt != nil && !offering.ExpiresAt.After(time.Now()) {
+		logger.Error(ErrBadOfferingExpiry.Error())
+		return ErrBadOfferingExpiry
+	}
 	return h.fillOffering(logger, offering)
 }
 
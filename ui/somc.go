@@ -1 +1,19 @@
 package ui
+
+import (
+	"github.com/privatix/dappctrl/agent/somcsrv"
+)
+
+// GetSOMCRejectedRequests returns numbers of requests rejected by agent SOMC
+// server by reasons.
+func (h *Handler) GetSOMCRejectedRequests(
+	tkn string) (map[string]uint64, error) {
+	logger := h.logger.Add("method", "GetSOMCRejectedRequests")
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	return somcsrv.RejectedRequests(), nil
+}
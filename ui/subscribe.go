@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
 	"gopkg.in/reform.v1"
@@ -113,3 +115,117 @@ func (h *Handler) ObjectChange(ctx context.Context, tkn, objectType string,
 
 	return sub, nil
 }
+
+// LogEventResult is a LogEvents notification result.
+type LogEventResult struct {
+	Event    *data.LogEvent `json:"event"`
+	Backfill bool           `json:"backfill,omitempty"`
+	// Dropped is a number of log events dropped before this one, because
+	// the subscriber was too slow to receive them.
+	Dropped uint64 `json:"dropped,omitempty"`
+}
+
+// logEventsBuffer is a number of log events buffered for a subscriber.
+const logEventsBuffer = 1000
+
+// LogEvents subscribes to new log events matching a filter. Up to a given
+// number of the last matching log events are sent first.
+func (h *Handler) LogEvents(ctx context.Context, tkn string,
+	filter *LogEventsFilter, backfill uint) (*rpc.Subscription, error) {
+	logger := h.logger.Add("method", "LogEvents",
+		"filter", filter, "backfill", backfill)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	if backfill > maxLogBackfill {
+		logger.Warn(ErrBadLogBackfill.Error())
+		return nil, ErrBadLogBackfill
+	}
+
+	if filter == nil {
+		filter = &LogEventsFilter{}
+	}
+
+	if h.logFeed == nil {
+		logger.Error("no log feed")
+		return nil, ErrInternal
+	}
+
+	ntf, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		logger.Error("no notifier found in context")
+		return nil, ErrInternal
+	}
+
+	sub := ntf.CreateSubscription()
+	sid := string(sub.ID)
+
+	// Log events are sent from a separate goroutine, as feed callbacks
+	// are called by loggers and must neither block nor log.
+	events := make(chan *data.LogEvent, logEventsBuffer)
+	var dropped uint64
+	h.logFeed.Subscribe(sid, func(e *data.LogEvent) {
+		if !filter.match(e) {
+			return
+		}
+		select {
+		case events <- e:
+		default:
+			atomic.AddUint64(&dropped, 1)
+		}
+	})
+
+	backfilled, err := h.logEventsBackfill(logger, filter, backfill)
+	if err != nil {
+		h.logFeed.Unsubscribe(sid)
+		return nil, err
+	}
+
+	go func() {
+		defer h.logFeed.Unsubscribe(sid)
+
+		var last time.Time
+		for _, v := range backfilled {
+			err := ntf.Notify(sub.ID,
+				&LogEventResult{Event: v, Backfill: true})
+			if err != nil {
+				logger.Warn(fmt.Sprintf(
+					"could not notify subscriber: %v", err))
+				return
+			}
+			last = v.Time
+		}
+
+		for {
+			select {
+			case err := <-sub.Err():
+				if err != nil {
+					logger.Warn(fmt.Sprintf(
+						"subscription error: %v", err))
+				}
+				return
+			case e := <-events:
+				// Skip events which are already backfilled.
+				// Database keeps time with microsecond precision.
+				if !e.Time.Round(time.Microsecond).After(last) {
+					continue
+				}
+
+				err := ntf.Notify(sub.ID, &LogEventResult{
+					Event:   e,
+					Dropped: atomic.SwapUint64(&dropped, 0),
+				})
+				if err != nil {
+					logger.Warn(fmt.Sprintf(
+						"could not notify subscriber: %v", err))
+					return
+				}
+			}
+		}
+	}()
+
+	return sub, nil
+}
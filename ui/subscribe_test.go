@@ -9,9 +9,11 @@ import (
 	"gopkg.in/reform.v1"
 
 	"github.com/privatix/dappctrl/data"
+	dblog "github.com/privatix/dappctrl/data/log"
 	"github.com/privatix/dappctrl/job"
 	"github.com/privatix/dappctrl/ui"
 	"github.com/privatix/dappctrl/util"
+	"github.com/privatix/dappctrl/util/log"
 )
 
 func TestObjectChange(t *testing.T) {
@@ -82,3 +84,45 @@ func TestObjectChange(t *testing.T) {
 		t.Fatal("didn't unsubscribe")
 	}
 }
+
+func TestLogEvents(t *testing.T) {
+	defer data.CleanTestTable(t, db, data.LogEventView)
+
+	fxt, assertErrEqual := newTest(t, "LogEvents")
+	defer fxt.close()
+
+	dlog, err := dblog.NewLogger(dblog.NewConfig(), db, testLogFeed)
+	util.TestExpectResult(t, "NewLogger", nil, err)
+
+	dlog.Add("channel", fxt.Channel.ID).Error("backfilled")
+	dlog.Add("channel", util.NewUUID()).Error("other channel")
+
+	filter := &ui.LogEventsFilter{
+		Levels:  []string{string(log.Error)},
+		Channel: fxt.Channel.ID,
+	}
+
+	ch := make(chan *ui.LogEventResult)
+	_, err = subscribe(client, ch, "logEvents", "bad-password", filter, 5)
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	_, err = subscribe(client, ch, "logEvents", testToken.v, filter, 1001)
+	assertErrEqual(ui.ErrBadLogBackfill, err)
+
+	sub, err := subscribe(client, ch, "logEvents", testToken.v, filter, 5)
+	assertErrEqual(nil, err)
+	defer sub.Unsubscribe()
+
+	ret := <-ch
+	if !ret.Backfill || ret.Event.Message != "backfilled" {
+		t.Fatalf("wrong backfilled log event: %+v", ret)
+	}
+
+	dlog.Add("channel", util.NewUUID()).Error("other channel")
+	dlog.Add("channel", fxt.Channel).Error("live")
+
+	ret = <-ch
+	if ret.Backfill || ret.Event.Message != "live" {
+		t.Fatalf("wrong live log event: %+v", ret)
+	}
+}
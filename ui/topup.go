@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/util/log"
+)
+
+// GetTopUpPolicy returns a deposit top up policy of a client channel.
+// Null is returned, if the channel has no policy.
+func (h *Handler) GetTopUpPolicy(
+	tkn, channel string) (*data.TopUpPolicy, error) {
+	logger := h.logger.Add("method", "GetTopUpPolicy", "channel", channel)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	if _, err := h.findClientChannel(logger, channel); err != nil {
+		return nil, err
+	}
+
+	policy, err := data.FindTopUpPolicy(h.db.Querier, channel)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	return policy, nil
+}
+
+// SetTopUpPolicy sets a deposit top up policy of a client channel.
+func (h *Handler) SetTopUpPolicy(tkn string, policy *data.TopUpPolicy) error {
+	logger := h.logger.Add("method", "SetTopUpPolicy", "policy", policy)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return ErrAccessDenied
+	}
+
+	if policy == nil || (policy.Mode != data.TopUpAuto &&
+		policy.Mode != data.TopUpAsk) ||
+		(policy.Amount != 0 && policy.MinUnitsMultiple != 0) {
+		logger.Warn(ErrBadTopUpPolicy.Error())
+		return ErrBadTopUpPolicy
+	}
+
+	if _, err := h.findClientChannel(logger, policy.Channel); err != nil {
+		return err
+	}
+
+	if err := h.db.Save(policy); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// DeleteTopUpPolicy deletes a deposit top up policy of a client channel.
+// After that the channel is topped up according to the settings.
+func (h *Handler) DeleteTopUpPolicy(tkn, channel string) error {
+	logger := h.logger.Add("method", "DeleteTopUpPolicy", "channel", channel)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return ErrAccessDenied
+	}
+
+	if _, err := h.findClientChannel(logger, channel); err != nil {
+		return err
+	}
+
+	if _, err := h.db.DeleteFrom(data.TopUpPolicyTable,
+		"WHERE channel = $1", channel); err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (h *Handler) findClientChannel(
+	logger log.Logger, channel string) (*data.Channel, error) {
+	if h.userRole == data.RoleAgent {
+		logger.Warn(ErrNotAllowedForAgent.Error())
+		return nil, ErrNotAllowedForAgent
+	}
+
+	var ch data.Channel
+	if err := h.findByPrimaryKey(
+		logger, ErrChannelNotFound, &ch, channel); err != nil {
+		return nil, err
+	}
+
+	return &ch, nil
+}
+
+func (h *Handler) checkTopUpPolicy(
+	logger log.Logger, ch *data.Channel, deposit uint64) error {
+	policy, err := data.FindTopUpPolicy(h.db.Querier, ch.ID)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	limited, err := data.LimitTopUp(h.db.Querier, policy, ch, deposit)
+	if err != nil {
+		logger.Error(err.Error())
+		return ErrInternal
+	}
+
+	if limited < deposit {
+		logger.Warn(ErrTopUpCapExceeded.Error())
+		return ErrTopUpCapExceeded
+	}
+
+	return nil
+}
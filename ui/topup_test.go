@@ -0,0 +1,98 @@
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/privatix/dappctrl/data"
+	"github.com/privatix/dappctrl/ui"
+	"github.com/privatix/dappctrl/util"
+)
+
+func TestSetTopUpPolicy(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "SetTopUpPolicy")
+	defer fxt.close()
+
+	policy := &data.TopUpPolicy{
+		Channel:  fxt.Channel.ID,
+		Mode:     data.TopUpAuto,
+		Amount:   100,
+		DailyCap: 1000,
+	}
+
+	err := handler.SetTopUpPolicy("wrong-token", policy)
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	bad := *policy
+	bad.Mode = "unknown"
+	err = handler.SetTopUpPolicy(testToken.v, &bad)
+	assertErrEqual(ui.ErrBadTopUpPolicy, err)
+
+	bad = *policy
+	bad.Channel = util.NewUUID()
+	err = handler.SetTopUpPolicy(testToken.v, &bad)
+	assertErrEqual(ui.ErrChannelNotFound, err)
+
+	handler.SetMockRole(data.RoleAgent)
+	err = handler.SetTopUpPolicy(testToken.v, policy)
+	assertErrEqual(ui.ErrNotAllowedForAgent, err)
+	handler.SetMockRole(data.RoleClient)
+
+	err = handler.SetTopUpPolicy(testToken.v, policy)
+	assertErrEqual(nil, err)
+	defer data.DeleteFromTestDB(t, db, policy)
+
+	ret, err := handler.GetTopUpPolicy(testToken.v, fxt.Channel.ID)
+	assertErrEqual(nil, err)
+	if ret == nil || *ret != *policy {
+		t.Fatal("wrong top up policy returned")
+	}
+}
+
+func TestDeleteTopUpPolicy(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "DeleteTopUpPolicy")
+	defer fxt.close()
+
+	policy := &data.TopUpPolicy{
+		Channel: fxt.Channel.ID,
+		Mode:    data.TopUpAsk,
+	}
+	data.InsertToTestDB(t, db, policy)
+
+	err := handler.DeleteTopUpPolicy("wrong-token", fxt.Channel.ID)
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	handler.SetMockRole(data.RoleAgent)
+	err = handler.DeleteTopUpPolicy(testToken.v, fxt.Channel.ID)
+	assertErrEqual(ui.ErrNotAllowedForAgent, err)
+	handler.SetMockRole(data.RoleClient)
+
+	err = handler.DeleteTopUpPolicy(testToken.v, fxt.Channel.ID)
+	assertErrEqual(nil, err)
+
+	ret, err := handler.GetTopUpPolicy(testToken.v, fxt.Channel.ID)
+	assertErrEqual(nil, err)
+	if ret != nil {
+		t.Fatal("top up policy not deleted")
+	}
+}
+
+func TestTopUpChannelPolicyCap(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "TopUpChannel")
+	defer fxt.close()
+
+	setTestJobQueueToExpectJobAdd(t, new(data.Job))
+
+	policy := &data.TopUpPolicy{
+		Channel:    fxt.Channel.ID,
+		Mode:       data.TopUpAuto,
+		MaxDeposit: fxt.Channel.TotalDeposit + 10,
+	}
+	data.InsertToTestDB(t, db, policy)
+	defer data.DeleteFromTestDB(t, db, policy)
+
+	err := handler.TopUpChannel(testToken.v, fxt.Channel.ID, 100, 123)
+	assertErrEqual(ui.ErrTopUpCapExceeded, err)
+
+	err = handler.TopUpChannel(testToken.v, fxt.Channel.ID, 10, 123)
+	assertErrEqual(nil, err)
+}
@@ -1,5 +1,9 @@
 package ui
 
+import (
+	"github.com/privatix/dappctrl/data"
+)
+
 // GetOfferingUsage returns total units used for all channels
 // with a given offering.
 func (h *Handler) GetOfferingUsage(password, offeringID string) (*uint, error) {
@@ -30,3 +34,48 @@ func (h *Handler) GetProductUsage(password, productID string) (*uint, error) {
 		   	JOIN sessions
 		     	ON sessions.channel=channels.id`, productID)
 }
+
+// GetUsageSeries returns units used within minute, hour or day buckets
+// grouped by channels, offerings or clients. A non empty id limits series
+// to a given channel, offering or client.
+func (h *Handler) GetUsageSeries(tkn, bucket, groupBy, id,
+	dateFrom, dateTo string) ([]data.UsageSeriesItem, error) {
+	logger := h.logger.Add("method", "GetUsageSeries", "bucket", bucket,
+		"groupBy", groupBy, "id", id, "dateFrom", dateFrom,
+		"dateTo", dateTo)
+
+	if !h.token.Check(tkn) {
+		logger.Warn("access denied")
+		return nil, ErrAccessDenied
+	}
+
+	switch bucket {
+	case data.UsageMinute, data.UsageHour, data.UsageDay:
+	default:
+		logger.Warn(ErrBadUsageSeries.Error())
+		return nil, ErrBadUsageSeries
+	}
+
+	switch groupBy {
+	case data.UsageByChannel, data.UsageByOffering, data.UsageByClient:
+	default:
+		logger.Warn(ErrBadUsageSeries.Error())
+		return nil, ErrBadUsageSeries
+	}
+
+	for _, v := range []string{dateFrom, dateTo} {
+		if v != "" && !validReportDate(v) {
+			logger.Warn(ErrBadUsageSeries.Error())
+			return nil, ErrBadUsageSeries
+		}
+	}
+
+	items, err := data.UsageSeries(h.db.Querier,
+		bucket, groupBy, id, dateFrom, dateTo)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	return items, nil
+}
@@ -2,6 +2,7 @@ package ui_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/privatix/dappctrl/data"
 	"github.com/privatix/dappctrl/ui"
@@ -46,3 +47,54 @@ func TestUsage(t *testing.T) {
 	ret, err = handler.GetProductUsage(testToken.v, fxt.Product.ID)
 	assertUsage(uint(sess1.UnitsUsed+sess2.UnitsUsed), ret, err)
 }
+
+func TestGetUsageSeries(t *testing.T) {
+	fxt, assertErrEqual := newTest(t, "GetUsageSeries")
+	defer fxt.close()
+
+	sess := data.NewTestSession(fxt.Channel.ID)
+	data.InsertToTestDB(t, fxt.DB, sess)
+	defer data.DeleteFromTestDB(t, fxt.DB, sess)
+
+	tm := time.Date(2018, 1, 15, 10, 0, 0, 0, time.UTC)
+	for _, v := range []time.Duration{
+		time.Minute, 2 * time.Minute, time.Hour} {
+		data.InsertToTestDB(t, fxt.DB, &data.UsageSample{
+			Session: sess.ID,
+			Channel: fxt.Channel.ID,
+			Time:    tm.Add(v),
+			Units:   10,
+		})
+	}
+
+	_, err := handler.GetUsageSeries("wrong-token", data.UsageHour,
+		data.UsageByChannel, "", "", "")
+	assertErrEqual(ui.ErrAccessDenied, err)
+
+	_, err = handler.GetUsageSeries(testToken.v, "week",
+		data.UsageByChannel, "", "", "")
+	assertErrEqual(ui.ErrBadUsageSeries, err)
+
+	_, err = handler.GetUsageSeries(testToken.v, data.UsageHour,
+		"product", "", "", "")
+	assertErrEqual(ui.ErrBadUsageSeries, err)
+
+	_, err = handler.GetUsageSeries(testToken.v, data.UsageHour,
+		data.UsageByChannel, "", "yesterday", "")
+	assertErrEqual(ui.ErrBadUsageSeries, err)
+
+	items, err := handler.GetUsageSeries(testToken.v, data.UsageHour,
+		data.UsageByOffering, fxt.Offering.ID, "", "")
+	assertErrEqual(nil, err)
+	if len(items) != 2 || items[0].Units != 20 || items[1].Units != 10 ||
+		items[0].Group != fxt.Offering.ID {
+		t.Fatalf("wrong usage series: %+v", items)
+	}
+
+	items, err = handler.GetUsageSeries(testToken.v, data.UsageDay,
+		data.UsageByChannel, "", "2018-01-15", "2018-01-16")
+	assertErrEqual(nil, err)
+	if len(items) != 1 || items[0].Units != 30 {
+		t.Fatalf("wrong usage series: %+v", items)
+	}
+}
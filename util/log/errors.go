@@ -9,11 +9,13 @@ const (
 	// CRC16("github.com/privatix/dappctrl/util/log") = 0x6928
 	ErrBadLevel errors.Error = 0x6928<<8 + iota
 	ErrBadStackLevel
+	ErrBadFormat
 )
 
 var errMsgs = errors.Messages{
 	ErrBadLevel:      "bad log level",
 	ErrBadStackLevel: "bad log level for stack trace",
+	ErrBadFormat:     "bad log format",
 }
 
 func init() { errors.InjectMessages(errMsgs) }
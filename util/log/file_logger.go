@@ -1,13 +1,19 @@
 package log
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
 	"time"
+)
 
-	"github.com/leekchan/timeutil"
+// Log output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 // WriterConfig is an io.Writer based logger configuration.
@@ -15,6 +21,7 @@ type WriterConfig struct {
 	*BaseConfig
 	Prefix string
 	UTC    bool
+	Format string
 }
 
 // NewWriterConfig creates a new io.Writer based logger configuration.
@@ -23,29 +30,43 @@ func NewWriterConfig() *WriterConfig {
 		BaseConfig: NewBaseConfig(),
 		Prefix:     "",
 		UTC:        false,
+		Format:     FormatText,
 	}
 }
 
 type writerLogger struct {
 	*LoggerBase
+	conf   *WriterConfig
 	logger *log.Logger
 }
 
 // NewWriterLogger creates a new io.Writer based logger.
 func NewWriterLogger(conf *WriterConfig, out io.Writer) (Logger, error) {
-	l := &writerLogger{}
+	l := &writerLogger{conf: conf}
+
+	logf := l.log
+	switch conf.Format {
+	case FormatText, "":
+	case FormatJSON:
+		logf = l.logJSON
+	default:
+		return nil, ErrBadFormat
+	}
 
-	base, err := NewLoggerBase(conf.BaseConfig, l.log)
+	base, err := NewLoggerBase(conf.BaseConfig, logf)
 	if err != nil {
 		return nil, err
 	}
 
-	flags := log.LstdFlags
-	if conf.UTC {
-		flags |= log.LUTC
+	if conf.Format == FormatJSON {
+		l.logger = log.New(out, "", 0)
+	} else {
+		flags := log.LstdFlags
+		if conf.UTC {
+			flags |= log.LUTC
+		}
+		l.logger = log.New(out, conf.Prefix, flags)
 	}
-
-	l.logger = log.New(out, conf.Prefix, flags)
 	l.LoggerBase = base
 
 	return l, nil
@@ -56,11 +77,19 @@ func NewStderrLogger(conf *WriterConfig) (Logger, error) {
 	return NewWriterLogger(conf, os.Stderr)
 }
 
-// FileConfig is a file based logger configuration.
+// FileConfig is a file based logger configuration. A log file is rotated
+// when its size exceeds MaxSize megabytes or its date-templated name changes.
+// Rotated files are compressed if Compress is set, and deleted when there
+// are more than MaxBackups of them or they are older than MaxAge days. Zero
+// values disable corresponding limits.
 type FileConfig struct {
 	*WriterConfig
-	Filename string
-	FileMode os.FileMode
+	Filename   string
+	FileMode   os.FileMode
+	MaxSize    uint64
+	MaxBackups uint
+	MaxAge     uint
+	Compress   bool
 }
 
 // NewFileConfig creates a new file logger configuration.
@@ -74,14 +103,7 @@ func NewFileConfig() *FileConfig {
 
 // NewFileLogger creates a new file logger.
 func NewFileLogger(conf *FileConfig) (Logger, io.Closer, error) {
-	now := time.Now()
-	if conf.UTC {
-		now = now.UTC()
-	}
-
-	file, err := os.OpenFile(
-		timeutil.Strftime(&now, conf.Filename),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY, conf.FileMode)
+	file, err := newRotatingFile(conf, time.Now)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -107,3 +129,48 @@ func (l *writerLogger) log(lvl Level, msg string,
 
 	return nil
 }
+
+// jsonEvent is a log event written in JSON format.
+type jsonEvent struct {
+	Time    string                 `json:"time"`
+	Level   Level                  `json:"level"`
+	Message string                 `json:"message"`
+	Context map[string]interface{} `json:"context"`
+	Stack   *string                `json:"stack,omitempty"`
+}
+
+func (l *writerLogger) logJSON(lvl Level, msg string,
+	ctx map[string]interface{}, stack *string) error {
+	now := time.Now()
+	if l.conf.UTC {
+		now = now.UTC()
+	}
+
+	e := &jsonEvent{
+		Time:    now.Format(time.RFC3339Nano),
+		Level:   lvl,
+		Message: msg,
+		Context: ctx,
+		Stack:   stack,
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		// Context values which can't be marshalled are written as text.
+		e.Context = make(map[string]interface{})
+		for k, v := range ctx {
+			if _, err := json.Marshal(v); err != nil {
+				v = fmt.Sprint(v)
+			}
+			e.Context[k] = v
+		}
+
+		if line, err = json.Marshal(e); err != nil {
+			return err
+		}
+	}
+
+	l.logger.Print(string(line))
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFormat(t *testing.T) {
+	conf := NewWriterConfig()
+	conf.Format = FormatJSON
+
+	var out bytes.Buffer
+	logger, err := NewWriterLogger(conf, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Add("channel", "123", "bad", func() {}).Error("message")
+
+	var e struct {
+		Level   Level
+		Message string
+		Context map[string]interface{}
+		Stack   *string
+	}
+	if err := json.Unmarshal(out.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Level != Error || e.Message != "message" ||
+		e.Context["channel"] != "123" || e.Context["bad"] == nil ||
+		e.Stack == nil {
+		t.Fatalf("unexpected log event: %s", out.String())
+	}
+
+	conf.Format = "xml"
+	if _, err := NewWriterLogger(conf, &out); err != ErrBadFormat {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dappctrl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := NewFileConfig()
+	conf.Filename = filepath.Join(dir, "test-%Y-%m-%d.log")
+	conf.MaxSize = 1
+	conf.MaxBackups = 2
+	conf.Compress = true
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.Local)
+	f, err := newRotatingFile(conf, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte(strings.Repeat("x", megabyte/2) + "\n")
+	for i := 0; i < 6; i++ {
+		now = now.Add(time.Second)
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.AddDate(0, 0, 1)
+	if _, err := f.Write(line); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotated files have close modification times, so only a number of
+	// them is checked.
+	current := filepath.Join(dir, "test-2019-01-02.log")
+	if len(names) != 3 || names[2] != current ||
+		!strings.HasSuffix(names[0], compressedExt) ||
+		!strings.HasSuffix(names[1], compressedExt) {
+		t.Fatalf("unexpected log files: %v", names)
+	}
+}
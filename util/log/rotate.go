@@ -0,0 +1,241 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leekchan/timeutil"
+)
+
+const (
+	megabyte = 1024 * 1024
+	day      = 24 * time.Hour
+
+	compressedExt = ".gz"
+	backupTime    = "20060102T150405.000"
+)
+
+var strftimeVerb = regexp.MustCompile("%.")
+
+// rotatingFile is a log file which is rotated when its size exceeds a limit
+// or its date-templated name changes. Rotated files are optionally
+// compressed, and deleted when there are too many of them or they get too
+// old.
+type rotatingFile struct {
+	mtx     sync.Mutex
+	wg      sync.WaitGroup
+	bgMtx   sync.Mutex // Serializes processing of rotated files.
+	conf    *FileConfig
+	name    string
+	file    *os.File
+	size    int64
+	nowFunc func() time.Time
+}
+
+func newRotatingFile(conf *FileConfig,
+	nowFunc func() time.Time) (*rotatingFile, error) {
+	f := &rotatingFile{conf: conf, nowFunc: nowFunc}
+	if err := f.open(f.currentName()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) now() time.Time {
+	now := f.nowFunc()
+	if f.conf.UTC {
+		now = now.UTC()
+	}
+	return now
+}
+
+func (f *rotatingFile) currentName() string {
+	now := f.now()
+	return timeutil.Strftime(&now, f.conf.Filename)
+}
+
+func (f *rotatingFile) open(name string) error {
+	file, err := os.OpenFile(name,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, f.conf.FileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.name = name
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+// backupName returns a name for a file rotated by size.
+func (f *rotatingFile) backupName() string {
+	ext := filepath.Ext(f.name)
+	return strings.TrimSuffix(f.name, ext) + "-" +
+		f.now().Format(backupTime) + ext
+}
+
+func (f *rotatingFile) rotate(name string) error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	old := f.name
+	if name == f.name {
+		old = f.backupName()
+		if err := os.Rename(f.name, old); err != nil {
+			return err
+		}
+	}
+
+	if err := f.open(name); err != nil {
+		return err
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		f.bgMtx.Lock()
+		defer f.bgMtx.Unlock()
+
+		if f.conf.Compress {
+			compressFile(old)
+		}
+		f.removeBackups()
+	}()
+
+	return nil
+}
+
+// Write writes data to the log file rotating it if needed.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	name := f.currentName()
+	if name != f.name || (f.conf.MaxSize != 0 && f.size != 0 &&
+		uint64(f.size)+uint64(len(p)) > f.conf.MaxSize*megabyte) {
+		if err := f.rotate(name); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the log file and waits for rotated files to be processed.
+func (f *rotatingFile) Close() error {
+	f.mtx.Lock()
+	err := f.file.Close()
+	f.mtx.Unlock()
+
+	f.wg.Wait()
+
+	return err
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+compressedExt,
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	// Keep modification time for max age checks.
+	os.Chtimes(name+compressedExt, info.ModTime(), info.ModTime())
+
+	return os.Remove(name)
+}
+
+// removeBackups deletes rotated files exceeding max backups and max age.
+func (f *rotatingFile) removeBackups() {
+	if f.conf.MaxBackups == 0 && f.conf.MaxAge == 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.conf.Filename)
+	pattern := strftimeVerb.ReplaceAllString(
+		strings.TrimSuffix(f.conf.Filename, ext), "*") + "*" + ext + "*"
+
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return
+	}
+
+	f.mtx.Lock()
+	current := f.name
+	f.mtx.Unlock()
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+
+	var backups []backup
+	for _, v := range names {
+		if v == current {
+			continue
+		}
+
+		info, err := os.Stat(v)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		backups = append(backups, backup{v, info.ModTime()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	minTime := f.now().Add(-time.Duration(f.conf.MaxAge) * day)
+	for i, v := range backups {
+		if (f.conf.MaxBackups != 0 && uint(i) >= f.conf.MaxBackups) ||
+			(f.conf.MaxAge != 0 && v.modTime.Before(minTime)) {
+			os.Remove(v.name)
+		}
+	}
+}
@@ -66,6 +66,11 @@ func (s *Server) AddHandler(namespace string, handler interface{}) error {
 	return s.rpcsrv.RegisterName(namespace, handler)
 }
 
+// Wrap wraps the server HTTP handler with a middleware.
+func (s *Server) Wrap(middleware func(http.Handler) http.Handler) {
+	s.httpsrv.Handler = middleware(s.httpsrv.Handler)
+}
+
 // ListenAndServe starts to listen and to serve requests.
 func (s *Server) ListenAndServe() error {
 	if s.conf.TLS != nil {